@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+	"taskgraph/internal/textdiff"
+)
+
+const adoptUsage = "usage: tg adopt [paths...] [--label name] [--dry-run]"
+
+// runAdopt gives open checklist items without an ID a new [prefix-xxxx] ID
+// so they can be referenced and closed. Without paths it covers every
+// indexed markdown file; --label keeps only items carrying all the labels.
+func runAdopt(args []string, stdout io.Writer, stderr io.Writer) error {
+	var paths, labels []string
+	dryRun := false
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--dry-run":
+			dryRun = true
+		case args[i] == "--label":
+			if i+1 >= len(args) || len(tasks.NormalizeLabelsCSV(args[i+1])) == 0 {
+				fmt.Fprintln(stderr, adoptUsage)
+				return errors.New(adoptUsage)
+			}
+			labels = append(labels, tasks.NormalizeLabelsCSV(args[i+1])...)
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			fmt.Fprintln(stderr, adoptUsage)
+			return errors.New(adoptUsage)
+		default:
+			paths = append(paths, args[i])
+		}
+	}
+	labels = tasks.MergeLabels(labels)
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+	prefix, err := project.ReadPrefix(root)
+	if err != nil {
+		return err
+	}
+
+	files, err := indexedMarkdownFiles(root)
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		if files, err = markdownFilesIn(cwd, paths); err != nil {
+			return err
+		}
+	}
+
+	// IDs must be unique across the whole repo, not just the files adopted.
+	existing := map[string]bool{}
+	sources, err := indexer.SourceFiles(root)
+	if err != nil {
+		return err
+	}
+	for _, f := range append(sources, files...) {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		for id := range tasks.CollectTaskIDs(string(b)) {
+			existing[id] = true
+		}
+	}
+
+	keep := func(task tasks.Task) bool {
+		have := map[string]bool{}
+		for _, l := range task.Labels {
+			have[l] = true
+		}
+		for _, l := range labels {
+			if !have[l] {
+				return false
+			}
+		}
+		return true
+	}
+
+	now := time.Now()
+	total, changedFiles := 0, 0
+	for _, absPath := range files {
+		b, err := os.ReadFile(absPath)
+		if err != nil {
+			return err
+		}
+		before := string(b)
+		after, adopted := tasks.AdoptTasks(before, prefix, existing, now, keep)
+		if len(adopted) == 0 {
+			continue
+		}
+		total += len(adopted)
+		changedFiles++
+		rel := absPath
+		if r, err := filepath.Rel(root, absPath); err == nil && !strings.HasPrefix(r, "..") {
+			rel = filepath.ToSlash(r)
+		}
+		if dryRun {
+			fmt.Fprint(stdout, textdiff.Unified(rel, before, after))
+			continue
+		}
+		if err := os.WriteFile(absPath, []byte(after), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if total == 0 {
+		fmt.Fprintln(stdout, "No untracked open tasks found.")
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(stdout, "Would adopt %d task(s) in %d file(s).\n", total, changedFiles)
+		return nil
+	}
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Adopted %d task(s) in %d file(s).\n", total, changedFiles)
+	return nil
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAdoptAssignsIDsToOpenItems(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-abc] Tracked\n")
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	plan := filepath.Join(dir, "docs", "plan.md")
+	original := "# Plan\n\n- [ ] Ship beta #launch\n- [ ] Write docs\n- [x] Done already\n"
+	mustWrite(t, plan, original)
+	notes := filepath.Join(dir, "notes.md")
+	mustWrite(t, notes, "- [ ] Call Sam #launch\n")
+
+	stdout, _, err := run([]string{"adopt", "--dry-run", "--label", "launch"})
+	if err != nil {
+		t.Fatalf("adopt --dry-run returned err: %v", err)
+	}
+	if strings.Count(stdout, "\n+- [ ] [tg-") != 2 || !strings.Contains(stdout, "Would adopt 2 task(s) in 2 file(s).") {
+		t.Fatalf("unexpected dry-run output:\n%s", stdout)
+	}
+	if b, _ := os.ReadFile(plan); string(b) != original {
+		t.Fatalf("--dry-run must not write, got:\n%s", b)
+	}
+
+	stdout, stderr, err := run([]string{"adopt", "docs"})
+	if err != nil {
+		t.Fatalf("adopt returned err: %v stderr=%q", err, stderr)
+	}
+	if stdout != "Adopted 2 task(s) in 1 file(s).\n" {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+	b, err := os.ReadFile(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if !strings.HasPrefix(lines[2], "- [ ] [tg-") || !strings.HasSuffix(lines[2], "] Ship beta #launch") || !strings.HasPrefix(lines[3], "- [ ] [tg-") || lines[4] != "- [x] Done already" {
+		t.Fatalf("unexpected adopted file:\n%s", b)
+	}
+	if b, _ := os.ReadFile(notes); string(b) != "- [ ] Call Sam #launch\n" {
+		t.Fatalf("expected notes.md to be left alone, got %q", b)
+	}
+
+	stdout, _, err = run([]string{"list", "--where", "path:docs"})
+	if err != nil || strings.Count(stdout, "[tg-") != 2 {
+		t.Fatalf("expected the index to be refreshed, err=%v output:\n%s", err, stdout)
+	}
+}
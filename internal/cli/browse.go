@@ -0,0 +1,599 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+)
+
+const browseHelp = "j/k move  h/l collapse/expand  J/K sibling  u parent  x close/reopen  e edit  f label  a closed  q quit"
+
+type browseAction int
+
+const (
+	browseNone browseAction = iota
+	browseQuit
+	browseToggleState
+	browseEdit
+	browseReload
+)
+
+type browseRow struct {
+	id    string
+	level int
+}
+
+// browser holds the navigation state for tg browse. It is kept free of
+// terminal I/O so key handling can be exercised directly in tests.
+type browser struct {
+	nodes         []indexer.Node
+	byID          map[string]indexer.Node
+	roots         map[string]bool
+	children      map[string][]indexer.Node
+	expanded      map[string]bool
+	includeClosed bool
+	labels        []string
+	rows          []browseRow
+	cursor        int
+	offset        int
+	status        string
+	prompting     bool
+	input         string
+}
+
+func newBrowser(nodes []indexer.Node) *browser {
+	b := &browser{expanded: map[string]bool{}}
+	b.load(nodes)
+	return b
+}
+
+// load replaces the node set, keeping the cursor and expansion on the same
+// file locations. Node IDs change when a title changes (closing a task appends
+// a note), so locations are the stable key across reloads.
+func (b *browser) load(nodes []indexer.Node) {
+	current := b.currentLocation()
+	b.nodes = nodes
+	b.byID = make(map[string]indexer.Node, len(nodes))
+	for _, node := range nodes {
+		b.byID[node.ID] = node
+	}
+	b.roots = selectGraphRoots(nodes)
+	b.children = graphChildren(nodes)
+	b.refresh()
+	b.moveToLocation(current)
+}
+
+func browseLocation(node indexer.Node) string {
+	return fmt.Sprintf("%s:%d", node.Path, node.Line)
+}
+
+func (b *browser) currentLocation() string {
+	if node, ok := b.current(); ok {
+		return browseLocation(node)
+	}
+	return ""
+}
+
+func (b *browser) moveToLocation(loc string) {
+	for i, row := range b.rows {
+		if browseLocation(b.byID[row.id]) == loc {
+			b.cursor = i
+			return
+		}
+	}
+	b.clampCursor()
+}
+
+func (b *browser) current() (indexer.Node, bool) {
+	if b.cursor < 0 || b.cursor >= len(b.rows) {
+		return indexer.Node{}, false
+	}
+	node, ok := b.byID[b.rows[b.cursor].id]
+	return node, ok
+}
+
+// refresh recomputes which nodes are shown and flattens the expanded tree into
+// rows, using the same root selection and visibility rules as tg graph.
+func (b *browser) refresh() {
+	visible := graphVisibility(b.byID, b.children, b.roots, b.includeClosed)
+	if len(b.labels) > 0 {
+		visible = b.applyLabelFilter(visible)
+	}
+
+	b.rows = b.rows[:0]
+	var walk func(id string, level int)
+	walk = func(id string, level int) {
+		b.rows = append(b.rows, browseRow{id: id, level: level})
+		if !b.expanded[browseLocation(b.byID[id])] {
+			return
+		}
+		for _, child := range b.visibleChildren(id, visible) {
+			walk(child.ID, level+1)
+		}
+	}
+	for _, node := range b.nodes {
+		if b.roots[node.ID] && visible[node.ID] {
+			walk(node.ID, 0)
+		}
+	}
+	b.clampCursor()
+}
+
+func (b *browser) applyLabelFilter(visible map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(visible))
+	var keep func(id string) bool
+	keep = func(id string) bool {
+		if !visible[id] {
+			return false
+		}
+		result := hasAllLabels(b.byID[id].Labels, b.labels)
+		for _, child := range b.children[id] {
+			if b.roots[child.ID] {
+				continue
+			}
+			if keep(child.ID) {
+				result = true
+			}
+		}
+		out[id] = result
+		return result
+	}
+	for _, node := range b.nodes {
+		if b.roots[node.ID] {
+			keep(node.ID)
+		}
+	}
+	return out
+}
+
+func (b *browser) visibleChildren(id string, visible map[string]bool) []indexer.Node {
+	var out []indexer.Node
+	for _, child := range b.children[id] {
+		if b.roots[child.ID] || !visible[child.ID] {
+			continue
+		}
+		out = append(out, child)
+	}
+	return out
+}
+
+func (b *browser) hasChildren(id string) bool {
+	for _, child := range b.children[id] {
+		if !b.roots[child.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *browser) clampCursor() {
+	if b.cursor >= len(b.rows) {
+		b.cursor = len(b.rows) - 1
+	}
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+}
+
+func (b *browser) parentRow(i int) int {
+	level := b.rows[i].level
+	for j := i - 1; j >= 0; j-- {
+		if b.rows[j].level < level {
+			return j
+		}
+	}
+	return -1
+}
+
+func (b *browser) siblingRow(i, step int) int {
+	level := b.rows[i].level
+	for j := i + step; j >= 0 && j < len(b.rows); j += step {
+		if b.rows[j].level < level {
+			return -1
+		}
+		if b.rows[j].level == level {
+			return j
+		}
+	}
+	return -1
+}
+
+// handleKey applies one key press and reports any action that needs the
+// caller to touch files or the terminal.
+func (b *browser) handleKey(key string) browseAction {
+	if b.prompting {
+		return b.handlePromptKey(key)
+	}
+	b.status = ""
+	loc := b.currentLocation()
+	switch key {
+	case "q", "ctrl-c":
+		return browseQuit
+	case "a":
+		b.includeClosed = !b.includeClosed
+		b.refresh()
+		b.moveToLocation(loc)
+		return browseNone
+	case "f":
+		b.prompting = true
+		b.input = ""
+		return browseNone
+	case "F":
+		b.labels = nil
+		b.refresh()
+		b.moveToLocation(loc)
+		return browseNone
+	case "r":
+		return browseReload
+	}
+	if len(b.rows) == 0 {
+		return browseNone
+	}
+
+	row := b.rows[b.cursor]
+	switch key {
+	case "j", "down":
+		if b.cursor < len(b.rows)-1 {
+			b.cursor++
+		}
+	case "k", "up":
+		if b.cursor > 0 {
+			b.cursor--
+		}
+	case "g", "home":
+		b.cursor = 0
+	case "G", "end":
+		b.cursor = len(b.rows) - 1
+	case "l", "right":
+		if !b.hasChildren(row.id) {
+			break
+		}
+		if !b.expanded[loc] {
+			b.expanded[loc] = true
+			b.refresh()
+		} else if b.cursor+1 < len(b.rows) && b.rows[b.cursor+1].level > row.level {
+			b.cursor++
+		}
+	case "h", "left":
+		if b.expanded[loc] {
+			delete(b.expanded, loc)
+			b.refresh()
+		} else if parent := b.parentRow(b.cursor); parent >= 0 {
+			b.cursor = parent
+		}
+	case "enter", " ":
+		if b.hasChildren(row.id) {
+			if b.expanded[loc] {
+				delete(b.expanded, loc)
+			} else {
+				b.expanded[loc] = true
+			}
+			b.refresh()
+		}
+	case "u":
+		if parent := b.parentRow(b.cursor); parent >= 0 {
+			b.cursor = parent
+		}
+	case "J":
+		if next := b.siblingRow(b.cursor, 1); next >= 0 {
+			b.cursor = next
+		}
+	case "K":
+		if prev := b.siblingRow(b.cursor, -1); prev >= 0 {
+			b.cursor = prev
+		}
+	case "x":
+		if b.byID[row.id].Kind != "checklist" {
+			b.status = "only checklist tasks can be closed or reopened"
+			break
+		}
+		return browseToggleState
+	case "e":
+		return browseEdit
+	}
+	return browseNone
+}
+
+func (b *browser) handlePromptKey(key string) browseAction {
+	switch key {
+	case "esc", "ctrl-c":
+		b.prompting = false
+	case "enter":
+		b.prompting = false
+		loc := b.currentLocation()
+		for _, label := range tasks.NormalizeLabelsCSV(b.input) {
+			b.toggleLabel(label)
+		}
+		b.refresh()
+		b.moveToLocation(loc)
+	case "backspace":
+		if b.input != "" {
+			_, size := utf8.DecodeLastRuneInString(b.input)
+			b.input = b.input[:len(b.input)-size]
+		}
+	default:
+		if utf8.RuneCountInString(key) == 1 {
+			b.input += key
+		}
+	}
+	return browseNone
+}
+
+func (b *browser) toggleLabel(label string) {
+	for i, existing := range b.labels {
+		if existing == label {
+			b.labels = append(b.labels[:i], b.labels[i+1:]...)
+			return
+		}
+	}
+	b.labels = append(b.labels, label)
+}
+
+// render draws the visible window of rows, scrolling to keep the cursor in view.
+func (b *browser) render(out io.Writer, width, height int) {
+	bodyHeight := height - 2
+	if bodyHeight < 1 {
+		bodyHeight = 1
+	}
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+bodyHeight {
+		b.offset = b.cursor - bodyHeight + 1
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	header := "tg browse"
+	if b.includeClosed {
+		header += "  [showing closed]"
+	}
+	if len(b.labels) > 0 {
+		header += "  filter: " + formatLabelFilter(b.labels)
+	}
+	sb.WriteString("\x1b[1m" + truncateDisplay(header, width) + "\x1b[0m\r\n")
+
+	for i := b.offset; i < len(b.rows) && i < b.offset+bodyHeight; i++ {
+		line := truncateDisplay(b.formatRow(b.rows[i]), width)
+		if i == b.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		sb.WriteString(line + "\r\n")
+	}
+	if len(b.rows) == 0 {
+		sb.WriteString("(no matching nodes)\r\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\x1b[%d;1H", height))
+	footer := browseHelp
+	if b.prompting {
+		footer = "label filter (toggle, comma-separated): " + b.input
+	} else if b.status != "" {
+		footer = b.status
+	} else if node, ok := b.current(); ok {
+		footer = fmt.Sprintf("%s:%d  |  %s", node.Path, node.Line, browseHelp)
+	}
+	sb.WriteString("\x1b[2m" + truncateDisplay(footer, width) + "\x1b[0m")
+	io.WriteString(out, sb.String())
+}
+
+func (b *browser) formatRow(row browseRow) string {
+	node := b.byID[row.id]
+	marker := "  "
+	if b.hasChildren(row.id) {
+		marker = "▸ "
+		if b.expanded[browseLocation(node)] {
+			marker = "▾ "
+		}
+	}
+	box := ""
+	if node.Kind == "checklist" {
+		box = "[ ] "
+		if node.State == "closed" {
+			box = "[x] "
+		}
+	}
+	return strings.Repeat("  ", row.level) + marker + box + formatGraphNode(node)
+}
+
+func formatLabelFilter(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, "#"+label)
+	}
+	return strings.Join(parts, " ")
+}
+
+func truncateDisplay(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width == 1 {
+		return string(runes[:1])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// parseKeys splits raw terminal input into named keys. Escape sequences for
+// arrows arrive in one read, so a lone ESC byte is reported as "esc".
+func parseKeys(in []byte) []string {
+	var keys []string
+	for len(in) > 0 {
+		switch {
+		case len(in) >= 3 && in[0] == 0x1b && (in[1] == '[' || in[1] == 'O'):
+			switch in[2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			case 'C':
+				keys = append(keys, "right")
+			case 'D':
+				keys = append(keys, "left")
+			case 'H':
+				keys = append(keys, "home")
+			case 'F':
+				keys = append(keys, "end")
+			}
+			in = in[3:]
+		case in[0] == 0x1b:
+			keys = append(keys, "esc")
+			in = in[1:]
+		case in[0] == '\r' || in[0] == '\n':
+			keys = append(keys, "enter")
+			in = in[1:]
+		case in[0] == 0x7f || in[0] == 0x08:
+			keys = append(keys, "backspace")
+			in = in[1:]
+		case in[0] == 0x03:
+			keys = append(keys, "ctrl-c")
+			in = in[1:]
+		default:
+			r, size := utf8.DecodeRune(in)
+			if r != utf8.RuneError {
+				keys = append(keys, string(r))
+			}
+			in = in[size:]
+		}
+	}
+	return keys
+}
+
+// editorCommand builds the command that opens path at line, using the
+// conventions of common editors and falling back to the widely supported +N.
+func editorCommand(editor, path string, line int) []string {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+	switch filepath.Base(fields[0]) {
+	case "code", "codium", "code-insiders":
+		return append(fields, "--goto", fmt.Sprintf("%s:%d", path, line))
+	case "subl", "zed":
+		return append(fields, fmt.Sprintf("%s:%d", path, line))
+	}
+	if line > 0 {
+		return append(fields, fmt.Sprintf("+%d", line), path)
+	}
+	return append(fields, path)
+}
+
+func runBrowse(stdout io.Writer, stderr io.Writer) error {
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+	stdoutFd := int(os.Stdout.Fd())
+	if !term.IsTerminal(stdinFd) || !term.IsTerminal(stdoutFd) {
+		fmt.Fprintln(stderr, "tg browse needs an interactive terminal")
+		return errors.New("not a terminal")
+	}
+
+	load := func() ([]indexer.Node, error) {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return nil, err
+		}
+		return indexer.ReadGraphNodes(filepath.Join(root, ".taskgraph", "taskgraph.db"))
+	}
+	nodes, err := load()
+	if err != nil {
+		return err
+	}
+	b := newBrowser(nodes)
+
+	state, err := term.MakeRaw(stdinFd)
+	if err != nil {
+		return err
+	}
+	enter := func() { io.WriteString(stdout, "\x1b[?1049h\x1b[?25l") }
+	leave := func() { io.WriteString(stdout, "\x1b[?25h\x1b[?1049l") }
+	enter()
+	defer func() {
+		leave()
+		_ = term.Restore(stdinFd, state)
+	}()
+
+	buf := make([]byte, 64)
+	for {
+		width, height, err := term.GetSize(stdoutFd)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		b.render(stdout, width, height)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			action := b.handleKey(key)
+			switch action {
+			case browseQuit:
+				return nil
+			case browseToggleState:
+				node, _ := b.current()
+				path := filepath.Join(root, filepath.FromSlash(node.Path))
+				if node.State == "closed" {
+					err = tasks.ReopenTaskLine(path, node.Line)
+				} else {
+					err = tasks.CloseTaskLine(path, node.Line, "")
+				}
+				if err != nil {
+					b.status = err.Error()
+					continue
+				}
+			case browseEdit:
+				node, _ := b.current()
+				editor := os.Getenv("VISUAL")
+				if editor == "" {
+					editor = os.Getenv("EDITOR")
+				}
+				args := editorCommand(editor, filepath.Join(root, filepath.FromSlash(node.Path)), node.Line)
+				cmd := exec.Command(args[0], args[1:]...)
+				cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+				leave()
+				_ = term.Restore(stdinFd, state)
+				runErr := cmd.Run()
+				if state, err = term.MakeRaw(stdinFd); err != nil {
+					return err
+				}
+				enter()
+				if runErr != nil {
+					b.status = "editor: " + runErr.Error()
+					continue
+				}
+			case browseReload:
+			default:
+				continue
+			}
+			nodes, err := load()
+			if err != nil {
+				b.status = err.Error()
+				continue
+			}
+			b.load(nodes)
+		}
+	}
+}
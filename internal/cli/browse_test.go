@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"taskgraph/internal/indexer"
+)
+
+func browseFixture() []indexer.Node {
+	return []indexer.Node{
+		{ID: "f", Kind: "file", Title: "plan", Path: "plan.md"},
+		{ID: "h", Kind: "heading", Title: "Platform", Path: "plan.md", Line: 1, ParentID: "f"},
+		{ID: "a", Kind: "checklist", Title: "Build graph", State: "open", Path: "plan.md", Line: 2, ParentID: "h"},
+		{ID: "a1", Kind: "checklist", Title: "Root heuristic #perf", State: "open", Path: "plan.md", Line: 3, ParentID: "a", Labels: []string{"perf"}},
+		{ID: "a2", Kind: "checklist", Title: "Done child", State: "closed", Path: "plan.md", Line: 4, ParentID: "a"},
+		{ID: "b", Kind: "checklist", Title: "Write docs", State: "open", Path: "plan.md", Line: 5, ParentID: "h"},
+		{ID: "p", Kind: "checklist", Title: "Launch #t-project", State: "open", Path: "plan.md", Line: 6, ParentID: "h", Labels: []string{"t-project"}},
+		{ID: "p1", Kind: "checklist", Title: "Announce", State: "open", Path: "plan.md", Line: 7, ParentID: "p"},
+	}
+}
+
+func browseTitles(b *browser) []string {
+	var out []string
+	for _, row := range b.rows {
+		out = append(out, strings.Repeat("  ", row.level)+b.byID[row.id].Title)
+	}
+	return out
+}
+
+func TestBrowserExpandsCollapsesAndNavigates(t *testing.T) {
+	b := newBrowser(browseFixture())
+	if got, want := browseTitles(b), []string{"Platform", "Launch #t-project"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected roots: %q want %q", got, want)
+	}
+
+	b.handleKey("l")
+	b.handleKey("l")
+	b.handleKey("enter")
+	want := []string{"Platform", "  Build graph", "    Root heuristic #perf", "  Write docs", "Launch #t-project"}
+	if got := browseTitles(b); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected expanded rows: %q want %q", got, want)
+	}
+	if node, _ := b.current(); node.ID != "a" {
+		t.Fatalf("expected cursor on first child, got %q", node.ID)
+	}
+
+	b.handleKey("J")
+	if node, _ := b.current(); node.ID != "b" {
+		t.Fatalf("expected next sibling, got %q", node.ID)
+	}
+	b.handleKey("h")
+	if node, _ := b.current(); node.ID != "h" {
+		t.Fatalf("expected parent after h on leaf, got %q", node.ID)
+	}
+	b.handleKey("h")
+	if got := browseTitles(b); len(got) != 2 {
+		t.Fatalf("expected collapsed tree, got %q", got)
+	}
+}
+
+func TestBrowserTogglesClosedAndLabelFilter(t *testing.T) {
+	b := newBrowser(browseFixture())
+	b.handleKey("l")
+	b.handleKey("j")
+	b.handleKey("l")
+
+	b.handleKey("a")
+	if got := strings.Join(browseTitles(b), "|"); !strings.Contains(got, "Done child") {
+		t.Fatalf("expected closed child after toggling closed, got %q", got)
+	}
+
+	for _, key := range []string{"f", "p", "e", "r", "f", "enter"} {
+		b.handleKey(key)
+	}
+	want := []string{"Platform", "  Build graph", "    Root heuristic #perf"}
+	if got := browseTitles(b); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected filtered rows: %q want %q", got, want)
+	}
+	if node, _ := b.current(); node.ID != "a" {
+		t.Fatalf("expected cursor kept on filtered node, got %q", node.ID)
+	}
+
+	b.handleKey("F")
+	if got := browseTitles(b); len(got) != 6 {
+		t.Fatalf("expected filter cleared, got %q", got)
+	}
+}
+
+func TestBrowserCloseActionOnlyForChecklist(t *testing.T) {
+	b := newBrowser(browseFixture())
+	if action := b.handleKey("x"); action != browseNone || b.status == "" {
+		t.Fatalf("expected heading close to be refused, got action=%v status=%q", action, b.status)
+	}
+	b.handleKey("j")
+	if action := b.handleKey("x"); action != browseToggleState {
+		t.Fatalf("expected toggle action for checklist, got %v", action)
+	}
+}
+
+func TestBrowserKeepsCursorLocationAcrossReload(t *testing.T) {
+	b := newBrowser(browseFixture())
+	b.handleKey("a")
+	b.handleKey("l")
+	b.handleKey("j")
+	b.handleKey("j")
+
+	nodes := browseFixture()
+	nodes[5].ID = "b-closed"
+	nodes[5].State = "closed"
+	nodes[5].Title = "Write docs **✅2026-03-03**"
+	b.load(nodes)
+
+	if node, _ := b.current(); node.ID != "b-closed" {
+		t.Fatalf("expected cursor to follow reloaded node, got %q", node.ID)
+	}
+}
+
+func TestBrowserRenderMarksCursorAndState(t *testing.T) {
+	b := newBrowser(browseFixture())
+	b.handleKey("G")
+	var out bytes.Buffer
+	b.render(&out, 80, 10)
+
+	got := out.String()
+	if !strings.Contains(got, "▸ Platform") {
+		t.Fatalf("expected collapsed marker, got %q", got)
+	}
+	if !strings.Contains(got, "\x1b[7m▸ [ ] [project] Launch\x1b[0m") {
+		t.Fatalf("expected highlighted typed root, got %q", got)
+	}
+	if !strings.Contains(got, "plan.md:6") {
+		t.Fatalf("expected location in footer, got %q", got)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("j\x1b[A\x1b[D\r\x7f\x1bé"))
+	want := []string{"j", "up", "left", "enter", "backspace", "esc", "é"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	cases := []struct {
+		editor string
+		want   []string
+	}{
+		{"", []string{"vi", "+12", "/r/a.md"}},
+		{"nvim", []string{"nvim", "+12", "/r/a.md"}},
+		{"code -w", []string{"code", "-w", "--goto", "/r/a.md:12"}},
+		{"/usr/bin/subl", []string{"/usr/bin/subl", "/r/a.md:12"}},
+	}
+	for _, tc := range cases {
+		if got := editorCommand(tc.editor, "/r/a.md", 12); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("editorCommand(%q) = %q want %q", tc.editor, got, tc.want)
+		}
+	}
+}
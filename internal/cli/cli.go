@@ -10,16 +10,26 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"taskgraph/internal/indexer"
 	"taskgraph/internal/migrate"
 	"taskgraph/internal/project"
 	"taskgraph/internal/tasks"
+	"taskgraph/internal/textdiff"
 )
 
-const addUsage = "usage: tg add <task text> [--labels a,b] [--type name]"
+const migrateBeadsUsage = "usage: tg migrate-beads [--dry-run]"
 
-var graphLabelPattern = regexp.MustCompile(`(^|[\s(])#([A-Za-z0-9][A-Za-z0-9-]*)`)
+const importUsage = "usage: tg import todotxt <file> [--dry-run] | tg import taskwarrior|github-issues <file.json> [--dry-run]"
+
+const refsUsage = "usage: tg refs <id>"
+
+const syncUsage = "usage: tg sync beads [--dry-run] [--prefer tg|beads] [--label name]"
+
+const addUsage = "usage: tg add <task text> [--labels a,b] [--type name] [--to path[#heading]] [--parent id]"
+
+var graphLabelPattern = regexp.MustCompile(`(^|[\s(])#([A-Za-z0-9][A-Za-z0-9-]*(?:/[A-Za-z0-9][A-Za-z0-9-]*)*)`)
 
 // Run dispatches CLI commands.
 func Run(args []string, stdout io.Writer, stderr io.Writer) error {
@@ -42,14 +52,50 @@ func Run(args []string, stdout io.Writer, stderr io.Writer) error {
 		return runClose(args[1:], stdout, stderr)
 	case "list":
 		return runList(args, stdout, stderr)
+	case "query":
+		return runQuery(args[1:], stdout, stderr)
 	case "graph":
 		return runGraph(args[1:], stdout, stderr)
+	case "view":
+		return runView(args[1:], stdout, stderr)
+	case "views":
+		return runViews(stdout, stderr)
+	case "doctor":
+		return runDoctor(args[1:], stdout, stderr)
+	case "fmt":
+		return runFmt(args[1:], stdout, stderr)
+	case "adopt":
+		return runAdopt(args[1:], stdout, stderr)
+	case "move":
+		return runMove(args[1:], stdout, stderr)
+	case "edit":
+		return runEdit(args[1:], stdout, stderr)
+	case "rm":
+		return runRm(args[1:], stdout, stderr)
+	case "labels":
+		return runLabels(args[1:], stdout, stderr)
+	case "triage":
+		return runTriage(os.Stdin, stdout, stderr)
+	case "browse":
+		return runBrowse(stdout, stderr)
+	case "serve":
+		return runServe(args[1:], stdout, stderr)
+	case "mcp":
+		return runMCP(os.Stdin, stdout, stderr)
+	case "export":
+		return runExport(args[1:], stdout, stderr)
+	case "import":
+		return runImport(args[1:], stdout, stderr)
 	case "index":
 		return runIndex(stdout, stderr)
 	case "projects":
 		return runProjects(stdout, stderr)
+	case "refs":
+		return runRefs(args[1:], stdout, stderr)
+	case "sync":
+		return runSync(args[1:], stdout, stderr)
 	case "migrate-beads":
-		return runMigrateBeads(stdout, stderr)
+		return runMigrateBeads(args[1:], stdout, stderr)
 	default:
 		return fmt.Errorf("unknown command: %s. Run 'tg --help'", args[0])
 	}
@@ -72,19 +118,61 @@ USAGE
 
 COMMANDS
   init              Initialize .taskgraph in current directory
-  add <text>        Add a task to .taskgraph/issues.md (supports --labels, --type)
-  create <text>     Alias for add (supports --labels, --type)
+  add <text>        Add a task to .taskgraph/issues.md (supports --labels, --type,
+                    --to path[#heading], --parent id)
+  create <text>     Alias for add (supports --labels, --type, --to, --parent)
   inbox [--all] [--label name]
                     Print inbox checklist from .taskgraph/issues.md
   close <id> [reason]
                     Close an inbox task in .taskgraph/issues.md
-  list [--all] [--label name]
+  move <id> <path>[#heading] | move <id> --parent <id>
+                    Move a task and its subtasks to another file, section or task
+  triage            Walk open inbox items: move, label, set type, close or skip
+  edit <id> [--title text] [--add-label a,b] [--remove-label a,b] [--type name]
+                    Change a task in place
+  rm <id> [--children]
+                    Delete a task; its subtasks move up unless --children is given
+  labels            List labels with how many tasks use each and registered
+                    descriptions (labels: in .taskgraph/config.yml)
+  labels rename <old> <new> | labels merge <from> <into> [--dry-run]
+                    Rewrite a label in every indexed markdown file
+  list [--all] [--label name] [--where query] [--sort mtime|path|title]
                     Print indexed checklist tasks from SQLite
-  graph [--depth N] [--max-children N] [--all]
+  query <query>     Print indexed nodes matching a query (see QUERIES)
+  graph [--depth N] [--max-children N] [--all] [--format text|dot|mermaid]
+        [--where query]
                     Print a compact graph overview from root nodes
+  view <name> [flags]
+                    Run a view saved under views: in .taskgraph/config.yml
+  views             List saved views and the command each one runs
+  browse            Navigate the graph interactively in a full-screen view
+  serve [--addr host:port]
+                    Serve a local JSON API over the index, reindexing on change
+  mcp               Run a Model Context Protocol server on stdin/stdout
+  export html <dir> Write a static HTML site of the graph, files and labels
+  export todotxt [file]
+                    Write all checklist tasks as todo.txt lines (stdout by default)
+  import todotxt <file> [--dry-run]
+                    Add todo.txt tasks to the inbox, skipping ids already present
+  import taskwarrior <file.json> [--dry-run]
+                    Import a Taskwarrior export, updating tasks imported before
+  import github-issues <file.json> [--dry-run]
+                    Import a gh issue list --json dump, updating issues imported before
   index             Build SQLite index from markdown files
   projects          List project files with open task counts
-  migrate-beads     Import .beads/issues.jsonl into .taskgraph/issues.md
+  refs <id>         List code comments that mention a task (needs scan-code: true)
+  doctor [--fix]    Check task files and the index; --fix repairs mechanical problems
+  fmt [paths...] [--check] [--diff] [--ids]
+                    Rewrite checklist lines into the canonical layout (--ids adds
+                    missing IDs)
+  adopt [paths...] [--label name] [--dry-run]
+                    Give open checklist items without an ID a new one
+  migrate-beads [--dry-run]
+                    Import .beads/issues.jsonl into .taskgraph/issues.md, updating
+                    issues imported before
+  sync beads [--dry-run] [--prefer tg|beads] [--label name]
+                    Two-way sync with .beads/issues.jsonl (pushes new inbox
+                    tasks, or with --label, tasks carrying that label)
   help              Show this help
 
 EXAMPLES
@@ -92,22 +180,63 @@ EXAMPLES
   tg add "buy milk"
   tg add "buy milk" --labels errands,home
   tg add "plan launch" --type epic
+  tg add "draft outline" --parent tg-abc
+  tg add "ship beta" --to docs/roadmap.md#Q3
   tg create "book dentist"
   tg inbox
   tg inbox --label home
   tg close tg-abc "done on phone"
+  tg move tg-abc docs/roadmap.md#Q3
+  tg move tg-abc --parent tg-def
+  tg triage
+  tg edit tg-abc --title "ship beta" --add-label launch --type feature
+  tg rm tg-abc --children
+  tg labels
+  tg labels rename flowershwo flowershow
+  tg labels merge home household
   tg list
   tg list --label errands
+  tg list --where "type:bug path:docs/**"
+  tg query type:bug state:open -label:someday
+  tg query "type:epic -has:open-children"
   tg graph
   tg graph --depth 3 --max-children 4
+  tg graph --format mermaid
+  tg graph --where "under:tg-abc"
+  tg views
+  tg view open-bugs --all
+  tg browse
+  tg serve --addr 127.0.0.1:7420
+  tg mcp
+  tg export html public/roadmap
+  tg export todotxt todo.txt
+  tg import todotxt ~/todo.txt
+  task export > tw.json && tg import taskwarrior tw.json
+  tg import github-issues issues.json
   tg index
-  tg migrate-beads
+  tg refs tg-abc
+  tg doctor --fix
+  tg fmt --check
+  tg fmt docs --diff
+  tg adopt docs/roadmap.md --dry-run
+  tg adopt --label launch
+  tg migrate-beads --dry-run
+  tg sync beads
+
+QUERIES
+  Terms are AND-ed; join with OR, group with ( ), negate with NOT or -.
+  type:bug  label:x or #x  state:open|closed  kind:file|heading|checklist
+  path:docs/**  under:<id>  has:children|open-children|labels|refs
+  id:<id>  source:<name>  text:word or a bare word
+  Without kind:, only checklist tasks match.
 
 NOTES
   - tg add auto-initializes .taskgraph if missing
   - use --type with one allowed task type per task
+  - --to paths are relative to the .taskgraph root
   - inbox is stored in .taskgraph/issues.md
   - index DB is stored in .taskgraph/taskgraph.db
+  - set scan-code: true in .taskgraph/config.yml to index TODO/FIXME/HACK comments
 `
 }
 
@@ -128,12 +257,12 @@ func runInit(stdout io.Writer) error {
 }
 
 func runAdd(args []string, stdout io.Writer, stderr io.Writer) error {
-	taskText, labels, taskType, err := parseAddArgs(args[1:])
+	opts, err := parseAddArgs(args[1:])
 	if err != nil {
 		fmt.Fprintln(stderr, err.Error())
 		return err
 	}
-	if strings.TrimSpace(taskText) == "" {
+	if strings.TrimSpace(opts.text) == "" {
 		fmt.Fprintln(stderr, addUsage)
 		return errors.New("missing task text")
 	}
@@ -157,36 +286,118 @@ func runAdd(args []string, stdout io.Writer, stderr io.Writer) error {
 		}
 	}
 
-	taskFile := filepath.Join(root, ".taskgraph", "issues.md")
+	if _, err := addTask(root, opts); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+	fmt.Fprintf(stdout, "Added task: %s\n", opts.text)
+	return nil
+}
+
+// addTask validates the task type, writes the new checklist line where opts
+// asks for it, refreshes the index and returns the generated task ID. It backs
+// tg add and every other surface that creates tasks.
+func addTask(root string, opts addOptions) (string, error) {
 	prefix, err := project.ReadPrefix(root)
 	if err != nil {
-		return err
+		return "", err
 	}
-	resolvedType, cleanLabels, err := tasks.ResolveTaskType(taskText, labels, taskType)
+	resolvedType, cleanLabels, err := tasks.ResolveTaskType(opts.text, opts.labels, opts.taskType)
 	if err != nil {
-		fmt.Fprintln(stderr, err.Error())
-		return err
+		return "", err
 	}
 	if resolvedType != "" {
 		allowed, err := project.ReadAllowedIssueTypes(root)
 		if err != nil {
-			return err
+			return "", err
 		}
 		if !containsString(allowed, resolvedType) {
 			sort.Strings(allowed)
-			msg := fmt.Sprintf("unknown task type: %s (allowed: %s)", resolvedType, strings.Join(allowed, ", "))
-			fmt.Fprintln(stderr, msg)
-			return errors.New(msg)
+			return "", fmt.Errorf("unknown task type: %s (allowed: %s)", resolvedType, strings.Join(allowed, ", "))
 		}
 	}
-	if err := tasks.AppendTask(taskFile, prefix, taskText, cleanLabels, resolvedType); err != nil {
-		return err
+	if err := checkRegisteredLabels(root, tasks.MergeLabels(cleanLabels, tasks.ExtractLabels(opts.text))); err != nil {
+		return "", err
+	}
+
+	taskFile := filepath.Join(root, ".taskgraph", "issues.md")
+	if opts.toPath != "" || opts.parentID != "" {
+		taskFile, err = resolveAddTarget(root, opts.toPath, opts.parentID)
+		if err != nil {
+			return "", err
+		}
+	}
+	placement := tasks.Placement{Heading: opts.heading, ParentID: opts.parentID}
+	taken, err := repoTaskIDs(root)
+	if err != nil {
+		return "", err
+	}
+	id, err := tasks.InsertTaskAvoiding(taskFile, prefix, opts.text, cleanLabels, resolvedType, placement, taken)
+	if err != nil {
+		return "", err
 	}
 	if _, _, err := buildAndStoreIndex(root); err != nil {
-		return err
+		return "", err
 	}
-	fmt.Fprintf(stdout, "Added task: %s\n", taskText)
-	return nil
+	return id, nil
+}
+
+// resolveAddTarget picks the markdown file for tg add --to/--parent. An
+// explicit --to path is relative to the taskgraph root and must stay inside
+// it; otherwise the file holding the parent task is used.
+func resolveAddTarget(root, toPath, parentID string) (string, error) {
+	if toPath != "" {
+		target := filepath.FromSlash(toPath)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(root, target)
+		}
+		rel, err := filepath.Rel(root, filepath.Clean(target))
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("target %s is outside the project at %s", toPath, root)
+		}
+		return filepath.Join(root, rel), nil
+	}
+	relPath, err := findTaskPath(root, parentID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, filepath.FromSlash(relPath)), nil
+}
+
+// repoTaskIDs returns every task ID in the markdown files the index covers,
+// so new IDs stay unique across the repo and not just within one file.
+func repoTaskIDs(root string) (map[string]bool, error) {
+	files, err := indexer.SourceFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for id := range tasks.CollectTaskIDs(string(b)) {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
+
+// findTaskPath returns the root-relative path of the markdown file that
+// contains the checklist task with the given ID.
+func findTaskPath(root, id string) (string, error) {
+	nodes, err := indexer.BuildNodes(root)
+	if err != nil {
+		return "", err
+	}
+	needle := "[" + id + "]"
+	for _, node := range nodes {
+		if node.Kind == "checklist" && node.Source != indexer.CodeSource && strings.Contains(node.Title, needle) {
+			return node.Path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", tasks.ErrTaskNotFound, id)
 }
 
 func runInbox(args []string, stdout io.Writer, stderr io.Writer) error {
@@ -245,24 +456,37 @@ func runClose(args []string, stdout io.Writer, stderr io.Writer) error {
 		return errors.New("not initialized")
 	}
 
-	taskFile := filepath.Join(root, ".taskgraph", "issues.md")
-	if err := tasks.CloseTask(taskFile, id, reason); err != nil {
+	if err := closeTask(root, id, reason); err != nil {
 		fmt.Fprintln(stderr, err.Error())
 		return err
 	}
-	if _, _, err := buildAndStoreIndex(root); err != nil {
-		return err
-	}
 	fmt.Fprintf(stdout, "Closed task: %s\n", id)
 	return nil
 }
 
+// closeTask closes the inbox task with the given ID and refreshes the index.
+func closeTask(root, id, reason string) error {
+	taskFile := filepath.Join(root, ".taskgraph", "issues.md")
+	if err := tasks.CloseTask(taskFile, id, reason); err != nil {
+		return err
+	}
+	_, _, err := buildAndStoreIndex(root)
+	return err
+}
+
 func runList(args []string, stdout io.Writer, stderr io.Writer) error {
-	includeClosed, requiredLabels, err := parseListArgs(args[1:])
+	opts, err := parseListArgs(args[1:])
 	if err != nil {
 		fmt.Fprintln(stderr, err.Error())
 		return err
 	}
+	var query indexer.Query
+	if opts.where != "" {
+		if query, err = indexer.ParseQuery(opts.where); err != nil {
+			fmt.Fprintln(stderr, err.Error())
+			return err
+		}
+	}
 
 	cwd, err := effectiveCWD()
 	if err != nil {
@@ -278,10 +502,18 @@ func runList(args []string, stdout io.Writer, stderr io.Writer) error {
 	}
 
 	dbPath := filepath.Join(root, ".taskgraph", "taskgraph.db")
-	nodes, err := indexer.ReadChecklistNodes(dbPath, includeClosed, requiredLabels)
+	nodes, err := indexer.ReadChecklistNodes(dbPath, opts.includeClosed, opts.labels)
 	if err != nil {
 		return err
 	}
+	if opts.where != "" {
+		matches, err := indexer.ReadQueryNodes(dbPath, query)
+		if err != nil {
+			return err
+		}
+		nodes = keepNodes(nodes, nodeIDSet(matches))
+	}
+	sortListNodes(nodes, opts.sort)
 
 	for _, n := range nodes {
 		mark := " "
@@ -293,6 +525,79 @@ func runList(args []string, stdout io.Writer, stderr io.Writer) error {
 	return nil
 }
 
+// sortListNodes orders tg list output. mtime, the default, keeps the index
+// order of most recently changed files first.
+func sortListNodes(nodes []indexer.Node, by string) {
+	switch by {
+	case "path":
+		sort.SliceStable(nodes, func(i, j int) bool {
+			if nodes[i].Path != nodes[j].Path {
+				return nodes[i].Path < nodes[j].Path
+			}
+			return nodes[i].Line < nodes[j].Line
+		})
+	case "title":
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return listSortTitle(nodes[i].Title) < listSortTitle(nodes[j].Title)
+		})
+	}
+}
+
+// listSortTitle drops a leading [id] so titles sort by their text.
+func listSortTitle(title string) string {
+	if strings.HasPrefix(title, "[") {
+		if end := strings.Index(title, "] "); end > 0 {
+			title = title[end+2:]
+		}
+	}
+	return strings.ToLower(title)
+}
+
+func runRefs(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		fmt.Fprintln(stderr, refsUsage)
+		return errors.New(refsUsage)
+	}
+	id := strings.TrimSpace(args[0])
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return err
+	}
+	nodes, err := indexer.ReadRefNodes(filepath.Join(root, ".taskgraph", "taskgraph.db"), id)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		scanCode, err := project.ReadScanCode(root)
+		if err != nil {
+			return err
+		}
+		if !scanCode {
+			fmt.Fprintf(stdout, "No code references to %s (set scan-code: true in .taskgraph/config.yml)\n", id)
+			return nil
+		}
+		fmt.Fprintf(stdout, "No code references to %s\n", id)
+		return nil
+	}
+	for _, n := range nodes {
+		fmt.Fprintf(stdout, "%s:%d: %s\n", n.Path, n.Line, n.Title)
+	}
+	return nil
+}
+
 func runIndex(stdout io.Writer, stderr io.Writer) error {
 	cwd, err := effectiveCWD()
 	if err != nil {
@@ -354,11 +659,18 @@ func runProjects(stdout io.Writer, stderr io.Writer) error {
 }
 
 func runGraph(args []string, stdout io.Writer, stderr io.Writer) error {
-	depth, maxChildren, includeClosed, err := parseGraphArgs(args)
+	opts, err := parseGraphArgs(args)
 	if err != nil {
 		fmt.Fprintln(stderr, err.Error())
 		return err
 	}
+	var query indexer.Query
+	if opts.where != "" {
+		if query, err = indexer.ParseQuery(opts.where); err != nil {
+			fmt.Fprintln(stderr, err.Error())
+			return err
+		}
+	}
 
 	cwd, err := effectiveCWD()
 	if err != nil {
@@ -378,46 +690,235 @@ func runGraph(args []string, stdout io.Writer, stderr io.Writer) error {
 	if err != nil {
 		return err
 	}
-
-	selectedRoots := selectGraphRoots(nodes)
-	children := graphChildren(nodes)
-	byID := make(map[string]indexer.Node, len(nodes))
-	for _, node := range nodes {
-		byID[node.ID] = node
-	}
-	visible := graphVisibility(byID, children, selectedRoots, includeClosed)
-	for _, node := range nodes {
-		if !selectedRoots[node.ID] {
-			continue
-		}
-		if !visible[node.ID] {
-			continue
+	if opts.where != "" {
+		matches, err := indexer.ReadQueryNodes(dbPath, query)
+		if err != nil {
+			return err
 		}
-		fmt.Fprintln(stdout, formatGraphNode(node))
-		renderGraphChildren(stdout, children, selectedRoots, visible, node.ID, 1, depth, maxChildren)
+		nodes = keepNodes(nodes, withAncestors(nodes, nodeIDSet(matches)))
+	}
+
+	entries := buildGraphEntries(nodes, opts.depth, opts.maxChildren, opts.includeClosed)
+	switch opts.format {
+	case "dot":
+		renderGraphDOT(stdout, entries)
+	case "mermaid":
+		renderGraphMermaid(stdout, entries)
+	default:
+		renderGraphText(stdout, entries)
 	}
 	return nil
 }
 
-func runMigrateBeads(stdout io.Writer, stderr io.Writer) error {
+func runMigrateBeads(args []string, stdout io.Writer, stderr io.Writer) error {
+	dryRun := false
+	for _, arg := range args {
+		if arg != "--dry-run" {
+			fmt.Fprintln(stderr, migrateBeadsUsage)
+			return errors.New(migrateBeadsUsage)
+		}
+		dryRun = true
+	}
+
 	cwd, err := effectiveCWD()
 	if err != nil {
 		return err
 	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		root = cwd
+	}
 
-	summary, err := migrate.ImportBeadsIssues(cwd)
+	var summary migrate.Summary
+	if dryRun {
+		var changes []migrate.FileChange
+		changes, summary, err = migrate.PlanBeadsImport(root)
+		if err == nil {
+			for _, change := range changes {
+				fmt.Fprint(stdout, textdiff.Unified(change.Path, change.Before, change.After))
+			}
+		}
+	} else {
+		summary, err = migrate.ImportBeadsIssues(root)
+	}
 	if err != nil {
 		fmt.Fprintln(stderr, err)
 		return err
 	}
 
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
 	fmt.Fprintf(
 		stdout,
-		"Imported %d issues (%d tombstones skipped, %d invalid skipped)\n",
+		"%s %d issues, %d updated, %d unchanged (%d tombstones skipped, %d invalid skipped)\n",
+		verb,
 		summary.Imported,
+		summary.Updated,
+		summary.Unchanged,
 		summary.SkippedTombstone,
 		summary.SkippedInvalid,
 	)
+	fmt.Fprintf(
+		stdout,
+		"Mapped %d types, %d priorities, %d labeled, %d descriptions, %d parent links, %d dependencies (%d links skipped)\n",
+		summary.Types,
+		summary.Priorities,
+		summary.Labeled,
+		summary.Descriptions,
+		summary.ParentLinks,
+		summary.Dependencies,
+		summary.SkippedLinks,
+	)
+	if !dryRun && summary.Imported+summary.Updated > 0 {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runImport(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, importUsage)
+		return errors.New(importUsage)
+	}
+	switch args[0] {
+	case "todotxt":
+		return runImportTodoTxt(args[1:], stdout, stderr)
+	case "taskwarrior":
+		return runImportTaskwarrior(args[1:], stdout, stderr)
+	case "github-issues":
+		return runImportGitHubIssues(args[1:], stdout, stderr)
+	default:
+		err := fmt.Errorf("unknown import format: %s\n%s", args[0], importUsage)
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+}
+
+// parseImportFileArgs reads "<file> [--dry-run]" for file-based importers and
+// resolves the project root and input path.
+func parseImportFileArgs(args []string, stderr io.Writer) (root, input string, dryRun bool, err error) {
+	for _, arg := range args {
+		switch {
+		case arg == "--dry-run":
+			dryRun = true
+		case input == "" && arg != "":
+			input = arg
+		default:
+			fmt.Fprintln(stderr, importUsage)
+			return "", "", false, errors.New(importUsage)
+		}
+	}
+	if input == "" {
+		fmt.Fprintln(stderr, importUsage)
+		return "", "", false, errors.New(importUsage)
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return "", "", false, err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return "", "", false, err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return "", "", false, errors.New("not initialized")
+	}
+	if !filepath.IsAbs(input) {
+		input = filepath.Join(cwd, input)
+	}
+	return root, input, dryRun, nil
+}
+
+func runSync(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 || args[0] != "beads" {
+		fmt.Fprintln(stderr, syncUsage)
+		return errors.New(syncUsage)
+	}
+	dryRun := false
+	prefer, pushLabel := "", ""
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--dry-run":
+			dryRun = true
+		case "--prefer":
+			if i+1 >= len(rest) || (rest[i+1] != "tg" && rest[i+1] != "beads") {
+				fmt.Fprintln(stderr, syncUsage)
+				return errors.New(syncUsage)
+			}
+			prefer = rest[i+1]
+			i++
+		case "--label":
+			if i+1 >= len(rest) || strings.TrimSpace(rest[i+1]) == "" {
+				fmt.Fprintln(stderr, syncUsage)
+				return errors.New(syncUsage)
+			}
+			pushLabel = rest[i+1]
+			i++
+		default:
+			fmt.Fprintln(stderr, syncUsage)
+			return errors.New(syncUsage)
+		}
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	var summary migrate.SyncSummary
+	if dryRun {
+		var changes []migrate.FileChange
+		changes, summary, err = migrate.PlanBeadsSync(root, prefer, pushLabel, time.Now())
+		if err == nil {
+			for _, change := range changes {
+				fmt.Fprint(stdout, textdiff.Unified(change.Path, change.Before, change.After))
+			}
+		}
+	} else {
+		summary, err = migrate.SyncBeads(root, prefer, pushLabel)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return err
+	}
+
+	fmt.Fprintf(
+		stdout,
+		"Pulled %d new and %d updated issues from Beads; pushed %d new and %d updated tasks to Beads\n",
+		summary.PulledNew,
+		summary.PulledUpdates,
+		summary.PushedNew,
+		summary.PushedUpdates,
+	)
+	if summary.RemovedInBeads > 0 || summary.RemovedInTG > 0 {
+		fmt.Fprintf(stdout, "Left alone: %d removed in Beads, %d removed from markdown\n", summary.RemovedInBeads, summary.RemovedInTG)
+	}
+	for _, id := range summary.Conflicts {
+		fmt.Fprintf(stdout, "conflict: %s changed on both sides; rerun with --prefer tg or --prefer beads\n", id)
+	}
+	if !dryRun && summary.PulledNew+summary.PulledUpdates+summary.PushedNew > 0 {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -440,28 +941,53 @@ func effectiveCWD() (string, error) {
 	return os.Getwd()
 }
 
-func parseListArgs(args []string) (bool, []string, error) {
-	includeClosed := false
+type listOptions struct {
+	includeClosed bool
+	labels        []string
+	where         string
+	sort          string
+}
+
+func parseListArgs(args []string) (listOptions, error) {
+	var opts listOptions
 	var labels []string
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--all":
-			includeClosed = true
+			opts.includeClosed = true
 		case "--label":
 			if i+1 >= len(args) {
-				return false, nil, fmt.Errorf("usage: tg list [--all] [--label name]")
+				return listOptions{}, errors.New(listUsage)
 			}
 			label := tasks.NormalizeLabelsCSV(args[i+1])
 			if len(label) == 0 {
-				return false, nil, fmt.Errorf("usage: tg list [--all] [--label name]")
+				return listOptions{}, errors.New(listUsage)
 			}
 			labels = append(labels, label...)
 			i++
+		case "--where":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return listOptions{}, errors.New(listUsage)
+			}
+			opts.where = args[i+1]
+			i++
+		case "--sort":
+			if i+1 >= len(args) {
+				return listOptions{}, errors.New(listUsage)
+			}
+			switch args[i+1] {
+			case "mtime", "path", "title":
+				opts.sort = args[i+1]
+			default:
+				return listOptions{}, errors.New(listUsage)
+			}
+			i++
 		default:
-			return false, nil, fmt.Errorf("usage: tg list [--all] [--label name]")
+			return listOptions{}, errors.New(listUsage)
 		}
 	}
-	return includeClosed, tasks.MergeLabels(labels), nil
+	opts.labels = tasks.MergeLabels(labels)
+	return opts, nil
 }
 
 func parseInboxArgs(args []string) (bool, []string, error) {
@@ -505,41 +1031,68 @@ func parseCloseArgs(args []string) (string, string, error) {
 	return id, reason, nil
 }
 
-func parseGraphArgs(args []string) (int, int, bool, error) {
-	depth := 4
-	maxChildren := 5
-	includeClosed := false
+const listUsage = "usage: tg list [--all] [--label name] [--where query] [--sort mtime|path|title]"
+
+const graphUsage = "usage: tg graph [--depth N] [--max-children N] [--all] [--format text|dot|mermaid] [--where query]"
+
+type graphOptions struct {
+	depth         int
+	maxChildren   int
+	includeClosed bool
+	format        string
+	where         string
+}
+
+func parseGraphArgs(args []string) (graphOptions, error) {
+	opts := graphOptions{depth: 4, maxChildren: 5, format: "text"}
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--all":
-			includeClosed = true
+			opts.includeClosed = true
 		case "--depth":
 			if i+1 >= len(args) {
-				return 0, 0, false, fmt.Errorf("usage: tg graph [--depth N] [--max-children N] [--all]")
+				return graphOptions{}, fmt.Errorf(graphUsage)
 			}
 			value, err := strconv.Atoi(args[i+1])
 			if err != nil || value < 1 {
-				return 0, 0, false, fmt.Errorf("usage: tg graph [--depth N] [--max-children N] [--all]")
+				return graphOptions{}, fmt.Errorf(graphUsage)
 			}
-			depth = value
+			opts.depth = value
 			i++
 		case "--max-children":
 			if i+1 >= len(args) {
-				return 0, 0, false, fmt.Errorf("usage: tg graph [--depth N] [--max-children N] [--all]")
+				return graphOptions{}, fmt.Errorf(graphUsage)
 			}
 			value, err := strconv.Atoi(args[i+1])
 			if err != nil || value < 1 {
-				return 0, 0, false, fmt.Errorf("usage: tg graph [--depth N] [--max-children N] [--all]")
+				return graphOptions{}, fmt.Errorf(graphUsage)
 			}
-			maxChildren = value
+			opts.maxChildren = value
+			i++
+		case "--format":
+			if i+1 >= len(args) {
+				return graphOptions{}, fmt.Errorf(graphUsage)
+			}
+			switch args[i+1] {
+			case "text", "dot", "mermaid":
+				opts.format = args[i+1]
+			default:
+				return graphOptions{}, fmt.Errorf(graphUsage)
+			}
+			i++
+		case "--where":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return graphOptions{}, fmt.Errorf(graphUsage)
+			}
+			opts.where = args[i+1]
 			i++
 		default:
-			return 0, 0, false, fmt.Errorf("usage: tg graph [--depth N] [--max-children N] [--all]")
+			return graphOptions{}, fmt.Errorf(graphUsage)
 		}
 	}
 
-	return depth, maxChildren, includeClosed, nil
+	return opts, nil
 }
 
 var graphRootTypes = map[string]bool{
@@ -668,9 +1221,43 @@ func isTypedGraphRoot(node indexer.Node) bool {
 	return graphRootTypes[taskType]
 }
 
-func renderGraphChildren(stdout io.Writer, children map[string][]indexer.Node, roots map[string]bool, visible map[string]bool, parentID string, level int, maxDepth int, maxChildren int) {
+// graphEntry is one line of the rendered graph: either a node or, when Hidden
+// is non-zero, a marker for children omitted by --max-children.
+type graphEntry struct {
+	Node     indexer.Node
+	Level    int
+	ParentID string
+	Hidden   int
+}
+
+// buildGraphEntries selects roots and walks their visible descendants in
+// display order, applying the depth and child limits.
+func buildGraphEntries(nodes []indexer.Node, maxDepth int, maxChildren int, includeClosed bool) []graphEntry {
+	selectedRoots := selectGraphRoots(nodes)
+	children := graphChildren(nodes)
+	byID := make(map[string]indexer.Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+	visible := graphVisibility(byID, children, selectedRoots, includeClosed)
+
+	var out []graphEntry
+	for _, node := range nodes {
+		if !selectedRoots[node.ID] {
+			continue
+		}
+		if !visible[node.ID] {
+			continue
+		}
+		out = append(out, graphEntry{Node: node})
+		out = collectGraphChildren(out, children, selectedRoots, visible, node.ID, 1, maxDepth, maxChildren)
+	}
+	return out
+}
+
+func collectGraphChildren(out []graphEntry, children map[string][]indexer.Node, roots map[string]bool, visible map[string]bool, parentID string, level int, maxDepth int, maxChildren int) []graphEntry {
 	if level > maxDepth {
-		return
+		return out
 	}
 	visibleChildren := make([]indexer.Node, 0)
 	for _, child := range children[parentID] {
@@ -688,11 +1275,23 @@ func renderGraphChildren(stdout io.Writer, children map[string][]indexer.Node, r
 		visibleChildren = visibleChildren[:maxChildren]
 	}
 	for _, child := range visibleChildren {
-		fmt.Fprintf(stdout, "%s%s\n", strings.Repeat("  ", level), formatGraphNode(child))
-		renderGraphChildren(stdout, children, roots, visible, child.ID, level+1, maxDepth, maxChildren)
+		out = append(out, graphEntry{Node: child, Level: level, ParentID: parentID})
+		out = collectGraphChildren(out, children, roots, visible, child.ID, level+1, maxDepth, maxChildren)
 	}
 	if hiddenCount > 0 {
-		fmt.Fprintf(stdout, "%s... %d more\n", strings.Repeat("  ", level), hiddenCount)
+		out = append(out, graphEntry{Level: level, ParentID: parentID, Hidden: hiddenCount})
+	}
+	return out
+}
+
+func renderGraphText(stdout io.Writer, entries []graphEntry) {
+	for _, entry := range entries {
+		indent := strings.Repeat("  ", entry.Level)
+		if entry.Hidden > 0 {
+			fmt.Fprintf(stdout, "%s... %d more\n", indent, entry.Hidden)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s%s\n", indent, formatGraphNode(entry.Node))
 	}
 }
 
@@ -766,50 +1365,83 @@ func cleanGraphTitle(title string) string {
 	return strings.Join(strings.Fields(cleaned), " ")
 }
 
+// hasAllLabels reports whether every required label, or a label below it in
+// the hierarchy, is among actual.
 func hasAllLabels(actual, required []string) bool {
-	if len(required) == 0 {
-		return true
-	}
-	seen := map[string]bool{}
-	for _, label := range actual {
-		seen[label] = true
-	}
-	for _, label := range required {
-		if !seen[label] {
+	for _, want := range required {
+		found := false
+		for _, label := range actual {
+			if tasks.LabelMatches(label, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
 			return false
 		}
 	}
 	return true
 }
 
-func parseAddArgs(args []string) (string, []string, string, error) {
+type addOptions struct {
+	text     string
+	labels   []string
+	taskType string
+	toPath   string
+	heading  string
+	parentID string
+}
+
+func parseAddArgs(args []string) (addOptions, error) {
+	var opts addOptions
 	var textParts []string
 	var labels []string
-	taskType := ""
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--labels", "-l":
 			if i+1 >= len(args) {
-				return "", nil, "", fmt.Errorf(addUsage)
+				return addOptions{}, fmt.Errorf(addUsage)
 			}
 			labels = append(labels, tasks.NormalizeLabelsCSV(args[i+1])...)
 			i++
 		case "--type", "-t":
 			if i+1 >= len(args) {
-				return "", nil, "", fmt.Errorf(addUsage)
+				return addOptions{}, fmt.Errorf(addUsage)
+			}
+			if opts.taskType != "" {
+				return addOptions{}, fmt.Errorf("multiple --type values are not allowed")
+			}
+			opts.taskType = args[i+1]
+			i++
+		case "--to":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return addOptions{}, fmt.Errorf(addUsage)
+			}
+			path, heading, _ := strings.Cut(strings.TrimSpace(args[i+1]), "#")
+			if strings.TrimSpace(path) == "" {
+				return addOptions{}, fmt.Errorf(addUsage)
 			}
-			if taskType != "" {
-				return "", nil, "", fmt.Errorf("multiple --type values are not allowed")
+			opts.toPath = strings.TrimSpace(path)
+			opts.heading = strings.TrimSpace(heading)
+			i++
+		case "--parent":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return addOptions{}, fmt.Errorf(addUsage)
 			}
-			taskType = args[i+1]
+			opts.parentID = strings.TrimSpace(args[i+1])
 			i++
 		default:
 			textParts = append(textParts, args[i])
 		}
 	}
+	if opts.heading != "" && opts.parentID != "" {
+		return addOptions{}, fmt.Errorf("use either --to path#heading or --parent, not both")
+	}
 
-	return strings.TrimSpace(strings.Join(textParts, " ")), tasks.MergeLabels(labels), taskType, nil
+	opts.text = strings.TrimSpace(strings.Join(textParts, " "))
+	opts.labels = tasks.MergeLabels(labels)
+	return opts, nil
 }
 
 func containsString(items []string, value string) bool {
@@ -416,6 +416,33 @@ func TestListFiltersByLabel(t *testing.T) {
 	}
 }
 
+func TestListAndInboxMatchNestedLabels(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-aaa] Fix nav #area/frontend\n- [ ] [tg-bbb] Tidy #areas\n- [ ] [tg-ccc] Plan #type/epic\n")
+	if _, stderr, err := run([]string{"index"}); err != nil {
+		t.Fatalf("index returned err: %v stderr=%q", err, stderr)
+	}
+
+	for _, cmd := range [][]string{{"list", "--label", "area"}, {"inbox", "--label", "area"}} {
+		stdout, stderr, err := run(cmd)
+		if err != nil {
+			t.Fatalf("%v returned err: %v stderr=%q", cmd, err, stderr)
+		}
+		if !strings.Contains(stdout, "Fix nav #area/frontend") || strings.Contains(stdout, "Tidy") {
+			t.Fatalf("%v: unexpected output %q", cmd, stdout)
+		}
+	}
+
+	stdout, _, err := run([]string{"query", "type:epic"})
+	if err != nil || !strings.Contains(stdout, "Plan #type/epic") {
+		t.Fatalf("expected #type/epic to count as an epic, err=%v output %q", err, stdout)
+	}
+}
+
 func TestAddRequiresTaskText(t *testing.T) {
 	dir := t.TempDir()
 	chdir(t, dir)
@@ -759,6 +786,133 @@ func TestGraphHidesStructuralRootWhenOnlyClosedDescendantsRemain(t *testing.T) {
 	}
 }
 
+func TestGraphFormatDOT(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	_, stderr, err := run([]string{"init"})
+	if err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+
+	mustWrite(t, filepath.Join(dir, "projects.md"), strings.Join([]string{
+		"# Projects",
+		"",
+		"## Active",
+		"- [ ] TaskGraph \"roadmap\" #t-project",
+		"  - [ ] Add tg graph",
+		"  - [x] Add tg next",
+	}, "\n")+"\n")
+
+	_, stderr, err = run([]string{"index"})
+	if err != nil {
+		t.Fatalf("index returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, stderr, err := run([]string{"graph", "--all", "--format", "dot"})
+	if err != nil {
+		t.Fatalf("graph returned err: %v stderr=%q", err, stderr)
+	}
+
+	want := strings.Join([]string{
+		"digraph taskgraph {",
+		"  rankdir=LR;",
+		`  node [shape=box, style="rounded", fontname="Helvetica"];`,
+		`  n1 [label="[project] TaskGraph \"roadmap\"", fillcolor="#d0ebff", style="rounded,filled"];`,
+		`  n2 [label="Add tg graph", style="rounded"];`,
+		`  n3 [label="Add tg next", fontcolor="gray40", style="rounded,dashed"];`,
+		"  n1 -> n2;",
+		"  n1 -> n3;",
+		"}",
+	}, "\n") + "\n"
+	if stdout != want {
+		t.Fatalf("unexpected dot output:\n%s\nwant:\n%s", stdout, want)
+	}
+}
+
+func TestGraphFormatsDrawDependencyEdges(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	mustWrite(t, filepath.Join(dir, "plan.md"), strings.Join([]string{
+		"- [ ] [tg-aaa] Launch #t-epic",
+		"  - [ ] [tg-bbb] Book venue",
+		"  - [ ] [tg-ccc] Print flyers ⛔ tg-bbb,tg-zzz",
+	}, "\n")+"\n")
+	if _, stderr, err := run([]string{"index"}); err != nil {
+		t.Fatalf("index returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, _, err := run([]string{"graph", "--format", "dot"})
+	if err != nil || !strings.Contains(stdout, "  n2 -> n3 [style=dashed, label=\"blocks\"];\n") {
+		t.Fatalf("expected a dependency edge in dot output, err=%v:\n%s", err, stdout)
+	}
+	stdout, _, err = run([]string{"graph", "--format", "mermaid"})
+	if err != nil || !strings.Contains(stdout, "  n2 -.->|blocks| n3\n") {
+		t.Fatalf("expected a dependency edge in mermaid output, err=%v:\n%s", err, stdout)
+	}
+}
+
+func TestGraphFormatMermaid(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	_, stderr, err := run([]string{"init"})
+	if err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+
+	mustWrite(t, filepath.Join(dir, "wide.md"), strings.Join([]string{
+		"# Wide",
+		"",
+		"## Platform",
+		"- [ ] Branch",
+		"  - [ ] Child 1",
+		"  - [ ] Child 2",
+		"  - [ ] Child 3",
+	}, "\n")+"\n")
+
+	_, stderr, err = run([]string{"index"})
+	if err != nil {
+		t.Fatalf("index returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, stderr, err := run([]string{"graph", "--format", "mermaid", "--max-children", "2"})
+	if err != nil {
+		t.Fatalf("graph returned err: %v stderr=%q", err, stderr)
+	}
+
+	want := strings.Join([]string{
+		"graph TD",
+		`  n1("Platform")`,
+		`  n2["Branch"]`,
+		`  n3["Child 1"]`,
+		`  n4["Child 2"]`,
+		`  n5["... 1 more"]`,
+		"  n1 --> n2",
+		"  n2 --> n3",
+		"  n2 --> n4",
+		"  n2 --> n5",
+		"  classDef omitted fill:none,stroke:none,color:#868e96",
+		"  class n5 omitted",
+	}, "\n") + "\n"
+	if stdout != want {
+		t.Fatalf("unexpected mermaid output:\n%s\nwant:\n%s", stdout, want)
+	}
+}
+
+func TestGraphRejectsUnknownFormat(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	_, stderr, err := run([]string{"graph", "--format", "svg"})
+	if err == nil || !strings.Contains(stderr, "--format text|dot|mermaid") {
+		t.Fatalf("expected usage error, got err=%v stderr=%q", err, stderr)
+	}
+}
+
 func TestAddUsesTGCWDOverride(t *testing.T) {
 	targetDir := t.TempDir()
 	otherDir := t.TempDir()
@@ -936,6 +1090,91 @@ func TestAddUpdatesSQLiteIndex(t *testing.T) {
 	}
 }
 
+func TestAddParentInsertsIndentedChildInParentFile(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	_, stderr, err := run([]string{"init"})
+	if err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	mustWrite(t, filepath.Join(dir, "docs", "roadmap.md"), strings.Join([]string{
+		"# Roadmap",
+		"",
+		"- [ ] [tg-epic] Launch #t-epic",
+		"- [ ] Unrelated",
+	}, "\n")+"\n")
+
+	_, stderr, err = run([]string{"add", "write launch post", "--parent", "tg-epic"})
+	if err != nil {
+		t.Fatalf("add returned err: %v stderr=%q", err, stderr)
+	}
+
+	lines := strings.Split(readFile(t, filepath.Join(dir, "docs", "roadmap.md")), "\n")
+	if !strings.HasPrefix(lines[3], "  - [ ] ➕") || !strings.HasSuffix(lines[3], "write launch post") {
+		t.Fatalf("expected indented child under parent, got %q", lines)
+	}
+	if got := readFile(t, filepath.Join(dir, ".taskgraph", "issues.md")); got != "" {
+		t.Fatalf("expected inbox untouched, got %q", got)
+	}
+
+	stdout, stderr, err := run([]string{"graph"})
+	if err != nil {
+		t.Fatalf("graph returned err: %v stderr=%q", err, stderr)
+	}
+	if !strings.Contains(stdout, "[epic] [tg-epic] Launch\n  ➕") {
+		t.Fatalf("expected new child under epic in graph, got %q", stdout)
+	}
+}
+
+func TestAddToFileHeading(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	_, stderr, err := run([]string{"init"})
+	if err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	mustWrite(t, filepath.Join(dir, "docs", "roadmap.md"), "# Roadmap\n\n## Q3\n\n- [ ] existing\n\n## Q4\n")
+
+	_, stderr, err = run([]string{"add", "ship beta", "--to", "docs/roadmap.md#Q3", "--labels", "launch"})
+	if err != nil {
+		t.Fatalf("add returned err: %v stderr=%q", err, stderr)
+	}
+
+	lines := strings.Split(readFile(t, filepath.Join(dir, "docs", "roadmap.md")), "\n")
+	if !strings.HasPrefix(lines[5], "- [ ] ➕") || !strings.HasSuffix(lines[5], "ship beta #launch") {
+		t.Fatalf("expected task at end of Q3 section, got %q", lines)
+	}
+
+	_, stderr, err = run([]string{"add", "orphan", "--to", "docs/roadmap.md#Missing"})
+	if err == nil || !strings.Contains(stderr, "heading not found: Missing") {
+		t.Fatalf("expected missing heading error, got err=%v stderr=%q", err, stderr)
+	}
+}
+
+func TestAddToRejectsPathsOutsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	mustMkdirAll(t, filepath.Join(project, ".taskgraph"))
+	mustWrite(t, filepath.Join(project, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	outside := filepath.Join(dir, "outside.md")
+	mustWrite(t, outside, "# Outside\n")
+	chdir(t, project)
+
+	for _, target := range []string{"../outside.md", outside, "docs/../../outside.md"} {
+		_, stderr, err := run([]string{"add", "escape", "--to", target})
+		if err == nil || !strings.Contains(stderr, "outside the project") {
+			t.Fatalf("--to %s: expected outside-root error, got err=%v stderr=%q", target, err, stderr)
+		}
+	}
+	if got := readFile(t, outside); got != "# Outside\n" {
+		t.Fatalf("file outside the root was modified: %q", got)
+	}
+}
+
 func TestMigrateBeadsImportsIntoIssuesMarkdown(t *testing.T) {
 	dir := t.TempDir()
 	chdir(t, dir)
@@ -953,7 +1192,7 @@ func TestMigrateBeadsImportsIntoIssuesMarkdown(t *testing.T) {
 	if err != nil {
 		t.Fatalf("migrate-beads returned err: %v stderr=%q", err, stderr)
 	}
-	if !strings.Contains(stdout, "Imported 2 issues") {
+	if !strings.Contains(stdout, "Imported 2 issues") || !strings.Contains(stdout, "Mapped 0 types") {
 		t.Fatalf("expected import summary, got %q", stdout)
 	}
 
@@ -969,6 +1208,86 @@ func TestMigrateBeadsImportsIntoIssuesMarkdown(t *testing.T) {
 	}
 }
 
+func TestMigrateBeadsDryRunFromSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustMkdirAll(t, filepath.Join(dir, ".beads"))
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	chdir(t, filepath.Join(dir, "docs"))
+
+	issuesPath := filepath.Join(dir, ".taskgraph", "issues.md")
+	mustWrite(t, issuesPath, "- [ ] [beads:pl-1] Open item\n")
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join([]string{
+		`{"id":"pl-1","title":"Open item","status":"closed"}`,
+		`{"id":"pl-2","title":"New item","status":"open"}`,
+	}, "\n")+"\n")
+
+	stdout, stderr, err := run([]string{"migrate-beads", "--dry-run"})
+	if err != nil {
+		t.Fatalf("migrate-beads --dry-run returned err: %v stderr=%q", err, stderr)
+	}
+	for _, want := range []string{
+		"--- a/.taskgraph/issues.md",
+		"-- [ ] [beads:pl-1] Open item",
+		"+- [x] [beads:pl-1] Open item",
+		"+- [ ] [beads:pl-2] New item",
+		"Would import 1 issues, 1 updated, 0 unchanged",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in dry-run output, got %q", want, stdout)
+		}
+	}
+	if got := readFile(t, issuesPath); got != "- [ ] [beads:pl-1] Open item\n" {
+		t.Fatalf("dry run wrote changes: %q", got)
+	}
+
+	if _, stderr, err := run([]string{"migrate-beads"}); err != nil {
+		t.Fatalf("migrate-beads returned err: %v stderr=%q", err, stderr)
+	}
+	stdout, _, err = run([]string{"migrate-beads"})
+	if err != nil || !strings.Contains(stdout, "Imported 0 issues, 0 updated, 2 unchanged") {
+		t.Fatalf("expected idempotent re-run, got err=%v stdout=%q", err, stdout)
+	}
+	if got := readFile(t, issuesPath); strings.Count(got, "beads:pl-2") != 1 {
+		t.Fatalf("expected single copy of new issue, got %q", got)
+	}
+
+	if _, stderr, err := run([]string{"migrate-beads", "--force"}); err == nil || !strings.Contains(stderr, "usage: tg migrate-beads") {
+		t.Fatalf("expected usage error, got err=%v stderr=%q", err, stderr)
+	}
+}
+
+func TestSyncBeadsCommand(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustMkdirAll(t, filepath.Join(dir, ".beads"))
+	chdir(t, dir)
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "")
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), `{"id":"bd-1","title":"From beads","status":"open"}`+"\n")
+
+	stdout, stderr, err := run([]string{"sync", "beads", "--dry-run"})
+	if err != nil {
+		t.Fatalf("sync --dry-run returned err: %v stderr=%q", err, stderr)
+	}
+	if !strings.Contains(stdout, "+- [ ] [beads:bd-1] From beads") || !strings.Contains(stdout, "Pulled 1 new") {
+		t.Fatalf("unexpected dry-run output: %q", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".taskgraph", "beads-sync.json")); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not write sync state, stat err=%v", err)
+	}
+
+	if _, stderr, err := run([]string{"sync", "beads"}); err != nil {
+		t.Fatalf("sync returned err: %v stderr=%q", err, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".taskgraph", "beads-sync.json")); err != nil {
+		t.Fatalf("expected sync state file: %v", err)
+	}
+
+	if _, stderr, err := run([]string{"sync", "jira"}); err == nil || !strings.Contains(stderr, "usage: tg sync beads") {
+		t.Fatalf("expected usage error, got err=%v stderr=%q", err, stderr)
+	}
+}
+
 func TestMigrateBeadsRequiresLocalBeadsAndTaskgraphDirs(t *testing.T) {
 	dir := t.TempDir()
 	chdir(t, dir)
@@ -1100,3 +1419,38 @@ func matchesTaskLine(line, prefix, text string) bool {
 	ok, _ := regexp.MatchString(pattern, line)
 	return ok
 }
+
+func TestRefsListsCodeComments(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "scan-code: true\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-abc] Handle retries\n")
+	mustWrite(t, filepath.Join(dir, "client.go"), strings.Join([]string{
+		"package client",
+		"",
+		"// TODO(tg-abc): retry on 503",
+		"func Do() {}",
+	}, "\n")+"\n")
+
+	stdout, stderr, err := run([]string{"refs", "tg-abc"})
+	if err != nil {
+		t.Fatalf("refs returned err: %v stderr=%q", err, stderr)
+	}
+	if want := "client.go:3: TODO(tg-abc): retry on 503"; !strings.Contains(stdout, want) {
+		t.Fatalf("expected %q in output, got %q", want, stdout)
+	}
+
+	stdout, _, err = run([]string{"refs", "tg-zzz"})
+	if err != nil {
+		t.Fatalf("refs returned err: %v", err)
+	}
+	if !strings.Contains(stdout, "No code references to tg-zzz") {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+
+	if _, _, err := run([]string{"refs"}); err == nil {
+		t.Fatalf("expected usage error")
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"taskgraph/internal/doctor"
+	"taskgraph/internal/project"
+)
+
+const doctorUsage = "usage: tg doctor [--fix]"
+
+// runDoctor prints every problem doctor.Check finds and fails when any of
+// them is an error. With --fix the mechanical problems are repaired first
+// and only what is left is reported.
+func runDoctor(args []string, stdout io.Writer, stderr io.Writer) error {
+	fix := false
+	for _, arg := range args {
+		if arg != "--fix" {
+			fmt.Fprintln(stderr, doctorUsage)
+			return errors.New(doctorUsage)
+		}
+		fix = true
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	problems, err := doctor.Check(root)
+	if err != nil {
+		return err
+	}
+	if fix {
+		fixed, err := doctor.Fix(root, problems)
+		if err != nil {
+			return err
+		}
+		if fixed > 0 {
+			fmt.Fprintf(stdout, "Fixed %d problem(s).\n", fixed)
+		}
+		if problems, err = doctor.Check(root); err != nil {
+			return err
+		}
+	}
+
+	errorCount, warningCount, fixable := 0, 0, 0
+	for _, p := range problems {
+		fmt.Fprintln(stdout, p.String())
+		if p.Severity == doctor.Error {
+			errorCount++
+		} else {
+			warningCount++
+		}
+		if p.Fixable {
+			fixable++
+		}
+	}
+	if len(problems) == 0 {
+		fmt.Fprintln(stdout, "No problems found.")
+		return nil
+	}
+	summary := fmt.Sprintf("%d error(s), %d warning(s)", errorCount, warningCount)
+	if fixable > 0 {
+		summary += fmt.Sprintf("; %d can be fixed with tg doctor --fix", fixable)
+	}
+	fmt.Fprintln(stdout, summary)
+	if errorCount > 0 {
+		return fmt.Errorf("doctor found %d error(s)", errorCount)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorReportsAndFixes(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	issues := filepath.Join(dir, ".taskgraph", "issues.md")
+	mustWrite(t, issues, strings.Join([]string{
+		"- [ ] [tg-aaa] Ship it",
+		"* [ ] Star marker",
+		"- [ ] [tg-aaa] Ship it",
+	}, "\n")+"\n")
+	if _, stderr, err := run([]string{"index"}); err != nil {
+		t.Fatalf("index returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, _, err := run([]string{"doctor"})
+	if err == nil {
+		t.Fatalf("expected doctor to fail on a duplicate ID, output:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, ".taskgraph/issues.md:3: error: duplicate task ID tg-aaa") ||
+		!strings.Contains(stdout, ".taskgraph/issues.md:2: warning: malformed checkbox") ||
+		!strings.Contains(stdout, "1 error(s), 1 warning(s); 2 can be fixed with tg doctor --fix") {
+		t.Fatalf("unexpected doctor output:\n%s", stdout)
+	}
+
+	stdout, stderr, err := run([]string{"doctor", "--fix"})
+	if err != nil {
+		t.Fatalf("doctor --fix returned err: %v stderr=%q output:\n%s", err, stderr, stdout)
+	}
+	if !strings.Contains(stdout, "Fixed 2 problem(s).") || !strings.Contains(stdout, "No problems found.") {
+		t.Fatalf("unexpected doctor --fix output:\n%s", stdout)
+	}
+	b, err := os.ReadFile(issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "- [ ] [tg-aaa] Ship it\n- [ ] Star marker\n" {
+		t.Fatalf("unexpected fixed file:\n%s", b)
+	}
+
+	if _, _, err := run([]string{"doctor", "--bogus"}); err == nil {
+		t.Fatalf("expected usage error")
+	}
+}
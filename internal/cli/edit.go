@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+)
+
+const editUsage = "usage: tg edit <id> [--title text] [--add-label a,b] [--remove-label a,b] [--type name]"
+
+const rmUsage = "usage: tg rm <id> [--children]"
+
+func parseEditArgs(args []string) (string, tasks.TaskEdit, error) {
+	var edit tasks.TaskEdit
+	id := ""
+	changed := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--title", "--add-label", "--remove-label", "--type":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", tasks.TaskEdit{}, errors.New(editUsage)
+			}
+			value := args[i+1]
+			i++
+			changed = true
+			switch arg {
+			case "--title":
+				edit.Title = value
+			case "--add-label":
+				edit.AddLabels = append(edit.AddLabels, tasks.NormalizeLabelsCSV(value)...)
+			case "--remove-label":
+				edit.RemoveLabels = append(edit.RemoveLabels, tasks.NormalizeLabelsCSV(value)...)
+			case "--type":
+				edit.Type = value
+			}
+		default:
+			if strings.HasPrefix(arg, "-") || id != "" {
+				return "", tasks.TaskEdit{}, errors.New(editUsage)
+			}
+			id = strings.TrimSpace(arg)
+		}
+	}
+	if id == "" || !changed {
+		return "", tasks.TaskEdit{}, errors.New(editUsage)
+	}
+	return id, edit, nil
+}
+
+// runEdit rewrites a task line in place, wherever it lives, in the layout
+// tg add writes.
+func runEdit(args []string, stdout io.Writer, stderr io.Writer) error {
+	id, edit, err := parseEditArgs(args)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+	allowed, err := project.ReadAllowedIssueTypes(root)
+	if err != nil {
+		return err
+	}
+	if err := checkRegisteredLabels(root, tasks.MergeLabels(edit.AddLabels, tasks.ExtractLabels(edit.Title))); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+	relPath, err := findTaskPath(root, id)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+
+	var edited string
+	err = tasks.UpdateTaskLine(filepath.Join(root, filepath.FromSlash(relPath)), id, func(line string) (string, error) {
+		out, task, err := tasks.EditTaskLine(line, edit)
+		if err != nil {
+			return "", err
+		}
+		taskType, err := tasks.ExtractTaskTypeFromLabels(task.Labels)
+		if err != nil {
+			return "", err
+		}
+		if taskType != "" && !containsString(allowed, taskType) {
+			sort.Strings(allowed)
+			return "", fmt.Errorf("unknown task type: %s (allowed: %s)", taskType, strings.Join(allowed, ", "))
+		}
+		edited = strings.TrimSpace(out)
+		return out, nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Updated task: %s\n", edited)
+	return nil
+}
+
+// runRm deletes a task. Its subtasks move up a level unless --children asks
+// for them to be deleted as well.
+func runRm(args []string, stdout io.Writer, stderr io.Writer) error {
+	id := ""
+	withChildren := false
+	for _, arg := range args {
+		switch {
+		case arg == "--children":
+			withChildren = true
+		case strings.HasPrefix(arg, "-") || id != "" || strings.TrimSpace(arg) == "":
+			fmt.Fprintln(stderr, rmUsage)
+			return errors.New(rmUsage)
+		default:
+			id = strings.TrimSpace(arg)
+		}
+	}
+	if id == "" {
+		fmt.Fprintln(stderr, rmUsage)
+		return errors.New(rmUsage)
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+	relPath, err := findTaskPath(root, id)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+
+	nested, err := tasks.RemoveTask(filepath.Join(root, filepath.FromSlash(relPath)), id, withChildren)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return err
+	}
+	switch {
+	case nested > 0 && withChildren:
+		fmt.Fprintf(stdout, "Removed %s and %d nested line(s) from %s\n", id, nested, relPath)
+	case nested > 0:
+		fmt.Fprintf(stdout, "Removed %s from %s; its %d nested line(s) moved up a level\n", id, relPath, nested)
+	default:
+		fmt.Fprintf(stdout, "Removed %s from %s\n", id, relPath)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditAndRmTasks(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-aaa] Inbox item #someday\n")
+	plan := filepath.Join(dir, "plan.md")
+	mustWrite(t, plan, "# Plan\n\n- [ ] [tg-ep1] Launch #t-epic\n  - [ ] [tg-bbb] Book venue\n  - [ ] [tg-ccc] Print flyers\n")
+
+	stdout, stderr, err := run([]string{"edit", "tg-aaa", "--title", "Call #venue", "--remove-label", "someday", "--type", "task"})
+	if err != nil {
+		t.Fatalf("edit returned err: %v stderr=%q", err, stderr)
+	}
+	if stdout != "Updated task: - [ ] [tg-aaa] Call #venue #t-task\n" {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+
+	_, stderr, err = run([]string{"edit", "tg-bbb", "--type", "wizard"})
+	if err == nil || !strings.Contains(stderr, "unknown task type: wizard") {
+		t.Fatalf("expected type validation error, got err=%v stderr=%q", err, stderr)
+	}
+	if _, _, err := run([]string{"edit", "tg-bbb"}); err == nil {
+		t.Fatalf("expected usage error without changes")
+	}
+
+	if _, stderr, err := run([]string{"edit", "tg-bbb", "--add-label", "Venue"}); err != nil {
+		t.Fatalf("edit returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, stderr, err = run([]string{"rm", "tg-ep1"})
+	if err != nil {
+		t.Fatalf("rm returned err: %v stderr=%q", err, stderr)
+	}
+	if stdout != "Removed tg-ep1 from plan.md; its 2 nested line(s) moved up a level\n" {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+	b, _ := os.ReadFile(plan)
+	if string(b) != "# Plan\n\n- [ ] [tg-bbb] Book venue #venue\n- [ ] [tg-ccc] Print flyers\n" {
+		t.Fatalf("unexpected plan.md:\n%s", b)
+	}
+
+	stdout, _, err = run([]string{"list", "--where", "label:venue"})
+	if err != nil || strings.Count(stdout, "\n") != 2 {
+		t.Fatalf("expected the index to be refreshed, err=%v output:\n%s", err, stdout)
+	}
+
+	if _, _, err := run([]string{"rm", "tg-zzz"}); err == nil {
+		t.Fatalf("expected error for unknown task")
+	}
+}
@@ -0,0 +1,456 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+)
+
+const exportUsage = "usage: tg export html <dir> | tg export todotxt [file]"
+
+// siteTask is one checklist item or heading as rendered on a file page.
+type siteTask struct {
+	Kind   string
+	Title  string
+	Line   int
+	Depth  int
+	Closed bool
+	Labels []string
+}
+
+// siteFile is a page describing one indexed markdown file.
+type siteFile struct {
+	Path    string
+	Title   string
+	Href    string
+	Project bool
+	Done    int
+	Total   int
+	Tasks   []siteTask
+}
+
+func (f siteFile) Open() int {
+	return f.Total - f.Done
+}
+
+func (f siteFile) Percent() int {
+	if f.Total == 0 {
+		return 0
+	}
+	return f.Done * 100 / f.Total
+}
+
+// siteLabel is a label index entry and the tasks carrying it.
+type siteLabel struct {
+	Name        string
+	Href        string
+	Description string
+	Nodes       []indexer.Node
+}
+
+// siteSearchEntry is one record in search.js, kept short because every page
+// loads it.
+type siteSearchEntry struct {
+	Title   string `json:"t"`
+	Context string `json:"c"`
+	Href    string `json:"u"`
+	Closed  bool   `json:"x,omitempty"`
+}
+
+type sitePage struct {
+	Title  string
+	Base   string
+	Roots  []*graphItem
+	Files  []siteFile
+	File   siteFile
+	Labels []siteLabel
+	Label  siteLabel
+}
+
+func siteFileHref(path string) string {
+	return "files/" + path + ".html"
+}
+
+func siteLabelHref(label string) string {
+	return "labels/" + label + ".html"
+}
+
+func siteNodeHref(node indexer.Node) string {
+	if node.Kind == "file" {
+		return siteFileHref(node.Path)
+	}
+	return fmt.Sprintf("%s#L%d", siteFileHref(node.Path), node.Line)
+}
+
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"fileHref":   siteFileHref,
+	"labelHref":  siteLabelHref,
+	"nodeHref":   siteNodeHref,
+	"nodeTitle":  formatGraphNode,
+	"cleanTitle": cleanGraphTitle,
+	"indent": func(depth int) string {
+		return fmt.Sprintf("%.1fem", float64(depth)*1.5)
+	},
+}).Parse(`
+{{define "header"}}<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · TaskGraph</title>
+<link rel="stylesheet" href="{{.Base}}style.css">
+</head>
+<body data-base="{{.Base}}">
+<header>
+<a class="brand" href="{{.Base}}index.html">TaskGraph</a>
+<nav><a href="{{.Base}}index.html">Graph</a> <a href="{{.Base}}files/index.html">Files</a> <a href="{{.Base}}labels/index.html">Labels</a></nav>
+<input id="search" type="search" placeholder="Search tasks" autocomplete="off">
+</header>
+<ul id="results" hidden></ul>
+<main>
+<h1>{{.Title}}</h1>
+{{end}}
+
+{{define "footer"}}</main>
+<script src="{{.Base}}search.js"></script>
+<script src="{{.Base}}app.js"></script>
+</body>
+</html>
+{{end}}
+
+{{define "item"}}{{if .Hidden}}<li class="more">… {{.Hidden}} more</li>{{else}}<li class="{{.Node.Kind}}{{if eq .Node.State "closed"}} closed{{end}}">{{if .Children}}<details open><summary><a href="{{nodeHref .Node}}">{{nodeTitle .Node}}</a></summary>
+<ul>{{range .Children}}{{template "item" .}}{{end}}</ul>
+</details>{{else}}<a href="{{nodeHref .Node}}">{{nodeTitle .Node}}</a>{{end}}</li>
+{{end}}{{end}}
+
+{{define "index"}}{{template "header" .}}
+<p><button type="button" data-toggle="open">Expand all</button> <button type="button" data-toggle="close">Collapse all</button></p>
+<ul class="tree">{{range .Roots}}{{template "item" .}}{{end}}</ul>
+{{template "footer" .}}{{end}}
+
+{{define "files"}}{{template "header" .}}
+<table>
+<thead><tr><th>File</th><th>Progress</th><th>Open</th></tr></thead>
+<tbody>{{range .Files}}
+<tr><td><a href="{{$.Base}}{{.Href}}">{{.Title}}</a>{{if .Project}} <span class="tag">project</span>{{end}}<br><code>{{.Path}}</code></td><td><progress max="100" value="{{.Percent}}"></progress> {{.Done}}/{{.Total}}</td><td>{{.Open}}</td></tr>{{end}}
+</tbody>
+</table>
+{{template "footer" .}}{{end}}
+
+{{define "file"}}{{template "header" .}}
+<p><code>{{.File.Path}}</code></p>
+<p><progress max="100" value="{{.File.Percent}}"></progress> {{.File.Done}} of {{.File.Total}} tasks done ({{.File.Percent}}%)</p>
+<ul class="tasks">{{range .File.Tasks}}
+<li id="L{{.Line}}" class="{{.Kind}}{{if .Closed}} closed{{end}}" style="margin-left: {{indent .Depth}}">{{if eq .Kind "heading"}}<strong>{{cleanTitle .Title}}</strong>{{else}}<span class="box">{{if .Closed}}☑{{else}}☐{{end}}</span> {{cleanTitle .Title}}{{range .Labels}} <a class="label" data-label="{{.}}" href="{{$.Base}}{{labelHref .}}">#{{.}}</a>{{end}}{{end}}</li>{{end}}
+</ul>
+{{template "footer" .}}{{end}}
+
+{{define "labelindex"}}{{template "header" .}}
+<ul class="labels">{{range .Labels}}
+<li><a class="label" data-label="{{.Name}}" href="{{$.Base}}{{.Href}}">#{{.Name}}</a> {{len .Nodes}}{{if .Description}} <small>{{.Description}}</small>{{end}}</li>{{end}}
+</ul>
+{{template "footer" .}}{{end}}
+
+{{define "label"}}{{template "header" .}}
+{{if .Label.Description}}<p>{{.Label.Description}}</p>
+{{end}}<ul class="tasks">{{range .Label.Nodes}}
+<li class="{{.Kind}}{{if eq .State "closed"}} closed{{end}}"><a href="{{$.Base}}{{nodeHref .}}">{{cleanTitle .Title}}</a> <small>{{.Path}}:{{.Line}}</small></li>{{end}}
+</ul>
+{{template "footer" .}}{{end}}
+`))
+
+const siteStyle = `body { font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, sans-serif; margin: 0; color: #212529; }
+header { display: flex; gap: 1em; align-items: center; padding: .6em 1.5em; border-bottom: 1px solid #dee2e6; background: #f8f9fa; }
+header nav a { margin-right: .8em; }
+.brand { font-weight: 600; color: inherit; text-decoration: none; }
+#search { margin-left: auto; padding: .3em .6em; min-width: 16em; }
+#results { position: absolute; right: 1.5em; margin: 0; padding: .5em 1em; list-style: none; background: #fff; border: 1px solid #dee2e6; max-height: 60vh; overflow: auto; }
+main { padding: 1em 1.5em; max-width: 60em; }
+a { color: #1c7ed6; }
+ul.tree, ul.tree ul { list-style: none; padding-left: 1.2em; }
+ul.tree > li { margin-bottom: .4em; }
+ul.tasks { list-style: none; padding-left: 0; }
+li.heading { margin-top: .8em; }
+li.closed, li.closed a { color: #868e96; text-decoration: line-through; }
+li.more { color: #868e96; }
+.label { font-size: .85em; color: #495057; background: #e9ecef; border-radius: 3px; padding: 0 .3em; text-decoration: none; }
+.tag { font-size: .8em; color: #1971c2; }
+table { border-collapse: collapse; }
+td, th { padding: .3em .8em; border-bottom: 1px solid #e9ecef; text-align: left; vertical-align: top; }
+:target { background: #fff3bf; }
+`
+
+const siteScript = `(function () {
+  var base = document.body.dataset.base || "";
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var entries = window.TG_SEARCH || [];
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    if (terms.length === 0) {
+      results.hidden = true;
+      return;
+    }
+    var shown = 0;
+    for (var i = 0; i < entries.length && shown < 50; i++) {
+      var e = entries[i];
+      var haystack = (e.t + " " + e.c).toLowerCase();
+      if (!terms.every(function (t) { return haystack.indexOf(t) >= 0; })) {
+        continue;
+      }
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = base + e.u;
+      a.textContent = e.t;
+      if (e.x) {
+        li.className = "closed";
+      }
+      li.appendChild(a);
+      var small = document.createElement("small");
+      small.textContent = " " + e.c;
+      li.appendChild(small);
+      results.appendChild(li);
+      shown++;
+    }
+    results.hidden = shown === 0;
+  });
+  document.querySelectorAll("button[data-toggle]").forEach(function (button) {
+    button.addEventListener("click", function () {
+      var open = button.dataset.toggle === "open";
+      document.querySelectorAll("details").forEach(function (d) { d.open = open; });
+    });
+  });
+})();
+`
+
+// writeHTMLSite renders the static site for nodes into dir and returns the
+// number of HTML pages written. Registered labels get their description and
+// colour.
+func writeHTMLSite(dir string, nodes []indexer.Node, registry project.LabelRegistry) (int, error) {
+	pages := 0
+	writePage := func(rel, name string, page sitePage) error {
+		page.Base = strings.Repeat("../", strings.Count(rel, "/"))
+		var b strings.Builder
+		if err := siteTemplates.ExecuteTemplate(&b, name, page); err != nil {
+			return fmt.Errorf("render %s: %w", rel, err)
+		}
+		pages++
+		return writeSiteFile(dir, rel, b.String())
+	}
+
+	roots := nestGraphEntries(buildGraphEntries(nodes, len(nodes)+1, len(nodes)+1, true))
+	if err := writePage("index.html", "index", sitePage{Title: "Task graph", Roots: roots}); err != nil {
+		return pages, err
+	}
+
+	files := buildSiteFiles(nodes)
+	if err := writePage("files/index.html", "files", sitePage{Title: "Files", Files: files}); err != nil {
+		return pages, err
+	}
+	for _, file := range files {
+		if err := writePage(file.Href, "file", sitePage{Title: file.Title, File: file}); err != nil {
+			return pages, err
+		}
+	}
+
+	labels := buildSiteLabels(nodes, registry)
+	if err := writePage("labels/index.html", "labelindex", sitePage{Title: "Labels", Labels: labels}); err != nil {
+		return pages, err
+	}
+	for _, label := range labels {
+		if err := writePage(label.Href, "label", sitePage{Title: "#" + label.Name, Label: label}); err != nil {
+			return pages, err
+		}
+	}
+
+	search := make([]siteSearchEntry, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Kind == "file" {
+			continue
+		}
+		search = append(search, siteSearchEntry{
+			Title:   cleanGraphTitle(node.Title),
+			Context: node.Context,
+			Href:    siteNodeHref(node),
+			Closed:  node.State == "closed",
+		})
+	}
+	data, err := json.Marshal(search)
+	if err != nil {
+		return pages, err
+	}
+	// Search data ships as a script rather than JSON so it loads from file://
+	// URLs, where browsers block fetch.
+	if err := writeSiteFile(dir, "search.js", "window.TG_SEARCH = "+string(data)+";\n"); err != nil {
+		return pages, err
+	}
+	if err := writeSiteFile(dir, "style.css", siteStyle+labelColorStyle(registry)); err != nil {
+		return pages, err
+	}
+	return pages, writeSiteFile(dir, "app.js", siteScript)
+}
+
+func buildSiteFiles(nodes []indexer.Node) []siteFile {
+	byID := make(map[string]indexer.Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+	depthOf := func(node indexer.Node) int {
+		depth := 0
+		for parent, ok := byID[node.ParentID]; ok && parent.Kind != "file"; parent, ok = byID[parent.ParentID] {
+			depth++
+		}
+		return depth
+	}
+
+	var files []siteFile
+	index := map[string]int{}
+	for _, node := range nodes {
+		if node.Kind != "file" {
+			continue
+		}
+		index[node.Path] = len(files)
+		files = append(files, siteFile{
+			Path:    node.Path,
+			Title:   cleanGraphTitle(node.Title),
+			Href:    siteFileHref(node.Path),
+			Project: containsString(node.Labels, "t-project"),
+		})
+	}
+	for _, node := range nodes {
+		i, ok := index[node.Path]
+		if !ok || node.Kind == "file" {
+			continue
+		}
+		if node.Kind == "checklist" {
+			files[i].Total++
+			if node.State == "closed" {
+				files[i].Done++
+			}
+		}
+		files[i].Tasks = append(files[i].Tasks, siteTask{
+			Kind:   node.Kind,
+			Title:  node.Title,
+			Line:   node.Line,
+			Depth:  depthOf(node),
+			Closed: node.State == "closed",
+			Labels: node.Labels,
+		})
+	}
+	return files
+}
+
+func buildSiteLabels(nodes []indexer.Node, registry project.LabelRegistry) []siteLabel {
+	byLabel := map[string][]indexer.Node{}
+	for _, node := range nodes {
+		for _, label := range node.Labels {
+			byLabel[label] = append(byLabel[label], node)
+		}
+	}
+	labels := make([]siteLabel, 0, len(byLabel))
+	for name, tagged := range byLabel {
+		label := siteLabel{Name: name, Href: siteLabelHref(name), Nodes: tagged}
+		if def, ok := registry.Lookup(name); ok && def.Name == name {
+			label.Description = def.Description
+		}
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+// labelColorStyle colours the chips of registered labels. Nested labels
+// take the colour of their nearest coloured ancestor through the ^= match,
+// and longer names come later so they win over their ancestors.
+func labelColorStyle(registry project.LabelRegistry) string {
+	var b strings.Builder
+	defs := append([]project.LabelDef(nil), registry.Labels...)
+	sort.SliceStable(defs, func(i, j int) bool { return len(defs[i].Name) < len(defs[j].Name) })
+	for _, def := range defs {
+		if def.Color == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ".label[data-label=%q], .label[data-label^=%q] { border-left: .3em solid %s; }\n", def.Name, def.Name+"/", def.Color)
+	}
+	return b.String()
+}
+
+func writeSiteFile(dir, rel, content string) error {
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+func runExport(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, exportUsage)
+		return errors.New(exportUsage)
+	}
+	switch args[0] {
+	case "html":
+		return runExportHTML(args[1:], stdout, stderr)
+	case "todotxt":
+		return runExportTodoTxt(args[1:], stdout, stderr)
+	default:
+		err := fmt.Errorf("unknown export format: %s\n%s", args[0], exportUsage)
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+}
+
+func runExportHTML(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		fmt.Fprintln(stderr, exportUsage)
+		return errors.New(exportUsage)
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return err
+	}
+	nodes, err := indexer.ReadGraphNodes(filepath.Join(root, ".taskgraph", "taskgraph.db"))
+	if err != nil {
+		return err
+	}
+
+	dir := args[0]
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cwd, dir)
+	}
+	registry, err := project.ReadLabelRegistry(root)
+	if err != nil {
+		return err
+	}
+	pages, err := writeHTMLSite(dir, nodes, registry)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Exported %d pages to %s\n", pages, dir)
+	return nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportHTMLWritesStaticSite(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	if _, stderr, err := run([]string{"init"}); err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\nlabels:\n  web:\n    description: Public website\n    color: \"#4dabf7\"\n")
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	mustWrite(t, filepath.Join(dir, "docs", "roadmap.md"), strings.Join([]string{
+		"# Roadmap",
+		"",
+		"## Launch",
+		"- [ ] [tg-epic] Launch <site> #t-epic #web",
+		"  - [ ] Write copy #web",
+		"  - [x] Pick domain",
+	}, "\n")+"\n")
+
+	stdout, stderr, err := run([]string{"export", "html", "public"})
+	if err != nil {
+		t.Fatalf("export returned err: %v stderr=%q", err, stderr)
+	}
+	if !strings.Contains(stdout, "Exported") {
+		t.Fatalf("unexpected stdout: %q", stdout)
+	}
+
+	out := filepath.Join(dir, "public")
+	index := readFile(t, filepath.Join(out, "index.html"))
+	if !strings.Contains(index, `<details open><summary><a href="files/docs/roadmap.md.html#L4">[epic] [tg-epic] Launch &lt;site&gt;</a></summary>`) {
+		t.Fatalf("expected collapsible epic root in index, got %q", index)
+	}
+	if !strings.Contains(index, `<li class="checklist closed"><a href="files/docs/roadmap.md.html#L6">Pick domain</a>`) {
+		t.Fatalf("expected closed child in tree, got %q", index)
+	}
+
+	page := readFile(t, filepath.Join(out, "files", "docs", "roadmap.md.html"))
+	if !strings.Contains(page, `href="../../style.css"`) || !strings.Contains(page, "1 of 3 tasks done (33%)") {
+		t.Fatalf("unexpected file page: %q", page)
+	}
+	if !strings.Contains(page, `<a class="label" data-label="web" href="../../labels/web.html">#web</a>`) {
+		t.Fatalf("expected label link on file page, got %q", page)
+	}
+
+	labelPage := readFile(t, filepath.Join(out, "labels", "web.html"))
+	if !strings.Contains(labelPage, "Write copy") || strings.Contains(labelPage, "Pick domain") || !strings.Contains(labelPage, "<p>Public website</p>") {
+		t.Fatalf("unexpected label page: %q", labelPage)
+	}
+	if style := readFile(t, filepath.Join(out, "style.css")); !strings.Contains(style, `.label[data-label="web"], .label[data-label^="web/"] { border-left: .3em solid #4dabf7; }`) {
+		t.Fatalf("expected label colour in style.css, got %q", style)
+	}
+	if !strings.Contains(readFile(t, filepath.Join(out, "labels", "index.html")), `href="../labels/t-epic.html"`) {
+		t.Fatalf("expected type label in label index")
+	}
+
+	search := readFile(t, filepath.Join(out, "search.js"))
+	if !strings.HasPrefix(search, "window.TG_SEARCH = [") || !strings.Contains(search, `"u":"files/docs/roadmap.md.html#L5"`) {
+		t.Fatalf("unexpected search data: %q", search)
+	}
+}
+
+func TestExportRequiresFormatAndDir(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	for _, args := range [][]string{{"export"}, {"export", "html"}, {"export", "pdf", "out"}} {
+		_, stderr, err := run(args)
+		if err == nil || !strings.Contains(stderr, exportUsage) {
+			t.Fatalf("expected usage error for %v, got err=%v stderr=%q", args, err, stderr)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+	"taskgraph/internal/textdiff"
+)
+
+const fmtUsage = "usage: tg fmt [paths...] [--check] [--diff] [--ids]"
+
+type fmtOptions struct {
+	paths []string
+	check bool
+	diff  bool
+	ids   bool
+}
+
+func parseFmtArgs(args []string) (fmtOptions, error) {
+	var opts fmtOptions
+	for _, arg := range args {
+		switch {
+		case arg == "--check":
+			opts.check = true
+		case arg == "--diff":
+			opts.diff = true
+		case arg == "--ids":
+			opts.ids = true
+		case strings.HasPrefix(arg, "-"):
+			return fmtOptions{}, errors.New(fmtUsage)
+		default:
+			opts.paths = append(opts.paths, arg)
+		}
+	}
+	return opts, nil
+}
+
+// runFmt rewrites checklist lines in markdown files into tg's canonical
+// layout. Without paths it covers every markdown file the index covers.
+// --check and --diff report instead of writing; --check fails when any file
+// would change, for CI.
+func runFmt(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts, err := parseFmtArgs(args)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	indexed, err := indexedMarkdownFiles(root)
+	if err != nil {
+		return err
+	}
+	files := indexed
+	if len(opts.paths) > 0 {
+		if files, err = markdownFilesIn(cwd, opts.paths); err != nil {
+			return err
+		}
+	}
+
+	var newID func(tasks.Task) string
+	if opts.ids {
+		if newID, err = taskIDGenerator(root, indexed); err != nil {
+			return err
+		}
+	}
+
+	changed := 0
+	for _, absPath := range files {
+		b, err := os.ReadFile(absPath)
+		if err != nil {
+			return err
+		}
+		before := string(b)
+		after := tasks.FormatMarkdown(before, newID)
+		if after == before {
+			continue
+		}
+		changed++
+		rel := absPath
+		if r, err := filepath.Rel(root, absPath); err == nil && !strings.HasPrefix(r, "..") {
+			rel = filepath.ToSlash(r)
+		}
+		switch {
+		case opts.diff:
+			fmt.Fprint(stdout, textdiff.Unified(rel, before, after))
+		case opts.check:
+			fmt.Fprintln(stdout, rel)
+		default:
+			if err := os.WriteFile(absPath, []byte(after), 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Formatted %s\n", rel)
+		}
+	}
+
+	if opts.check && changed > 0 {
+		return fmt.Errorf("%d file(s) need tg fmt", changed)
+	}
+	if changed > 0 && !opts.check && !opts.diff {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexedMarkdownFiles returns the markdown files the index covers.
+func indexedMarkdownFiles(root string) ([]string, error) {
+	all, err := indexer.SourceFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range all {
+		if strings.EqualFold(filepath.Ext(f), ".md") {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// markdownFilesIn resolves file and directory arguments against cwd.
+// Directories are walked for .md files, skipping hidden directories and
+// node_modules like the indexer does.
+func markdownFilesIn(cwd string, paths []string) ([]string, error) {
+	seen := map[string]bool{}
+	var files []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			files = append(files, p)
+		}
+	}
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(cwd, p)
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			add(p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != p && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.EqualFold(filepath.Ext(path), ".md") {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// taskIDGenerator returns a function handing out fresh IDs that collide
+// neither with IDs in files nor with each other.
+func taskIDGenerator(root string, files []string) (func(tasks.Task) string, error) {
+	prefix, err := project.ReadPrefix(root)
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if task, ok := tasks.ParseTask(line); ok && task.ID != "" {
+				existing[task.ID] = true
+			}
+		}
+	}
+	now := time.Now()
+	return func(task tasks.Task) string {
+		id := tasks.NewTaskID(prefix, task.Text, now, existing)
+		existing[id] = true
+		return id
+	}, nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFmtCheckDiffAndWrite(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-abc] Tidy\n")
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	plan := filepath.Join(dir, "docs", "plan.md")
+	original := "# Plan #notalabel\n\n- [X] #launch Ship beta\n- [ ] Write docs\n"
+	mustWrite(t, plan, original)
+
+	stdout, _, err := run([]string{"fmt", "--check"})
+	if err == nil || stdout != "docs/plan.md\n" {
+		t.Fatalf("expected --check to fail listing docs/plan.md, err=%v output=%q", err, stdout)
+	}
+
+	stdout, _, err = run([]string{"fmt", "docs", "--diff"})
+	if err != nil {
+		t.Fatalf("fmt --diff returned err: %v", err)
+	}
+	if !strings.Contains(stdout, "-- [X] #launch Ship beta") || !strings.Contains(stdout, "+- [x] Ship beta #launch") {
+		t.Fatalf("unexpected diff:\n%s", stdout)
+	}
+	if b, _ := os.ReadFile(plan); string(b) != original {
+		t.Fatalf("--diff must not write, got:\n%s", b)
+	}
+
+	stdout, stderr, err := run([]string{"fmt", "--ids"})
+	if err != nil {
+		t.Fatalf("fmt returned err: %v stderr=%q", err, stderr)
+	}
+	if stdout != "Formatted docs/plan.md\n" {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+	b, err := os.ReadFile(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if lines[0] != "# Plan #notalabel" || !strings.HasPrefix(lines[2], "- [x] [tg-") || !strings.HasSuffix(lines[2], "] Ship beta #launch") || !strings.HasPrefix(lines[3], "- [ ] [tg-") {
+		t.Fatalf("unexpected formatted file:\n%s", b)
+	}
+
+	if _, _, err := run([]string{"fmt", "--check"}); err != nil {
+		t.Fatalf("expected formatted tree to pass --check, got %v", err)
+	}
+}
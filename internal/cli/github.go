@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"taskgraph/internal/migrate"
+	"taskgraph/internal/textdiff"
+)
+
+func runImportGitHubIssues(args []string, stdout io.Writer, stderr io.Writer) error {
+	root, input, dryRun, err := parseImportFileArgs(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	var summary migrate.GitHubSummary
+	if dryRun {
+		var changes []migrate.FileChange
+		changes, summary, err = migrate.PlanGitHubIssuesImport(root, input)
+		if err == nil {
+			for _, change := range changes {
+				fmt.Fprint(stdout, textdiff.Unified(change.Path, change.Before, change.After))
+			}
+		}
+	} else {
+		summary, err = migrate.ImportGitHubIssues(root, input)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return err
+	}
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(
+		stdout,
+		"%s %d issues, %d updated, %d unchanged (%d invalid skipped)\n",
+		verb,
+		summary.Imported,
+		summary.Updated,
+		summary.Unchanged,
+		summary.SkippedInvalid,
+	)
+	fmt.Fprintf(stdout, "Mapped %d labeled, %d milestones, %d assigned\n", summary.Labeled, summary.Milestones, summary.Assigned)
+	if !dryRun && summary.Imported+summary.Updated > 0 {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportGitHubIssuesCommand(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	if _, stderr, err := run([]string{"init"}); err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+	mustWrite(t, filepath.Join(dir, "issues.json"),
+		`[{"number":7,"title":"Slow search","state":"OPEN","labels":[{"name":"perf"}],"milestone":{"title":"Q4"}}]`)
+
+	stdout, stderr, err := run([]string{"import", "github-issues", "issues.json"})
+	if err != nil || !strings.Contains(stdout, "Imported 1 issues, 0 updated") || !strings.Contains(stdout, "1 milestones") {
+		t.Fatalf("unexpected import: err=%v stdout=%q stderr=%q", err, stdout, stderr)
+	}
+	stdout, _, err = run([]string{"list", "--label", "milestone-q4"})
+	if err != nil || !strings.Contains(stdout, "[gh:7] Slow search") {
+		t.Fatalf("expected milestone label in index, got err=%v stdout=%q", err, stdout)
+	}
+
+	stdout, _, err = run([]string{"import", "github-issues", "issues.json", "--dry-run"})
+	if err != nil || !strings.Contains(stdout, "Would import 0 issues, 0 updated, 1 unchanged") {
+		t.Fatalf("expected re-import to be a no-op, got err=%v stdout=%q", err, stdout)
+	}
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"taskgraph/internal/tasks"
+)
+
+// graphEdge connects two rendered graph entries. Kind is "contains" for the
+// markdown hierarchy and "blocks" from a task to one whose ⛔ marker names
+// it; other kinds are drawn with their own edge style.
+type graphEdge struct {
+	From string
+	To   string
+	Kind string
+}
+
+var graphTypeColors = map[string]string{
+	"idea":       "#fff3bf",
+	"initiative": "#e5dbff",
+	"project":    "#d0ebff",
+	"product":    "#d3f9d8",
+	"epic":       "#ffe3e3",
+}
+
+// graphExportIDs assigns short, stable identifiers to entries in display order
+// so DOT and Mermaid output stays readable and diffable.
+func graphExportIDs(entries []graphEntry) (map[string]string, []string) {
+	byNodeID := make(map[string]string, len(entries))
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = fmt.Sprintf("n%d", i+1)
+		if entry.Hidden == 0 {
+			byNodeID[entry.Node.ID] = ids[i]
+		}
+	}
+	return byNodeID, ids
+}
+
+// graphTaskRefPattern matches the bracketed references a task line carries,
+// such as [tg-abc] or [beads:pl-1], which ⛔ markers point at.
+var graphTaskRefPattern = regexp.MustCompile(`\[([A-Za-z0-9_:.-]+)\]`)
+
+func graphEdges(entries []graphEntry, byNodeID map[string]string, ids []string) []graphEdge {
+	var out []graphEdge
+	byRef := map[string]string{}
+	for i, entry := range entries {
+		if entry.Hidden > 0 || entry.Node.Kind != "checklist" {
+			continue
+		}
+		for _, m := range graphTaskRefPattern.FindAllStringSubmatch(entry.Node.Title, -1) {
+			if _, seen := byRef[m[1]]; !seen {
+				byRef[m[1]] = ids[i]
+			}
+		}
+	}
+	for i, entry := range entries {
+		if from, ok := byNodeID[entry.ParentID]; ok {
+			out = append(out, graphEdge{From: from, To: ids[i], Kind: "contains"})
+		}
+	}
+	// Dependencies only link tasks that are both on screen.
+	for i, entry := range entries {
+		if entry.Hidden > 0 || entry.Node.Kind != "checklist" {
+			continue
+		}
+		for _, ref := range tasks.ExtractDependsOn(entry.Node.Title) {
+			if from, ok := byRef[ref]; ok && from != ids[i] {
+				out = append(out, graphEdge{From: from, To: ids[i], Kind: "blocks"})
+			}
+		}
+	}
+	return out
+}
+
+func graphNodeType(entry graphEntry) string {
+	taskType, err := tasks.ExtractTaskTypeFromLabels(entry.Node.Labels)
+	if err != nil {
+		return ""
+	}
+	return taskType
+}
+
+func renderGraphDOT(stdout io.Writer, entries []graphEntry) {
+	byNodeID, ids := graphExportIDs(entries)
+
+	fmt.Fprintln(stdout, "digraph taskgraph {")
+	fmt.Fprintln(stdout, "  rankdir=LR;")
+	fmt.Fprintln(stdout, `  node [shape=box, style="rounded", fontname="Helvetica"];`)
+	for i, entry := range entries {
+		if entry.Hidden > 0 {
+			fmt.Fprintf(stdout, "  %s [label=%s, shape=plaintext, fontcolor=\"gray40\"];\n", ids[i], dotQuote(fmt.Sprintf("... %d more", entry.Hidden)))
+			continue
+		}
+
+		attrs := []string{"label=" + dotQuote(formatGraphNode(entry.Node))}
+		styles := []string{"rounded"}
+		switch entry.Node.Kind {
+		case "file":
+			attrs = append(attrs, "shape=folder")
+			styles = nil
+		case "heading":
+			styles = append(styles, "bold")
+		}
+		if color, ok := graphTypeColors[graphNodeType(entry)]; ok {
+			styles = append(styles, "filled")
+			attrs = append(attrs, "fillcolor="+dotQuote(color))
+		}
+		if entry.Node.State == "closed" {
+			styles = append(styles, "dashed")
+			attrs = append(attrs, `fontcolor="gray40"`)
+		}
+		if len(styles) > 0 {
+			attrs = append(attrs, "style="+dotQuote(strings.Join(styles, ",")))
+		}
+		fmt.Fprintf(stdout, "  %s [%s];\n", ids[i], strings.Join(attrs, ", "))
+	}
+	for _, edge := range graphEdges(entries, byNodeID, ids) {
+		if edge.Kind == "contains" {
+			fmt.Fprintf(stdout, "  %s -> %s;\n", edge.From, edge.To)
+			continue
+		}
+		fmt.Fprintf(stdout, "  %s -> %s [style=dashed, label=%s];\n", edge.From, edge.To, dotQuote(edge.Kind))
+	}
+	fmt.Fprintln(stdout, "}")
+}
+
+func renderGraphMermaid(stdout io.Writer, entries []graphEntry) {
+	byNodeID, ids := graphExportIDs(entries)
+
+	fmt.Fprintln(stdout, "graph TD")
+	classes := map[string][]string{}
+	for i, entry := range entries {
+		if entry.Hidden > 0 {
+			fmt.Fprintf(stdout, "  %s[%s]\n", ids[i], mermaidQuote(fmt.Sprintf("... %d more", entry.Hidden)))
+			classes["omitted"] = append(classes["omitted"], ids[i])
+			continue
+		}
+
+		label := mermaidQuote(formatGraphNode(entry.Node))
+		switch entry.Node.Kind {
+		case "file":
+			fmt.Fprintf(stdout, "  %s[[%s]]\n", ids[i], label)
+		case "heading":
+			fmt.Fprintf(stdout, "  %s(%s)\n", ids[i], label)
+		default:
+			fmt.Fprintf(stdout, "  %s[%s]\n", ids[i], label)
+		}
+		if taskType := graphNodeType(entry); graphTypeColors[taskType] != "" {
+			classes[taskType] = append(classes[taskType], ids[i])
+		}
+		if entry.Node.State == "closed" {
+			classes["closed"] = append(classes["closed"], ids[i])
+		}
+	}
+	for _, edge := range graphEdges(entries, byNodeID, ids) {
+		if edge.Kind == "contains" {
+			fmt.Fprintf(stdout, "  %s --> %s\n", edge.From, edge.To)
+			continue
+		}
+		fmt.Fprintf(stdout, "  %s -.->|%s| %s\n", edge.From, edge.Kind, edge.To)
+	}
+
+	for _, name := range []string{"idea", "initiative", "project", "product", "epic", "closed", "omitted"} {
+		members := classes[name]
+		if len(members) == 0 {
+			continue
+		}
+		switch name {
+		case "closed":
+			fmt.Fprintln(stdout, "  classDef closed stroke-dasharray: 4 4,color:#868e96")
+		case "omitted":
+			fmt.Fprintln(stdout, "  classDef omitted fill:none,stroke:none,color:#868e96")
+		default:
+			fmt.Fprintf(stdout, "  classDef %s fill:%s\n", name, graphTypeColors[name])
+		}
+		fmt.Fprintf(stdout, "  class %s %s\n", strings.Join(members, ","), name)
+	}
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return `"` + s + `"`
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+	"taskgraph/internal/textdiff"
+)
+
+const labelsUsage = "usage: tg labels | tg labels rename <old> <new> [--dry-run] | tg labels merge <from> <into> [--dry-run]"
+
+// runLabels lists indexed labels with how many nodes carry each, or renames
+// and merges labels across every indexed markdown file.
+func runLabels(args []string, stdout io.Writer, stderr io.Writer) error {
+	var positional []string
+	dryRun := false
+	for _, arg := range args {
+		switch {
+		case arg == "--dry-run":
+			dryRun = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintln(stderr, labelsUsage)
+			return errors.New(labelsUsage)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	valid := len(positional) == 0 && !dryRun ||
+		len(positional) == 3 && (positional[0] == "rename" || positional[0] == "merge")
+	if !valid {
+		fmt.Fprintln(stderr, labelsUsage)
+		return errors.New(labelsUsage)
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	dbPath := filepath.Join(root, ".taskgraph", "taskgraph.db")
+	counts, err := indexer.ReadLabelCounts(dbPath)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		reg, err := project.ReadLabelRegistry(root)
+		if err != nil {
+			return err
+		}
+		printLabels(stdout, counts, reg)
+		return nil
+	}
+
+	if err := renameLabel(root, positional[0], positional[1], positional[2], counts, dryRun, stdout); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+	return nil
+}
+
+// printLabels lists labels in use, most used first, followed by registered
+// labels nothing uses yet. Registered labels show their description; when a
+// registry exists, labels missing from it are flagged.
+func printLabels(stdout io.Writer, counts []indexer.LabelCount, reg project.LabelRegistry) {
+	inUse := map[string]bool{}
+	for _, lc := range counts {
+		inUse[lc.Label] = true
+	}
+	for _, def := range reg.Labels {
+		if !inUse[def.Name] {
+			counts = append(counts, indexer.LabelCount{Label: def.Name})
+		}
+	}
+	if len(counts) == 0 {
+		fmt.Fprintln(stdout, "No labels.")
+		return
+	}
+
+	width, countWidth := 0, 0
+	for _, lc := range counts {
+		width = max(width, len(lc.Label)+1)
+		countWidth = max(countWidth, len(fmt.Sprint(lc.Count)))
+	}
+	for _, lc := range counts {
+		line := fmt.Sprintf("%-*s  %*d", width, "#"+lc.Label, countWidth, lc.Count)
+		def, ok := reg.Lookup(lc.Label)
+		if ok && def.Name == lc.Label && def.Description != "" {
+			line += "  " + def.Description
+		} else if !ok && len(reg.Labels) > 0 && !strings.HasPrefix(lc.Label, "t-") {
+			line += "  (not registered)"
+		}
+		fmt.Fprintln(stdout, line)
+	}
+}
+
+// renameLabel rewrites #from, and the labels nested below it, to #to in every
+// indexed markdown file. rename refuses a target that is already in use, so
+// two labels are only folded together on purpose, with merge.
+func renameLabel(root, mode, from, to string, counts []indexer.LabelCount, dryRun bool, stdout io.Writer) error {
+	labels := tasks.NormalizeLabelsCSV(from + "," + to)
+	if len(labels) != 2 || strings.Contains(from, ",") || strings.Contains(to, ",") {
+		return fmt.Errorf("%s needs two different labels", mode)
+	}
+	from, to = labels[0], labels[1]
+	for _, label := range labels {
+		if strings.HasPrefix(label, "t-") || label == "type" {
+			return fmt.Errorf("#%s is a type label; change task types with tg edit <id> --type name", label)
+		}
+	}
+	if tasks.LabelMatches(to, from) {
+		return fmt.Errorf("cannot %s #%s into its own sub-label #%s", mode, from, to)
+	}
+	// Labels nested below from move with it, so both checks cover the whole
+	// subtree of each label.
+	fromUsed, toUsed := false, false
+	for _, lc := range counts {
+		fromUsed = fromUsed || tasks.LabelMatches(lc.Label, from)
+		toUsed = toUsed || tasks.LabelMatches(lc.Label, to)
+	}
+	if !fromUsed {
+		return fmt.Errorf("no indexed task uses #%s", from)
+	}
+	if mode == "rename" && toUsed {
+		return fmt.Errorf("#%s is already in use; run tg labels merge %s %s to fold #%s into it", to, from, to, from)
+	}
+	if err := checkRegisteredLabels(root, []string{to}); err != nil {
+		return err
+	}
+
+	files, err := indexedMarkdownFiles(root)
+	if err != nil {
+		return err
+	}
+	total, changed := 0, 0
+	for _, absPath := range files {
+		b, err := os.ReadFile(absPath)
+		if err != nil {
+			return err
+		}
+		before := string(b)
+		after, n := tasks.RenameLabel(before, from, to)
+		if n == 0 {
+			continue
+		}
+		total += n
+		changed++
+		rel := absPath
+		if r, err := filepath.Rel(root, absPath); err == nil {
+			rel = filepath.ToSlash(r)
+		}
+		if dryRun {
+			fmt.Fprint(stdout, textdiff.Unified(rel, before, after))
+			continue
+		}
+		if err := os.WriteFile(absPath, []byte(after), 0o644); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		return nil
+	}
+	if changed > 0 {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return err
+		}
+	}
+	action := "Renamed #" + from + " to #" + to
+	if mode == "merge" {
+		action = "Merged #" + from + " into #" + to
+	}
+	fmt.Fprintf(stdout, "%s: %d occurrence(s) in %d file(s)\n", action, total, changed)
+	return nil
+}
+
+// checkRegisteredLabels rejects labels missing from a strict label registry,
+// suggesting the closest registered one.
+func checkRegisteredLabels(root string, labels []string) error {
+	reg, err := project.ReadLabelRegistry(root)
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if reg.Allows(label) {
+			continue
+		}
+		if near := reg.Closest(label); near != "" {
+			return fmt.Errorf("unregistered label: %s (did you mean %s?)", label, near)
+		}
+		return fmt.Errorf("unregistered label: %s (add it under labels: in .taskgraph/config.yml)", label)
+	}
+	return nil
+}
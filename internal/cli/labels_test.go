@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLabelsListRenameAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-aaa] Plan stall #flowershwo\n")
+	plan := filepath.Join(dir, "plan.md")
+	mustWrite(t, plan, "- [ ] [tg-bbb] Book venue #flowershow #home\n- [ ] [tg-ccc] Sweep #household #home\n")
+	if _, _, err := run([]string{"index"}); err != nil {
+		t.Fatalf("index returned err: %v", err)
+	}
+
+	stdout, stderr, err := run([]string{"labels"})
+	if err != nil {
+		t.Fatalf("labels returned err: %v stderr=%q", err, stderr)
+	}
+	want := "#home        2\n#flowershow  1\n#flowershwo  1\n#household   1\n#t-project   1\n"
+	if stdout != want {
+		t.Fatalf("unexpected labels output %q", stdout)
+	}
+
+	_, stderr, err = run([]string{"labels", "rename", "flowershwo", "flowershow"})
+	if err == nil || !strings.Contains(stderr, "tg labels merge flowershwo flowershow") {
+		t.Fatalf("expected rename onto a used label to fail, got err=%v stderr=%q", err, stderr)
+	}
+	if _, _, err := run([]string{"labels", "rename", "t-task", "chore"}); err == nil {
+		t.Fatalf("expected type labels to be refused")
+	}
+
+	stdout, _, err = run([]string{"labels", "merge", "flowershwo", "flowershow", "--dry-run"})
+	if err != nil || !strings.Contains(stdout, "+- [ ] [tg-aaa] Plan stall #flowershow") {
+		t.Fatalf("unexpected dry run, err=%v output:\n%s", err, stdout)
+	}
+	stdout, _, err = run([]string{"labels", "merge", "flowershwo", "flowershow"})
+	if err != nil || stdout != "Merged #flowershwo into #flowershow: 1 occurrence(s) in 1 file(s)\n" {
+		t.Fatalf("unexpected merge, err=%v output %q", err, stdout)
+	}
+
+	stdout, _, err = run([]string{"labels", "merge", "home", "household"})
+	if err != nil || stdout != "Merged #home into #household: 2 occurrence(s) in 1 file(s)\n" {
+		t.Fatalf("unexpected merge, err=%v output %q", err, stdout)
+	}
+	b, _ := os.ReadFile(plan)
+	if string(b) != "- [ ] [tg-bbb] Book venue #flowershow #household\n- [ ] [tg-ccc] Sweep #household\n" {
+		t.Fatalf("unexpected plan.md:\n%s", b)
+	}
+
+	stdout, _, _ = run([]string{"labels"})
+	if stdout != "#flowershow  2\n#household   2\n#t-project   1\n" {
+		t.Fatalf("expected the index to be refreshed, got %q", stdout)
+	}
+}
+
+func TestLabelsRenameMovesNestedLabels(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	plan := filepath.Join(dir, "plan.md")
+	mustWrite(t, plan, "- [ ] [tg-aaa] Fix nav #area/frontend\n- [ ] [tg-bbb] Fix API #area/backend\n")
+	if _, _, err := run([]string{"index"}); err != nil {
+		t.Fatalf("index returned err: %v", err)
+	}
+
+	if _, stderr, err := run([]string{"labels", "rename", "area", "area/old"}); err == nil || !strings.Contains(stderr, "own sub-label") {
+		t.Fatalf("expected rename into a sub-label to fail, got err=%v stderr=%q", err, stderr)
+	}
+	stdout, stderr, err := run([]string{"labels", "rename", "area", "zone"})
+	if err != nil || stdout != "Renamed #area to #zone: 2 occurrence(s) in 1 file(s)\n" {
+		t.Fatalf("unexpected rename, err=%v stdout=%q stderr=%q", err, stdout, stderr)
+	}
+	if got := readFile(t, plan); got != "- [ ] [tg-aaa] Fix nav #zone/frontend\n- [ ] [tg-bbb] Fix API #zone/backend\n" {
+		t.Fatalf("unexpected plan.md:\n%s", got)
+	}
+	if _, stderr, err := run([]string{"labels", "rename", "area/frontend", "zone"}); err == nil || !strings.Contains(stderr, "no indexed task uses #area/frontend") {
+		t.Fatalf("expected renamed label to be gone, got err=%v stderr=%q", err, stderr)
+	}
+}
+
+func TestLabelRegistryStrictMode(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), strings.Join([]string{
+		"issue-prefix: tg",
+		"labels-strict: true",
+		"labels:",
+		"  flowershow: Flower show planning",
+		"  area:",
+		"  someday:",
+	}, "\n")+"\n")
+
+	_, stderr, err := run([]string{"add", "book stall", "--labels", "flowershwo"})
+	if err == nil || !strings.Contains(stderr, "unregistered label: flowershwo (did you mean flowershow?)") {
+		t.Fatalf("expected unregistered label error, got err=%v stderr=%q", err, stderr)
+	}
+	_, stderr, err = run([]string{"add", "sort receipts #taxes"})
+	if err == nil || !strings.Contains(stderr, "unregistered label: taxes (add it under labels:") {
+		t.Fatalf("expected inline labels to be checked, got err=%v stderr=%q", err, stderr)
+	}
+	if _, stderr, err := run([]string{"add", "book stall", "--labels", "flowershow,area/frontend", "--type", "task"}); err != nil {
+		t.Fatalf("add returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, _, err := run([]string{"labels"})
+	if err != nil {
+		t.Fatalf("labels returned err: %v", err)
+	}
+	want := "#area/frontend  1\n#flowershow     1  Flower show planning\n#t-task         1\n#area           0\n#someday        0\n"
+	if stdout != want {
+		t.Fatalf("unexpected labels output %q", stdout)
+	}
+
+	inbox := readFile(t, filepath.Join(dir, ".taskgraph", "issues.md"))
+	start := strings.Index(inbox, "[tg-")
+	id := inbox[start+1 : start+strings.Index(inbox[start:], "]")]
+	_, stderr, err = run([]string{"edit", id, "--add-label", "somday"})
+	if err == nil || !strings.Contains(stderr, "did you mean someday?") {
+		t.Fatalf("expected edit to check labels, got err=%v stderr=%q", err, stderr)
+	}
+}
@@ -0,0 +1,680 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+)
+
+// mcpProtocolVersions lists the Model Context Protocol revisions tg mcp
+// speaks, newest first. The first entry is offered when a client asks for an
+// unknown revision.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+const (
+	jsonRPCParseError     = -32700
+	jsonRPCInvalidRequest = -32600
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+	jsonRPCInternalError  = -32603
+)
+
+const mcpResourcePrefix = "taskgraph://files/"
+
+type jsonRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *jsonRPCError   `json:"error,omitempty"`
+}
+
+type mcpTool struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	InputSchema  map[string]any `json:"inputSchema"`
+	OutputSchema map[string]any `json:"outputSchema,omitempty"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content           []mcpContent `json:"content"`
+	StructuredContent any          `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+type mcpResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType"`
+}
+
+// mcpServer answers Model Context Protocol requests against one project. It
+// shares index bookkeeping with tg serve so edits made outside the agent are
+// picked up before every call.
+type mcpServer struct {
+	api *apiServer
+}
+
+func newMCPServer(root string) *mcpServer {
+	return &mcpServer{api: newAPIServer(root)}
+}
+
+// serve reads newline-delimited JSON-RPC messages until stdin closes.
+func (m *mcpServer) serve(stdin io.Reader, stdout io.Writer) error {
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if reply := m.handle([]byte(line)); reply != nil {
+			if _, err := fmt.Fprintf(stdout, "%s\n", reply); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle processes one JSON-RPC message and returns the encoded response, or
+// nil for notifications.
+func (m *mcpServer) handle(msg []byte) []byte {
+	var req jsonRPCRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return encodeRPC(jsonRPCResponse{ID: json.RawMessage("null"), Error: &jsonRPCError{Code: jsonRPCParseError, Message: "parse error: " + err.Error()}})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if len(req.ID) == 0 {
+			return nil
+		}
+		return encodeRPC(jsonRPCResponse{ID: req.ID, Error: &jsonRPCError{Code: jsonRPCInvalidRequest, Message: "invalid request"}})
+	}
+
+	result, rpcErr := m.dispatch(req)
+	if len(req.ID) == 0 {
+		return nil
+	}
+	return encodeRPC(jsonRPCResponse{ID: req.ID, Result: result, Error: rpcErr})
+}
+
+func encodeRPC(resp jsonRPCResponse) []byte {
+	resp.JSONRPC = "2.0"
+	if resp.Result == nil && resp.Error == nil {
+		resp.Result = struct{}{}
+	}
+	out, err := json.Marshal(resp)
+	if err != nil {
+		out, _ = json.Marshal(jsonRPCResponse{JSONRPC: "2.0", ID: resp.ID, Error: &jsonRPCError{Code: jsonRPCInternalError, Message: err.Error()}})
+	}
+	return out
+}
+
+func (m *mcpServer) dispatch(req jsonRPCRequest) (any, *jsonRPCError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := mcpProtocolVersions[0]
+		if containsString(mcpProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities": map[string]any{
+				"tools":     map[string]any{},
+				"resources": map[string]any{},
+			},
+			"serverInfo": map[string]string{"name": "taskgraph", "version": "0.1.0"},
+		}, nil
+	case "notifications/initialized", "notifications/cancelled", "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return map[string]any{"tools": mcpTools()}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "tools/call requires a tool name"}
+		}
+		if len(params.Arguments) == 0 || string(params.Arguments) == "null" {
+			params.Arguments = json.RawMessage("{}")
+		}
+		return m.callTool(params.Name, params.Arguments)
+	case "resources/list":
+		resources, err := m.listResources()
+		if err != nil {
+			return nil, &jsonRPCError{Code: jsonRPCInternalError, Message: err.Error()}
+		}
+		return map[string]any{"resources": resources}, nil
+	case "resources/read":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+			return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "resources/read requires a uri"}
+		}
+		return m.readResource(params.URI)
+	default:
+		return nil, &jsonRPCError{Code: jsonRPCMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+func (m *mcpServer) callTool(name string, args json.RawMessage) (any, *jsonRPCError) {
+	if _, err := m.api.refreshIfChanged(); err != nil {
+		return toolError(err), nil
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch name {
+	case "add":
+		result, err = m.toolAdd(args)
+	case "close":
+		result, err = m.toolClose(args)
+	case "list":
+		result, err = m.toolList(args)
+	case "graph":
+		result, err = m.toolGraph(args)
+	case "show":
+		result, err = m.toolShow(args)
+	case "search":
+		result, err = m.toolSearch(args)
+	case "next":
+		result, err = m.toolNext(args)
+	default:
+		return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "unknown tool: " + name}
+	}
+	if err != nil {
+		return toolError(err), nil
+	}
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return toolError(err), nil
+	}
+	return mcpToolResult{
+		Content:           []mcpContent{{Type: "text", Text: string(text)}},
+		StructuredContent: result,
+	}, nil
+}
+
+func toolError(err error) mcpToolResult {
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}
+}
+
+func decodeToolArgs(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(strings.NewReader(string(args)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func (m *mcpServer) toolAdd(args json.RawMessage) (any, error) {
+	var req addTaskRequest
+	if err := decodeToolArgs(args, &req); err != nil {
+		return nil, err
+	}
+	opts, err := addOptionsFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	m.api.mu.Lock()
+	defer m.api.mu.Unlock()
+	id, err := addTask(m.api.root, opts)
+	if err != nil {
+		return nil, err
+	}
+	m.api.markIndexed()
+	nodes, err := indexer.ReadGraphNodes(m.api.dbPath)
+	if err != nil {
+		return nil, err
+	}
+	node, _ := findNodeRef(nodes, id)
+	return map[string]any{"id": id, "node": node}, nil
+}
+
+func (m *mcpServer) toolClose(args json.RawMessage) (any, error) {
+	var req struct {
+		ID     string `json:"id"`
+		Reason string `json:"reason"`
+	}
+	if err := decodeToolArgs(args, &req); err != nil {
+		return nil, err
+	}
+	id := strings.TrimSpace(req.ID)
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
+
+	m.api.mu.Lock()
+	defer m.api.mu.Unlock()
+	if err := closeTask(m.api.root, id, req.Reason); err != nil {
+		return nil, err
+	}
+	m.api.markIndexed()
+	return map[string]string{"id": id, "state": "closed"}, nil
+}
+
+func (m *mcpServer) toolList(args json.RawMessage) (any, error) {
+	var req struct {
+		All    bool     `json:"all"`
+		Labels []string `json:"labels"`
+	}
+	if err := decodeToolArgs(args, &req); err != nil {
+		return nil, err
+	}
+	var labels []string
+	for _, raw := range req.Labels {
+		labels = append(labels, tasks.NormalizeLabelsCSV(raw)...)
+	}
+
+	m.api.mu.RLock()
+	nodes, err := indexer.ReadChecklistNodes(m.api.dbPath, req.All, tasks.MergeLabels(labels))
+	m.api.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return nodeList(nodes), nil
+}
+
+func (m *mcpServer) toolGraph(args json.RawMessage) (any, error) {
+	var req struct {
+		ID          string `json:"id"`
+		Depth       int    `json:"depth"`
+		MaxChildren int    `json:"max_children"`
+		All         bool   `json:"all"`
+	}
+	if err := decodeToolArgs(args, &req); err != nil {
+		return nil, err
+	}
+	if req.Depth < 0 || req.MaxChildren < 0 {
+		return nil, errors.New("depth and max_children must be positive")
+	}
+	if req.Depth == 0 {
+		req.Depth = 4
+	}
+	if req.MaxChildren == 0 {
+		req.MaxChildren = 5
+	}
+
+	nodes, err := m.api.readNodes()
+	if err != nil {
+		return nil, err
+	}
+	if req.ID == "" {
+		return map[string]any{"roots": nestGraphEntries(buildGraphEntries(nodes, req.Depth, req.MaxChildren, req.All))}, nil
+	}
+	node, ok := findNodeRef(nodes, req.ID)
+	if !ok {
+		return nil, fmt.Errorf("node not found: %s", req.ID)
+	}
+	tree, _ := buildGraphTree(nodes, node.ID, req.Depth, req.All)
+	return tree, nil
+}
+
+func (m *mcpServer) toolShow(args json.RawMessage) (any, error) {
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := decodeToolArgs(args, &req); err != nil {
+		return nil, err
+	}
+	nodes, err := m.api.readNodes()
+	if err != nil {
+		return nil, err
+	}
+	node, ok := findNodeRef(nodes, req.ID)
+	if !ok {
+		return nil, fmt.Errorf("node not found: %s", req.ID)
+	}
+	tree, _ := buildGraphTree(nodes, node.ID, 1, true)
+	return tree, nil
+}
+
+func (m *mcpServer) toolSearch(args json.RawMessage) (any, error) {
+	var req struct {
+		Query string `json:"query"`
+		All   bool   `json:"all"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeToolArgs(args, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+
+	m.api.mu.RLock()
+	nodes, err := indexer.SearchNodes(m.api.dbPath, req.Query, req.All, req.Limit)
+	m.api.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return nodeList(nodes), nil
+}
+
+func (m *mcpServer) toolNext(args json.RawMessage) (any, error) {
+	var req struct {
+		Labels []string `json:"labels"`
+		Limit  int      `json:"limit"`
+	}
+	if err := decodeToolArgs(args, &req); err != nil {
+		return nil, err
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	var labels []string
+	for _, raw := range req.Labels {
+		labels = append(labels, tasks.NormalizeLabelsCSV(raw)...)
+	}
+
+	nodes, err := m.api.readNodes()
+	if err != nil {
+		return nil, err
+	}
+	return nodeList(nextActionableNodes(nodes, tasks.MergeLabels(labels), req.Limit)), nil
+}
+
+// nextActionableNodes returns open checklist items with no open checklist
+// children, in document order: the leaves an agent can work on right away.
+func nextActionableNodes(nodes []indexer.Node, labels []string, limit int) []indexer.Node {
+	hasOpenChild := map[string]bool{}
+	for _, node := range nodes {
+		if node.Kind == "checklist" && node.State == "open" && node.ParentID != "" {
+			hasOpenChild[node.ParentID] = true
+		}
+	}
+	var out []indexer.Node
+	for _, node := range nodes {
+		if node.Kind != "checklist" || node.State != "open" || hasOpenChild[node.ID] {
+			continue
+		}
+		if !hasAllLabels(node.Labels, labels) {
+			continue
+		}
+		out = append(out, node)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// nodeList wraps node slices in an object, since MCP structured content must
+// be a JSON object.
+func nodeList(nodes []indexer.Node) map[string]any {
+	if nodes == nil {
+		nodes = []indexer.Node{}
+	}
+	return map[string]any{"nodes": nodes}
+}
+
+func (m *mcpServer) listResources() ([]mcpResource, error) {
+	m.api.mu.RLock()
+	projects, err := indexer.ReadProjectNodes(m.api.dbPath)
+	m.api.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []mcpResource{}
+	inbox := filepath.Join(m.api.root, ".taskgraph", "issues.md")
+	if _, err := os.Stat(inbox); err == nil {
+		resources = append(resources, mcpResource{
+			URI:         mcpResourcePrefix + ".taskgraph/issues.md",
+			Name:        "Inbox",
+			Description: "Tasks captured with tg add",
+			MimeType:    "text/markdown",
+		})
+	}
+	for _, p := range projects {
+		mimeType, ok := mcpResourceMimeType(p.Path)
+		if !ok {
+			continue
+		}
+		resources = append(resources, mcpResource{
+			URI:         mcpResourcePrefix + p.Path,
+			Name:        p.Title,
+			Description: fmt.Sprintf("%d open tasks", p.OpenTaskCount),
+			MimeType:    mimeType,
+		})
+	}
+	return resources, nil
+}
+
+func (m *mcpServer) readResource(uri string) (any, *jsonRPCError) {
+	rel, ok := strings.CutPrefix(uri, mcpResourcePrefix)
+	mimeType, known := mcpResourceMimeType(rel)
+	if !ok || rel == "" || !known {
+		return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "unknown resource: " + uri}
+	}
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "unknown resource: " + uri}
+	}
+	content, err := os.ReadFile(filepath.Join(m.api.root, clean))
+	if err != nil {
+		return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "unknown resource: " + uri}
+	}
+	return map[string]any{
+		"contents": []map[string]string{{
+			"uri":      uri,
+			"mimeType": mimeType,
+			"text":     string(content),
+		}},
+	}, nil
+}
+
+// mcpResourceMimeType returns the MIME type of a project file the index can
+// read, and false for any other file.
+func mcpResourceMimeType(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md":
+		return "text/markdown", true
+	case ".org":
+		return "text/org", true
+	case ".taskpaper":
+		return "text/plain", true
+	}
+	return "", false
+}
+
+func mcpNodeSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":                map[string]any{"type": "string", "description": "Stable index node ID"},
+			"kind":              map[string]any{"type": "string", "enum": []string{"file", "heading", "checklist"}},
+			"title":             map[string]any{"type": "string"},
+			"state":             map[string]any{"type": "string", "enum": []string{"open", "closed", "unknown"}, "description": "open or closed for checklist tasks, unknown for files and headings"},
+			"path":              map[string]any{"type": "string", "description": "Path relative to the project root"},
+			"line":              map[string]any{"type": "integer"},
+			"parent_id":         map[string]any{"type": "string"},
+			"context":           map[string]any{"type": "string"},
+			"source":            map[string]any{"type": "string"},
+			"source_mtime_unix": map[string]any{"type": "integer"},
+			"labels":            map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"refs":              map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Task IDs a code comment mentions"},
+		},
+		"required": []string{"id", "kind", "title", "path", "line"},
+	}
+}
+
+func mcpNodeListSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"nodes": map[string]any{"type": "array", "items": mcpNodeSchema()},
+		},
+		"required": []string{"nodes"},
+	}
+}
+
+func mcpTreeSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"node":     mcpNodeSchema(),
+			"children": map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+		},
+		"required": []string{"node"},
+	}
+}
+
+func mcpObjectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func mcpTools() []mcpTool {
+	str := func(description string) map[string]any {
+		return map[string]any{"type": "string", "description": description}
+	}
+	boolean := func(description string) map[string]any {
+		return map[string]any{"type": "boolean", "description": description}
+	}
+	integer := func(description string) map[string]any {
+		return map[string]any{"type": "integer", "minimum": 1, "description": description}
+	}
+	labels := map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Labels without the leading #"}
+
+	return []mcpTool{
+		{
+			Name:        "add",
+			Description: "Add a task to the inbox, a markdown file, a heading or under a parent task (same as tg add).",
+			InputSchema: mcpObjectSchema(map[string]any{
+				"text":   str("Task text"),
+				"labels": labels,
+				"type":   str("Task type, e.g. epic; must be allowed by config.yml"),
+				"to":     str("Target file relative to the project root, optionally path#heading"),
+				"parent": str("Task ID to nest the new task under"),
+			}, "text"),
+			OutputSchema: mcpObjectSchema(map[string]any{
+				"id":   str("New task ID"),
+				"node": mcpNodeSchema(),
+			}, "id"),
+		},
+		{
+			Name:        "close",
+			Description: "Close an inbox task by ID (same as tg close).",
+			InputSchema: mcpObjectSchema(map[string]any{
+				"id":     str("Task ID"),
+				"reason": str("Optional close reason"),
+			}, "id"),
+			OutputSchema: mcpObjectSchema(map[string]any{
+				"id":    str("Task ID"),
+				"state": str("New state"),
+			}, "id", "state"),
+		},
+		{
+			Name:        "list",
+			Description: "List checklist tasks (same as tg list).",
+			InputSchema: mcpObjectSchema(map[string]any{
+				"all":    boolean("Include closed tasks"),
+				"labels": labels,
+			}),
+			OutputSchema: mcpNodeListSchema(),
+		},
+		{
+			Name:        "graph",
+			Description: "Return the task graph as nested nodes, or the subtree under one node (same roots and limits as tg graph).",
+			InputSchema: mcpObjectSchema(map[string]any{
+				"id":           str("Node or task ID; omit for the whole graph"),
+				"depth":        integer("Maximum depth (default 4)"),
+				"max_children": integer("Maximum children per node for the whole graph (default 5)"),
+				"all":          boolean("Include closed tasks"),
+			}),
+		},
+		{
+			Name:         "show",
+			Description:  "Show one node by node or task ID with its direct children.",
+			InputSchema:  mcpObjectSchema(map[string]any{"id": str("Node or task ID")}, "id"),
+			OutputSchema: mcpTreeSchema(),
+		},
+		{
+			Name:        "search",
+			Description: "Full-text search over titles and context; every term must match.",
+			InputSchema: mcpObjectSchema(map[string]any{
+				"query": str("Search terms"),
+				"all":   boolean("Include closed tasks"),
+				"limit": integer("Maximum results (default 50)"),
+			}, "query"),
+			OutputSchema: mcpNodeListSchema(),
+		},
+		{
+			Name:        "next",
+			Description: "Suggest open tasks with no open subtasks, in document order.",
+			InputSchema: mcpObjectSchema(map[string]any{
+				"labels": labels,
+				"limit":  integer("Maximum results (default 10)"),
+			}),
+			OutputSchema: mcpNodeListSchema(),
+		},
+	}
+}
+
+func runMCP(stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	m := newMCPServer(root)
+	m.api.mu.Lock()
+	err = m.api.reindex()
+	m.api.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.serve(stdin, stdout)
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestMCPServer(t *testing.T) (*mcpServer, string) {
+	t.Helper()
+	s, _, dir := newTestAPIServer(t)
+	return &mcpServer{api: s}, dir
+}
+
+func callMCP(t *testing.T, m *mcpServer, method string, params any) jsonRPCResponse {
+	t.Helper()
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+	msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q,"params":%s}`, method, raw)
+	var resp struct {
+		jsonRPCResponse
+		Result json.RawMessage `json:"result"`
+	}
+	reply := m.handle([]byte(msg))
+	if err := json.Unmarshal(reply, &resp); err != nil {
+		t.Fatalf("decode reply %q: %v", reply, err)
+	}
+	resp.jsonRPCResponse.Result = resp.Result
+	return resp.jsonRPCResponse
+}
+
+func callMCPTool(t *testing.T, m *mcpServer, name string, args any, v any) mcpToolResult {
+	t.Helper()
+	resp := callMCP(t, m, "tools/call", map[string]any{"name": name, "arguments": args})
+	if resp.Error != nil {
+		t.Fatalf("tools/call %s returned rpc error: %#v", name, resp.Error)
+	}
+	var result struct {
+		mcpToolResult
+		StructuredContent json.RawMessage `json:"structuredContent"`
+	}
+	if err := json.Unmarshal(resp.Result.(json.RawMessage), &result); err != nil {
+		t.Fatalf("decode tool result: %v", err)
+	}
+	if v != nil && !result.IsError {
+		if err := json.Unmarshal(result.StructuredContent, v); err != nil {
+			t.Fatalf("decode structured content %s: %v", result.StructuredContent, err)
+		}
+	}
+	return result.mcpToolResult
+}
+
+func TestMCPInitializeAndListTools(t *testing.T) {
+	m, _ := newTestMCPServer(t)
+
+	resp := callMCP(t, m, "initialize", map[string]any{"protocolVersion": "2024-11-05"})
+	if resp.Error != nil || !strings.Contains(string(resp.Result.(json.RawMessage)), `"protocolVersion":"2024-11-05"`) {
+		t.Fatalf("unexpected initialize response: %#v", resp)
+	}
+	if reply := m.handle([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); reply != nil {
+		t.Fatalf("expected no reply to notification, got %s", reply)
+	}
+
+	var listed struct {
+		Tools []mcpTool `json:"tools"`
+	}
+	resp = callMCP(t, m, "tools/list", map[string]any{})
+	if err := json.Unmarshal(resp.Result.(json.RawMessage), &listed); err != nil {
+		t.Fatalf("decode tools: %v", err)
+	}
+	var names []string
+	for _, tool := range listed.Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "add,close,list,graph,show,search,next" {
+		t.Fatalf("unexpected tools: %v", names)
+	}
+
+	resp = callMCP(t, m, "bogus", map[string]any{})
+	if resp.Error == nil || resp.Error.Code != jsonRPCMethodNotFound {
+		t.Fatalf("expected method not found, got %#v", resp)
+	}
+	if reply := string(m.handle([]byte(`{not json`))); !strings.Contains(reply, "-32700") {
+		t.Fatalf("expected parse error, got %s", reply)
+	}
+}
+
+func TestMCPToolsMatchCLIBehaviour(t *testing.T) {
+	m, dir := newTestMCPServer(t)
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	callMCPTool(t, m, "add", map[string]any{"text": "Record demo", "parent": "tg-epic"}, &created)
+	if created.ID == "" {
+		t.Fatalf("expected new task id")
+	}
+
+	var next struct {
+		Nodes []struct {
+			Title string `json:"title"`
+		} `json:"nodes"`
+	}
+	callMCPTool(t, m, "next", map[string]any{}, &next)
+	if len(next.Nodes) != 2 || next.Nodes[0].Title != "Write copy #web" || !strings.Contains(next.Nodes[1].Title, "Record demo") {
+		t.Fatalf("unexpected next tasks: %#v", next)
+	}
+
+	var shown graphTree
+	callMCPTool(t, m, "show", map[string]any{"id": "tg-epic"}, &shown)
+	if shown.Node.Line != 4 || len(shown.Children) != 3 {
+		t.Fatalf("unexpected show result: %#v", shown)
+	}
+
+	var inbox struct {
+		ID string `json:"id"`
+	}
+	callMCPTool(t, m, "add", map[string]any{"text": "Book venue"}, &inbox)
+	callMCPTool(t, m, "close", map[string]any{"id": inbox.ID, "reason": "done"}, nil)
+	if content := readFile(t, filepath.Join(dir, ".taskgraph", "issues.md")); !strings.Contains(content, "Book venue **✅") {
+		t.Fatalf("expected task closed in inbox, got %q", content)
+	}
+
+	var listed struct {
+		Nodes []struct {
+			Labels []string `json:"labels"`
+		} `json:"nodes"`
+	}
+	callMCPTool(t, m, "list", map[string]any{"labels": []string{"web"}}, &listed)
+	if len(listed.Nodes) != 2 || listed.Nodes[0].Labels[0] != "t-epic" {
+		t.Fatalf("unexpected list result: %#v", listed)
+	}
+
+	if result := callMCPTool(t, m, "add", map[string]any{"text": "x", "type": "nonsense"}, nil); !result.IsError || !strings.Contains(result.Content[0].Text, "unknown task type") {
+		t.Fatalf("expected tool error, got %#v", result)
+	}
+	if result := callMCPTool(t, m, "show", map[string]any{"id": "tg-missing"}, nil); !result.IsError {
+		t.Fatalf("expected tool error for missing node, got %#v", result)
+	}
+}
+
+func TestMCPResourcesExposeProjectFiles(t *testing.T) {
+	m, dir := newTestMCPServer(t)
+	mustWrite(t, filepath.Join(dir, "plans.org"), "* TODO Plan garden\n")
+	if err := m.api.reindex(); err != nil {
+		t.Fatalf("reindex failed: %v", err)
+	}
+
+	var listed struct {
+		Resources []mcpResource `json:"resources"`
+	}
+	resp := callMCP(t, m, "resources/list", map[string]any{})
+	if err := json.Unmarshal(resp.Result.(json.RawMessage), &listed); err != nil {
+		t.Fatalf("decode resources: %v", err)
+	}
+	found, foundOrg := false, false
+	for _, r := range listed.Resources {
+		found = found || r.URI == "taskgraph://files/roadmap.md" && r.MimeType == "text/markdown"
+		foundOrg = foundOrg || r.URI == "taskgraph://files/plans.org" && r.MimeType == "text/org"
+	}
+	if !found || !foundOrg {
+		t.Fatalf("expected roadmap and org resources, got %#v", listed.Resources)
+	}
+
+	resp = callMCP(t, m, "resources/read", map[string]any{"uri": "taskgraph://files/roadmap.md"})
+	if resp.Error != nil || !strings.Contains(string(resp.Result.(json.RawMessage)), "Launch site") {
+		t.Fatalf("unexpected resource read: %#v", resp)
+	}
+	resp = callMCP(t, m, "resources/read", map[string]any{"uri": "taskgraph://files/plans.org"})
+	if resp.Error != nil || !strings.Contains(string(resp.Result.(json.RawMessage)), "Plan garden") {
+		t.Fatalf("unexpected org resource read: %#v", resp)
+	}
+	resp = callMCP(t, m, "resources/read", map[string]any{"uri": "taskgraph://files/../secret.md"})
+	if resp.Error == nil {
+		t.Fatalf("expected error for path outside the project")
+	}
+}
@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+)
+
+const moveUsage = "usage: tg move <id> <path>[#heading] | tg move <id> [path] --parent <id>"
+
+type moveOptions struct {
+	id       string
+	toPath   string
+	heading  string
+	parentID string
+}
+
+func parseMoveArgs(args []string) (moveOptions, error) {
+	var opts moveOptions
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--parent":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return moveOptions{}, errors.New(moveUsage)
+			}
+			opts.parentID = strings.TrimSpace(args[i+1])
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			return moveOptions{}, errors.New(moveUsage)
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) < 1 || len(positional) > 2 || strings.TrimSpace(positional[0]) == "" {
+		return moveOptions{}, errors.New(moveUsage)
+	}
+	opts.id = strings.TrimSpace(positional[0])
+	if len(positional) == 2 {
+		path, heading, _ := strings.Cut(strings.TrimSpace(positional[1]), "#")
+		opts.toPath = strings.TrimSpace(path)
+		opts.heading = strings.TrimSpace(heading)
+	}
+	if opts.toPath == "" && opts.parentID == "" {
+		return moveOptions{}, errors.New(moveUsage)
+	}
+	if opts.heading != "" && opts.parentID != "" {
+		return moveOptions{}, fmt.Errorf("use either path#heading or --parent, not both")
+	}
+	return opts, nil
+}
+
+func runMove(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts, err := parseMoveArgs(args)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	dest, err := moveTask(root, opts)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+	fmt.Fprintf(stdout, "Moved %s to %s\n", opts.id, dest)
+	return nil
+}
+
+// moveTask moves the task and its subtasks to the destination in opts,
+// refreshes the index and returns a description of where it went.
+func moveTask(root string, opts moveOptions) (string, error) {
+	srcRel, err := findTaskPath(root, opts.id)
+	if err != nil {
+		return "", err
+	}
+	dstFile, err := resolveAddTarget(root, opts.toPath, opts.parentID)
+	if err != nil {
+		return "", err
+	}
+	placement := tasks.Placement{Heading: opts.heading, ParentID: opts.parentID}
+	if err := tasks.MoveTask(filepath.Join(root, filepath.FromSlash(srcRel)), opts.id, dstFile, placement); err != nil {
+		return "", err
+	}
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return "", err
+	}
+
+	dest := dstFile
+	if rel, err := filepath.Rel(root, dstFile); err == nil {
+		dest = filepath.ToSlash(rel)
+	}
+	switch {
+	case opts.parentID != "":
+		dest += " under " + opts.parentID
+	case opts.heading != "":
+		dest += "#" + opts.heading
+	}
+	return dest, nil
+}
+
+const triageHelp = `  m <path>[#heading]  move to a file or section   p <id>  move under a task
+  l <a,b>             add labels                  t <type>  set the type
+  c [reason]          close                       s  skip   q  quit`
+
+// runTriage walks the open top-level inbox items one by one and asks what to
+// do with each. Labels and types keep the item on screen so it can still be
+// moved; every other action goes on to the next item.
+func runTriage(in io.Reader, stdout io.Writer, stderr io.Writer) error {
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+	inbox := filepath.Join(root, ".taskgraph", "issues.md")
+
+	b, err := os.ReadFile(inbox)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var ids []string
+	untracked := 0
+	for _, line := range strings.Split(string(b), "\n") {
+		task, ok := tasks.ParseTask(line)
+		// Subtasks travel with their parent, so only top-level items count.
+		if !ok || task.Done || strings.TrimLeft(task.Prefix, " \t") != task.Prefix {
+			continue
+		}
+		if task.ID == "" {
+			untracked++
+			continue
+		}
+		ids = append(ids, task.ID)
+	}
+	if untracked > 0 {
+		fmt.Fprintf(stdout, "Skipping %d inbox item(s) without an ID; run `tg adopt .taskgraph/issues.md` first.\n", untracked)
+	}
+	if len(ids) == 0 {
+		fmt.Fprintln(stdout, "Inbox is empty.")
+		return nil
+	}
+
+	scanner := bufio.NewScanner(in)
+	moved, closed, skipped := 0, 0, 0
+	fmt.Fprintln(stdout, triageHelp)
+items:
+	for n, id := range ids {
+		for {
+			line, err := inboxLine(inbox, id)
+			if err != nil {
+				// Moved or removed outside triage since we started.
+				continue items
+			}
+			fmt.Fprintf(stdout, "\n[%d/%d] %s\n> ", n+1, len(ids), strings.TrimSpace(line))
+			if !scanner.Scan() {
+				fmt.Fprintln(stdout)
+				break items
+			}
+			cmd, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+			arg = strings.TrimSpace(arg)
+			switch cmd {
+			case "", "s", "skip":
+				skipped++
+				continue items
+			case "q", "quit":
+				break items
+			case "m", "move", "p", "parent":
+				opts := moveOptions{id: id}
+				if cmd == "p" || cmd == "parent" {
+					opts.parentID = arg
+				} else {
+					path, heading, _ := strings.Cut(arg, "#")
+					opts.toPath, opts.heading = strings.TrimSpace(path), strings.TrimSpace(heading)
+				}
+				if opts.toPath == "" && opts.parentID == "" {
+					fmt.Fprintln(stdout, "  needs a destination")
+					continue
+				}
+				dest, err := moveTask(root, opts)
+				if err != nil {
+					fmt.Fprintf(stdout, "  %v\n", err)
+					continue
+				}
+				fmt.Fprintf(stdout, "  moved to %s\n", dest)
+				moved++
+				continue items
+			case "l", "label", "labels":
+				labels := tasks.NormalizeLabelsCSV(arg)
+				if len(labels) == 0 {
+					fmt.Fprintln(stdout, "  needs labels, e.g. l home,errands")
+					continue
+				}
+				if err := checkRegisteredLabels(root, labels); err != nil {
+					fmt.Fprintf(stdout, "  %v\n", err)
+					continue
+				}
+				if err := tasks.AddTaskLabels(inbox, id, labels); err != nil {
+					fmt.Fprintf(stdout, "  %v\n", err)
+				}
+			case "t", "type":
+				taskType := tasks.NormalizeTaskType(arg)
+				allowed, err := project.ReadAllowedIssueTypes(root)
+				if err != nil {
+					return err
+				}
+				if !containsString(allowed, taskType) {
+					sort.Strings(allowed)
+					fmt.Fprintf(stdout, "  unknown task type %q (allowed: %s)\n", arg, strings.Join(allowed, ", "))
+					continue
+				}
+				if err := tasks.SetTaskType(inbox, id, taskType); err != nil {
+					fmt.Fprintf(stdout, "  %v\n", err)
+				}
+			case "c", "close":
+				if err := closeTask(root, id, arg); err != nil {
+					fmt.Fprintf(stdout, "  %v\n", err)
+					continue
+				}
+				fmt.Fprintln(stdout, "  closed")
+				closed++
+				continue items
+			default:
+				fmt.Fprintln(stdout, triageHelp)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Triage done: %d moved, %d closed, %d skipped.\n", moved, closed, skipped)
+	return nil
+}
+
+// inboxLine returns the inbox line carrying [id].
+func inboxLine(inbox, id string) (string, error) {
+	b, err := os.ReadFile(inbox)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if task, ok := tasks.ParseTask(line); ok && task.ID == id {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", tasks.ErrTaskNotFound, id)
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveFromInboxToHeadingAndParent(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	inbox := filepath.Join(dir, ".taskgraph", "issues.md")
+	mustWrite(t, inbox, "- [ ] ➕2024-01-02 [tg-aaa] Book venue #launch\n  - [ ] [tg-bbb] Call hotel\n- [ ] [tg-ccc] Print flyers\n")
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	roadmap := filepath.Join(dir, "docs", "roadmap.md")
+	mustWrite(t, roadmap, "# Roadmap\n\n## Q3\n\n- [ ] [tg-ep1] Launch\n")
+
+	stdout, stderr, err := run([]string{"move", "tg-aaa", "docs/roadmap.md#Q3"})
+	if err != nil {
+		t.Fatalf("move returned err: %v stderr=%q", err, stderr)
+	}
+	if stdout != "Moved tg-aaa to docs/roadmap.md#Q3\n" {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+
+	stdout, stderr, err = run([]string{"move", "tg-ccc", "--parent", "tg-ep1"})
+	if err != nil {
+		t.Fatalf("move --parent returned err: %v stderr=%q", err, stderr)
+	}
+	if stdout != "Moved tg-ccc to docs/roadmap.md under tg-ep1\n" {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+
+	b, _ := os.ReadFile(inbox)
+	if string(b) != "" {
+		t.Fatalf("expected empty inbox, got %q", b)
+	}
+	b, _ = os.ReadFile(roadmap)
+	want := "# Roadmap\n\n## Q3\n\n- [ ] [tg-ep1] Launch\n  - [ ] [tg-ccc] Print flyers\n- [ ] ➕2024-01-02 [tg-aaa] Book venue #launch\n  - [ ] [tg-bbb] Call hotel\n"
+	if string(b) != want {
+		t.Fatalf("unexpected roadmap:\n%s\nwant:\n%s", b, want)
+	}
+
+	if _, _, err := run([]string{"move", "tg-zzz", "docs/roadmap.md"}); err == nil {
+		t.Fatalf("expected error for unknown task")
+	}
+	if _, _, err := run([]string{"move", "tg-aaa"}); err == nil {
+		t.Fatalf("expected usage error without a destination")
+	}
+}
+
+func TestMoveUnderStarBulletedParentStaysIndexed(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-aaa] Book venue\n")
+	notes := filepath.Join(dir, "notes.md")
+	mustWrite(t, notes, "# Notes\n\n- [ ] [tg-ep1] Launch\n  * Pick up keys\n  * Water plants\n\n## Later\n")
+
+	if _, stderr, err := run([]string{"move", "tg-aaa", "--parent", "tg-ep1"}); err != nil {
+		t.Fatalf("move returned err: %v stderr=%q", err, stderr)
+	}
+	want := "# Notes\n\n- [ ] [tg-ep1] Launch\n  * Pick up keys\n  * Water plants\n  - [ ] [tg-aaa] Book venue\n\n## Later\n"
+	if got := readFile(t, notes); got != want {
+		t.Fatalf("unexpected notes:\n%s\nwant:\n%s", got, want)
+	}
+
+	stdout, stderr, err := run([]string{"list"})
+	if err != nil || !strings.Contains(stdout, "[tg-aaa] Book venue") {
+		t.Fatalf("expected moved task indexed, got stdout=%q stderr=%q err=%v", stdout, stderr, err)
+	}
+	if _, stderr, err := run([]string{"move", "tg-aaa", "notes.md#Later"}); err != nil {
+		t.Fatalf("second move returned err: %v stderr=%q", err, stderr)
+	}
+	want = "# Notes\n\n- [ ] [tg-ep1] Launch\n  * Pick up keys\n  * Water plants\n\n## Later\n\n- [ ] [tg-aaa] Book venue\n"
+	if got := readFile(t, notes); got != want {
+		t.Fatalf("unexpected notes after second move:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTriageWalksInboxItems(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	inbox := filepath.Join(dir, ".taskgraph", "issues.md")
+	mustWrite(t, inbox, strings.Join([]string{
+		"- [ ] [tg-aaa] Fix login",
+		"- [ ] [tg-bbb] Old idea",
+		"- [ ] [tg-ccc] Maybe later",
+		"- [ ] No id yet",
+		"- [x] [tg-ddd] Done",
+	}, "\n")+"\n")
+	mustWrite(t, filepath.Join(dir, "bugs.md"), "# Bugs\n")
+
+	var out, errOut bytes.Buffer
+	input := strings.NewReader("l web\nt wizard\nt bug\nm bugs.md#Bugs\nc not needed\ns\n")
+	if err := runTriage(input, &out, &errOut); err != nil {
+		t.Fatalf("triage returned err: %v stderr=%q", err, errOut.String())
+	}
+	stdout := out.String()
+	for _, want := range []string{
+		"Skipping 1 inbox item(s) without an ID",
+		"[1/3] - [ ] [tg-aaa] Fix login #web #t-bug",
+		`unknown task type "wizard"`,
+		"moved to bugs.md#Bugs",
+		"closed",
+		"Triage done: 1 moved, 1 closed, 1 skipped.",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+
+	b, _ := os.ReadFile(filepath.Join(dir, "bugs.md"))
+	if string(b) != "# Bugs\n\n- [ ] [tg-aaa] Fix login #web #t-bug\n" {
+		t.Fatalf("unexpected bugs.md:\n%s", b)
+	}
+	b, _ = os.ReadFile(inbox)
+	lines := strings.Split(string(b), "\n")
+	if !strings.HasPrefix(lines[0], "- [x] [tg-bbb] Old idea **✅") || !strings.HasSuffix(lines[0], " not needed**") || lines[1] != "- [ ] [tg-ccc] Maybe later" {
+		t.Fatalf("unexpected inbox:\n%s", b)
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+)
+
+const queryUsage = "usage: tg query <query>"
+
+func runQuery(args []string, stdout io.Writer, stderr io.Writer) error {
+	src := strings.TrimSpace(strings.Join(args, " "))
+	if src == "" {
+		fmt.Fprintln(stderr, queryUsage)
+		return errors.New(queryUsage)
+	}
+	query, err := indexer.ParseQuery(src)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	nodes, err := indexer.ReadQueryNodes(filepath.Join(root, ".taskgraph", "taskgraph.db"), query)
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		switch n.Kind {
+		case "checklist":
+			mark := " "
+			if n.State == "closed" {
+				mark = "x"
+			}
+			fmt.Fprintf(stdout, "- [%s] %s (%s:%d)\n", mark, n.Title, n.Path, n.Line)
+		default:
+			fmt.Fprintf(stdout, "%s %s (%s:%d)\n", n.Kind, n.Title, n.Path, n.Line)
+		}
+	}
+	return nil
+}
+
+func nodeIDSet(nodes []indexer.Node) map[string]bool {
+	ids := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		ids[n.ID] = true
+	}
+	return ids
+}
+
+// keepNodes returns the nodes whose IDs are in ids, preserving order.
+func keepNodes(nodes []indexer.Node, ids map[string]bool) []indexer.Node {
+	out := make([]indexer.Node, 0, len(ids))
+	for _, n := range nodes {
+		if ids[n.ID] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// withAncestors adds the parents of every node in ids, up to the file node,
+// so a filtered graph still hangs off its roots.
+func withAncestors(nodes []indexer.Node, ids map[string]bool) map[string]bool {
+	parentOf := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		parentOf[n.ID] = n.ParentID
+	}
+	out := make(map[string]bool, len(ids))
+	for id := range ids {
+		for id != "" && !out[id] {
+			out[id] = true
+			id = parentOf[id]
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQueryListAndGraphWhere(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-in1] Inbox chore\n")
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	mustWrite(t, filepath.Join(dir, "docs", "roadmap.md"), strings.Join([]string{
+		"# Roadmap",
+		"",
+		"- [ ] [tg-ep1] Launch #t-epic",
+		"  - [ ] [tg-b01] Fix login #t-bug",
+		"  - [x] [tg-b02] Fix logout #t-bug",
+		"- [ ] [tg-b03] Flaky upload #t-bug #someday",
+	}, "\n")+"\n")
+
+	if _, stderr, err := run([]string{"index"}); err != nil {
+		t.Fatalf("index returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, stderr, err := run([]string{"query", "type:bug", "-label:someday"})
+	if err != nil {
+		t.Fatalf("query returned err: %v stderr=%q", err, stderr)
+	}
+	want := "- [ ] [tg-b01] Fix login #t-bug (docs/roadmap.md:4)\n- [x] [tg-b02] Fix logout #t-bug (docs/roadmap.md:5)\n"
+	if stdout != want {
+		t.Fatalf("unexpected query output:\n%s", stdout)
+	}
+
+	stdout, _, err = run([]string{"list", "--where", "path:docs/** type:bug"})
+	if err != nil {
+		t.Fatalf("list --where returned err: %v", err)
+	}
+	if !strings.Contains(stdout, "tg-b01") || !strings.Contains(stdout, "tg-b03") || strings.Contains(stdout, "tg-b02") || strings.Contains(stdout, "tg-in1") {
+		t.Fatalf("unexpected list --where output:\n%s", stdout)
+	}
+
+	stdout, _, err = run([]string{"graph", "--where", "under:tg-ep1 state:open"})
+	if err != nil {
+		t.Fatalf("graph --where returned err: %v", err)
+	}
+	if !strings.Contains(stdout, "tg-ep1") || !strings.Contains(stdout, "tg-b01") || strings.Contains(stdout, "tg-b03") || strings.Contains(stdout, "Inbox chore") {
+		t.Fatalf("unexpected graph --where output:\n%s", stdout)
+	}
+
+	_, stderr, err = run([]string{"query", "owner:me"})
+	if err == nil || !strings.Contains(stderr, "unknown query field") {
+		t.Fatalf("expected unknown field error, got err=%v stderr=%q", err, stderr)
+	}
+	if _, _, err := run([]string{"query"}); err == nil {
+		t.Fatalf("expected usage error")
+	}
+}
@@ -0,0 +1,602 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+)
+
+const serveUsage = "usage: tg serve [--addr host:port] [--interval duration]"
+
+// graphTree is a node with its nested children, as returned by subtree queries.
+type graphTree struct {
+	Node     indexer.Node `json:"node"`
+	Children []graphTree  `json:"children,omitempty"`
+}
+
+// buildGraphTree returns the subtree rooted at id down to maxDepth levels,
+// hiding closed leaves unless includeClosed is set.
+func buildGraphTree(nodes []indexer.Node, id string, maxDepth int, includeClosed bool) (graphTree, bool) {
+	byID := make(map[string]indexer.Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+	node, ok := byID[id]
+	if !ok {
+		return graphTree{}, false
+	}
+	children := graphChildren(nodes)
+	visible := graphVisibility(byID, children, map[string]bool{}, includeClosed)
+
+	var walk func(node indexer.Node, level int) graphTree
+	walk = func(node indexer.Node, level int) graphTree {
+		tree := graphTree{Node: node}
+		if level >= maxDepth {
+			return tree
+		}
+		for _, child := range children[node.ID] {
+			if !visible[child.ID] {
+				continue
+			}
+			tree.Children = append(tree.Children, walk(child, level+1))
+		}
+		return tree
+	}
+	return walk(node, 0), true
+}
+
+// findNodeRef resolves either an index node ID or a task ID such as tg-abc.
+func findNodeRef(nodes []indexer.Node, ref string) (indexer.Node, bool) {
+	for _, node := range nodes {
+		if node.ID == ref {
+			return node, true
+		}
+	}
+	needle := "[" + ref + "]"
+	for _, node := range nodes {
+		if node.Kind == "checklist" && node.Source != indexer.CodeSource && strings.Contains(node.Title, needle) {
+			return node, true
+		}
+	}
+	return indexer.Node{}, false
+}
+
+// apiServer answers JSON queries over the SQLite index and keeps it current by
+// polling the indexed files for changes. addr is the host:port it listens on;
+// requests addressed to any other host are refused.
+type apiServer struct {
+	root        string
+	dbPath      string
+	addr        string
+	mu          sync.RWMutex
+	fingerprint string
+}
+
+func newAPIServer(root string) *apiServer {
+	return &apiServer{
+		root:   root,
+		dbPath: filepath.Join(root, ".taskgraph", "taskgraph.db"),
+	}
+}
+
+// sourceFingerprint summarises the path, size and mtime of every indexed file,
+// so any edit, addition or deletion changes it.
+func sourceFingerprint(root string) (string, error) {
+	files, err := indexer.SourceFiles(root)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+// reindex rebuilds the index. Callers must hold s.mu for writing.
+func (s *apiServer) reindex() error {
+	fingerprint, err := sourceFingerprint(s.root)
+	if err != nil {
+		return err
+	}
+	if _, _, err := buildAndStoreIndex(s.root); err != nil {
+		return err
+	}
+	s.fingerprint = fingerprint
+	return nil
+}
+
+// markIndexed records the current files as indexed after a mutation that
+// already rebuilt the index, so the watcher does not rebuild it again.
+// Callers must hold s.mu for writing.
+func (s *apiServer) markIndexed() {
+	if fingerprint, err := sourceFingerprint(s.root); err == nil {
+		s.fingerprint = fingerprint
+	}
+}
+
+// refreshIfChanged rebuilds the index when indexed files changed on disk and
+// reports whether it did.
+func (s *apiServer) refreshIfChanged() (bool, error) {
+	fingerprint, err := sourceFingerprint(s.root)
+	if err != nil {
+		return false, err
+	}
+	s.mu.RLock()
+	same := fingerprint == s.fingerprint
+	s.mu.RUnlock()
+	if same {
+		return false, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return true, s.reindex()
+}
+
+func (s *apiServer) watch(ctx context.Context, interval time.Duration, stderr io.Writer) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.refreshIfChanged(); err != nil {
+				fmt.Fprintf(stderr, "reindex failed: %v\n", err)
+			}
+		}
+	}
+}
+
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/nodes", s.handleNodes)
+	mux.HandleFunc("GET /api/nodes/{ref}", s.handleNode)
+	mux.HandleFunc("GET /api/nodes/{ref}/subtree", s.handleSubtree)
+	mux.HandleFunc("GET /api/graph", s.handleGraph)
+	mux.HandleFunc("GET /api/labels", s.handleLabels)
+	mux.HandleFunc("GET /api/projects", s.handleProjects)
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+	mux.HandleFunc("POST /api/tasks", s.handleAddTask)
+	mux.HandleFunc("POST /api/tasks/{id}/close", s.handleCloseTask)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status, err := s.checkRequest(r); err != nil {
+			writeJSONError(w, status, err)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// checkRequest keeps web pages from driving the API. The Host header and any
+// Origin must name the address the server listens on, which defeats DNS
+// rebinding, and writes must be JSON, which a cross-origin form cannot send
+// without a preflight.
+func (s *apiServer) checkRequest(r *http.Request) (int, error) {
+	if !s.isServerHost(r.Host) {
+		return http.StatusForbidden, fmt.Errorf("host %q does not match the server address %s", r.Host, s.addr)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !s.isServerHost(u.Host) {
+			return http.StatusForbidden, fmt.Errorf("origin %q does not match the server address %s", origin, s.addr)
+		}
+	}
+	if r.Method == http.MethodPost {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			return http.StatusUnsupportedMediaType, errors.New("request body must be application/json")
+		}
+	}
+	return 0, nil
+}
+
+// isServerHost reports whether hostport names the listening address. A
+// loopback or wildcard listener also answers to localhost; otherwise only IP
+// literals match, since a DNS name could be rebound to this machine.
+func (s *apiServer) isServerHost(hostport string) bool {
+	bindHost, bindPort, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		return false
+	}
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil || port != bindPort {
+		return false
+	}
+	bindIP := net.ParseIP(bindHost)
+	if strings.EqualFold(host, "localhost") {
+		return bindIP != nil && (bindIP.IsLoopback() || bindIP.IsUnspecified())
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || bindIP == nil {
+		return false
+	}
+	return ip.Equal(bindIP) || bindIP.IsUnspecified() || bindIP.IsLoopback() && ip.IsLoopback()
+}
+
+func (s *apiServer) readNodes() ([]indexer.Node, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return indexer.ReadGraphNodes(s.dbPath)
+}
+
+func (s *apiServer) handleNodes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	nodes, err := s.readNodes()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var labels []string
+	for _, raw := range q["label"] {
+		labels = append(labels, tasks.NormalizeLabelsCSV(raw)...)
+	}
+	labels = tasks.MergeLabels(labels)
+	kind := q.Get("kind")
+	state := q.Get("state")
+	pathPrefix := q.Get("path")
+
+	out := []indexer.Node{}
+	for _, node := range nodes {
+		if kind != "" && node.Kind != kind {
+			continue
+		}
+		if state != "" && node.State != state {
+			continue
+		}
+		if pathPrefix != "" && !strings.HasPrefix(node.Path, pathPrefix) {
+			continue
+		}
+		if !hasAllLabels(node.Labels, labels) {
+			continue
+		}
+		out = append(out, node)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *apiServer) handleNode(w http.ResponseWriter, r *http.Request) {
+	nodes, err := s.readNodes()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	node, ok := findNodeRef(nodes, r.PathValue("ref"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("node not found: %s", r.PathValue("ref")))
+		return
+	}
+	writeJSON(w, http.StatusOK, node)
+}
+
+func (s *apiServer) handleSubtree(w http.ResponseWriter, r *http.Request) {
+	depth, err := queryInt(r, "depth", 4)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	nodes, err := s.readNodes()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	node, ok := findNodeRef(nodes, r.PathValue("ref"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("node not found: %s", r.PathValue("ref")))
+		return
+	}
+	tree, _ := buildGraphTree(nodes, node.ID, depth, queryBool(r, "all"))
+	writeJSON(w, http.StatusOK, tree)
+}
+
+func (s *apiServer) handleGraph(w http.ResponseWriter, r *http.Request) {
+	depth, err := queryInt(r, "depth", 4)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	maxChildren, err := queryInt(r, "max_children", 5)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	nodes, err := s.readNodes()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nestGraphEntries(buildGraphEntries(nodes, depth, maxChildren, queryBool(r, "all"))))
+}
+
+// graphItem is one nested graph entry: a node, or a count of children hidden
+// by the max-children limit.
+type graphItem struct {
+	Node     *indexer.Node `json:"node,omitempty"`
+	Hidden   int           `json:"hidden,omitempty"`
+	Children []*graphItem  `json:"children,omitempty"`
+}
+
+// nestGraphEntries turns the flat entries tg graph prints back into a tree, so
+// JSON consumers and the CLI agree on roots, limits and visibility.
+func nestGraphEntries(entries []graphEntry) []*graphItem {
+	roots := []*graphItem{}
+	stack := []*graphItem{}
+	for _, entry := range entries {
+		item := &graphItem{Hidden: entry.Hidden}
+		if entry.Hidden == 0 {
+			node := entry.Node
+			item.Node = &node
+		}
+		stack = stack[:entry.Level]
+		if entry.Level == 0 {
+			roots = append(roots, item)
+		} else {
+			parent := stack[entry.Level-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+	}
+	return roots
+}
+
+func (s *apiServer) handleLabels(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	labels, err := indexer.ReadLabelCounts(s.dbPath)
+	s.mu.RUnlock()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+func (s *apiServer) handleProjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	projects, err := indexer.ReadProjectNodes(s.dbPath)
+	s.mu.RUnlock()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if projects == nil {
+		projects = []indexer.ProjectNode{}
+	}
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func (s *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit", 50)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.RLock()
+	nodes, err := indexer.SearchNodes(s.dbPath, r.URL.Query().Get("q"), queryBool(r, "all"), limit)
+	s.mu.RUnlock()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nodes)
+}
+
+type addTaskRequest struct {
+	Text   string   `json:"text"`
+	Labels []string `json:"labels"`
+	Type   string   `json:"type"`
+	To     string   `json:"to"`
+	Parent string   `json:"parent"`
+}
+
+func (s *apiServer) handleAddTask(w http.ResponseWriter, r *http.Request) {
+	var req addTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+		return
+	}
+	opts, err := addOptionsFromRequest(req)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, err := addTask(s.root, opts)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.markIndexed()
+	nodes, err := indexer.ReadGraphNodes(s.dbPath)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	node, _ := findNodeRef(nodes, id)
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id, "node": node})
+}
+
+// addOptionsFromRequest maps a JSON add request onto the same options tg add
+// parses from flags.
+func addOptionsFromRequest(req addTaskRequest) (addOptions, error) {
+	opts := addOptions{
+		text:     strings.TrimSpace(req.Text),
+		taskType: strings.TrimSpace(req.Type),
+		parentID: strings.TrimSpace(req.Parent),
+	}
+	if opts.text == "" {
+		return addOptions{}, errors.New("task text is required")
+	}
+	for _, raw := range req.Labels {
+		opts.labels = append(opts.labels, tasks.NormalizeLabelsCSV(raw)...)
+	}
+	opts.labels = tasks.MergeLabels(opts.labels)
+	if to := strings.TrimSpace(req.To); to != "" {
+		path, heading, _ := strings.Cut(to, "#")
+		opts.toPath = strings.TrimSpace(path)
+		opts.heading = strings.TrimSpace(heading)
+	}
+	if opts.heading != "" && opts.parentID != "" {
+		return addOptions{}, errors.New("use either to path#heading or parent, not both")
+	}
+	return opts, nil
+}
+
+func (s *apiServer) handleCloseTask(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+			return
+		}
+	}
+
+	id := r.PathValue("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := closeTask(s.root, id, req.Reason); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, tasks.ErrTaskNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, status, err)
+		return
+	}
+	s.markIndexed()
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "state": "closed"})
+}
+
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return value, nil
+}
+
+func queryBool(r *http.Request, name string) bool {
+	value, _ := strconv.ParseBool(r.URL.Query().Get(name))
+	return value
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func parseServeArgs(args []string) (string, time.Duration, error) {
+	addr := "127.0.0.1:7420"
+	interval := 2 * time.Second
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--addr":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", 0, fmt.Errorf(serveUsage)
+			}
+			addr = strings.TrimSpace(args[i+1])
+			i++
+		case "--interval":
+			if i+1 >= len(args) {
+				return "", 0, fmt.Errorf(serveUsage)
+			}
+			value, err := time.ParseDuration(args[i+1])
+			if err != nil || value <= 0 {
+				return "", 0, fmt.Errorf(serveUsage)
+			}
+			interval = value
+			i++
+		default:
+			return "", 0, fmt.Errorf(serveUsage)
+		}
+	}
+	return addr, interval, nil
+}
+
+func runServe(args []string, stdout io.Writer, stderr io.Writer) error {
+	addr, interval, err := parseServeArgs(args)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return err
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	s := newAPIServer(root)
+	if err := s.reindex(); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.addr = listener.Addr().String()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go s.watch(ctx, interval, stderr)
+
+	srv := &http.Server{Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(stdout, "Serving %s on http://%s\n", root, listener.Addr())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"taskgraph/internal/indexer"
+)
+
+func newTestAPIServer(t *testing.T) (*apiServer, *httptest.Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	_, stderr, err := run([]string{"init"})
+	if err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+	mustWrite(t, filepath.Join(dir, "roadmap.md"), strings.Join([]string{
+		"# Roadmap",
+		"",
+		"## Launch",
+		"- [ ] [tg-epic] Launch site #t-epic #web",
+		"  - [ ] Write copy #web",
+		"  - [x] Pick domain",
+	}, "\n")+"\n")
+
+	s := newAPIServer(dir)
+	if err := s.reindex(); err != nil {
+		t.Fatalf("reindex failed: %v", err)
+	}
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(ts.Close)
+	s.addr = ts.Listener.Addr().String()
+	return s, ts, dir
+}
+
+func getJSON(t *testing.T, url string, want int, v any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		t.Fatalf("GET %s: expected status %d, got %d", url, want, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s failed: %v", url, err)
+	}
+}
+
+func postJSON(t *testing.T, url, body string, want int, v any) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		t.Fatalf("POST %s: expected status %d, got %d", url, want, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s failed: %v", url, err)
+	}
+}
+
+func TestServeQueriesNodesLabelsAndSearch(t *testing.T) {
+	_, ts, _ := newTestAPIServer(t)
+
+	var nodes []indexer.Node
+	getJSON(t, ts.URL+"/api/nodes?kind=checklist&state=open&label=web", http.StatusOK, &nodes)
+	if len(nodes) != 2 || nodes[0].Path != "roadmap.md" || nodes[1].Title != "Write copy #web" {
+		t.Fatalf("unexpected nodes: %#v", nodes)
+	}
+
+	var node indexer.Node
+	getJSON(t, ts.URL+"/api/nodes/tg-epic", http.StatusOK, &node)
+	if node.Line != 4 || node.Kind != "checklist" {
+		t.Fatalf("unexpected node lookup: %#v", node)
+	}
+
+	var errBody map[string]string
+	getJSON(t, ts.URL+"/api/nodes/tg-missing", http.StatusNotFound, &errBody)
+	if !strings.Contains(errBody["error"], "tg-missing") {
+		t.Fatalf("unexpected error body: %#v", errBody)
+	}
+
+	var labels []indexer.LabelCount
+	getJSON(t, ts.URL+"/api/labels", http.StatusOK, &labels)
+	if len(labels) == 0 || labels[0].Label != "web" || labels[0].Count != 2 {
+		t.Fatalf("unexpected labels: %#v", labels)
+	}
+
+	var projects []indexer.ProjectNode
+	getJSON(t, ts.URL+"/api/projects", http.StatusOK, &projects)
+	if len(projects) != 1 || projects[0].OpenTaskCount != 2 {
+		t.Fatalf("unexpected projects: %#v", projects)
+	}
+
+	var results []indexer.Node
+	getJSON(t, ts.URL+"/api/search?q=copy", http.StatusOK, &results)
+	if len(results) != 1 || results[0].Title != "Write copy #web" {
+		t.Fatalf("unexpected search results: %#v", results)
+	}
+}
+
+func TestServeSubtreeHidesClosedUnlessAll(t *testing.T) {
+	_, ts, _ := newTestAPIServer(t)
+
+	var tree graphTree
+	getJSON(t, ts.URL+"/api/nodes/tg-epic/subtree", http.StatusOK, &tree)
+	if len(tree.Children) != 1 || tree.Children[0].Node.Title != "Write copy #web" {
+		t.Fatalf("unexpected subtree: %#v", tree)
+	}
+
+	getJSON(t, ts.URL+"/api/nodes/tg-epic/subtree?all=true", http.StatusOK, &tree)
+	if len(tree.Children) != 2 {
+		t.Fatalf("expected closed child with all=true, got %#v", tree)
+	}
+}
+
+func TestServeAddAndCloseTasks(t *testing.T) {
+	_, ts, dir := newTestAPIServer(t)
+
+	var created struct {
+		ID   string       `json:"id"`
+		Node indexer.Node `json:"node"`
+	}
+	postJSON(t, ts.URL+"/api/tasks", `{"text":"Record demo","labels":["web"],"parent":"tg-epic"}`, http.StatusCreated, &created)
+	if created.ID == "" || created.Node.ParentID == "" || created.Node.Path != "roadmap.md" {
+		t.Fatalf("unexpected add response: %#v", created)
+	}
+
+	content := readFile(t, filepath.Join(dir, "roadmap.md"))
+	if !strings.Contains(content, "  - [ ] ➕") || !strings.Contains(content, "Record demo #web") {
+		t.Fatalf("expected new child in roadmap, got %q", content)
+	}
+
+	postJSON(t, ts.URL+"/api/tasks", `{"text":"Book venue"}`, http.StatusCreated, &created)
+	var closed map[string]string
+	postJSON(t, ts.URL+"/api/tasks/"+created.ID+"/close", `{"reason":"shipped"}`, http.StatusOK, &closed)
+	if closed["state"] != "closed" {
+		t.Fatalf("unexpected close response: %#v", closed)
+	}
+	content = readFile(t, filepath.Join(dir, ".taskgraph", "issues.md"))
+	if !strings.Contains(content, "- [x] ➕") || !strings.Contains(content, "Book venue **✅") {
+		t.Fatalf("expected closed task in inbox, got %q", content)
+	}
+
+	var errBody map[string]string
+	postJSON(t, ts.URL+"/api/tasks", `{"text":"bad","type":"nonsense"}`, http.StatusBadRequest, &errBody)
+	if !strings.Contains(errBody["error"], "unknown task type") {
+		t.Fatalf("unexpected error body: %#v", errBody)
+	}
+	postJSON(t, ts.URL+"/api/tasks/tg-missing/close", ``, http.StatusNotFound, &errBody)
+}
+
+func TestServeRejectsCrossSiteRequests(t *testing.T) {
+	s, ts, dir := newTestAPIServer(t)
+	_, port, _ := net.SplitHostPort(s.addr)
+
+	send := func(method, path, contentType, body string, header map[string]string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("new request failed: %v", err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for k, v := range header {
+			if k == "Host" {
+				req.Host = v
+				continue
+			}
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	cases := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		header      map[string]string
+		want        int
+	}{
+		{"form post", "POST", "/api/tasks", "text/plain", nil, http.StatusUnsupportedMediaType},
+		{"post without type", "POST", "/api/tasks/tg-epic/close", "", nil, http.StatusUnsupportedMediaType},
+		{"foreign origin", "POST", "/api/tasks", "application/json", map[string]string{"Origin": "https://evil.example"}, http.StatusForbidden},
+		{"rebound host", "GET", "/api/nodes", "", map[string]string{"Host": "evil.example:" + port}, http.StatusForbidden},
+		{"other port", "GET", "/api/nodes", "", map[string]string{"Host": "127.0.0.1:1"}, http.StatusForbidden},
+		{"localhost", "GET", "/api/nodes", "", map[string]string{"Host": "localhost:" + port}, http.StatusOK},
+		{"same origin", "GET", "/api/nodes", "", map[string]string{"Origin": "http://localhost:" + port}, http.StatusOK},
+	}
+	for _, tc := range cases {
+		if got := send(tc.method, tc.path, tc.contentType, `{"text":"Sneaky"}`, tc.header); got != tc.want {
+			t.Fatalf("%s: expected status %d, got %d", tc.name, tc.want, got)
+		}
+	}
+	if content := readFile(t, filepath.Join(dir, ".taskgraph", "issues.md")); strings.Contains(content, "Sneaky") {
+		t.Fatalf("rejected request still added a task: %q", content)
+	}
+
+	var errBody map[string]string
+	postJSON(t, ts.URL+"/api/tasks", `{"text":"Escape","to":"../outside.md"}`, http.StatusBadRequest, &errBody)
+	if !strings.Contains(errBody["error"], "outside the project") {
+		t.Fatalf("unexpected error body: %#v", errBody)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "outside.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected no file outside the project, stat err=%v", err)
+	}
+}
+
+func TestServeReindexesWhenFilesChange(t *testing.T) {
+	s, ts, dir := newTestAPIServer(t)
+
+	path := filepath.Join(dir, "notes.md")
+	mustWrite(t, path, "# Notes\n\n- [ ] Fresh idea\n")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+
+	changed, err := s.refreshIfChanged()
+	if err != nil || !changed {
+		t.Fatalf("expected reindex after change, got changed=%v err=%v", changed, err)
+	}
+	changed, err = s.refreshIfChanged()
+	if err != nil || changed {
+		t.Fatalf("expected no reindex without changes, got changed=%v err=%v", changed, err)
+	}
+
+	var results []indexer.Node
+	getJSON(t, ts.URL+"/api/search?q=fresh", http.StatusOK, &results)
+	if len(results) != 1 {
+		t.Fatalf("expected new file in index, got %#v", results)
+	}
+}
+
+func TestParseServeArgs(t *testing.T) {
+	addr, interval, err := parseServeArgs([]string{"--addr", "127.0.0.1:9000", "--interval", "500ms"})
+	if err != nil || addr != "127.0.0.1:9000" || interval != 500*time.Millisecond {
+		t.Fatalf("unexpected parse: addr=%q interval=%v err=%v", addr, interval, err)
+	}
+	if _, _, err := parseServeArgs([]string{"--port", "1"}); err == nil {
+		t.Fatalf("expected usage error")
+	}
+}
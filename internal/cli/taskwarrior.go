@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"taskgraph/internal/migrate"
+	"taskgraph/internal/textdiff"
+)
+
+func runImportTaskwarrior(args []string, stdout io.Writer, stderr io.Writer) error {
+	root, input, dryRun, err := parseImportFileArgs(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	var summary migrate.TaskwarriorSummary
+	if dryRun {
+		var changes []migrate.FileChange
+		changes, summary, err = migrate.PlanTaskwarriorImport(root, input)
+		if err == nil {
+			for _, change := range changes {
+				fmt.Fprint(stdout, textdiff.Unified(change.Path, change.Before, change.After))
+			}
+		}
+	} else {
+		summary, err = migrate.ImportTaskwarrior(root, input)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return err
+	}
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(
+		stdout,
+		"%s %d tasks, %d updated, %d unchanged (%d deleted skipped, %d recurring templates skipped, %d invalid skipped)\n",
+		verb,
+		summary.Imported,
+		summary.Updated,
+		summary.Unchanged,
+		summary.SkippedDeleted,
+		summary.SkippedRecurring,
+		summary.SkippedInvalid,
+	)
+	fmt.Fprintf(
+		stdout,
+		"Mapped %d projects, %d priorities, %d labeled, %d dated, %d dependencies (%d links skipped)\n",
+		summary.Projects,
+		summary.Priorities,
+		summary.Labeled,
+		summary.Dates,
+		summary.Dependencies,
+		summary.SkippedLinks,
+	)
+	if !dryRun && summary.Imported+summary.Updated > 0 {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
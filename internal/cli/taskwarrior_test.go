@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportTaskwarriorCommand(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	if _, stderr, err := run([]string{"init"}); err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+	mustWrite(t, filepath.Join(dir, "tw.json"),
+		`[{"uuid":"0a1b2c3d-0000-4000-8000-000000000001","description":"Fix fence","project":"Home","tags":["diy"],"status":"pending"}]`)
+
+	stdout, stderr, err := run([]string{"import", "taskwarrior", "tw.json", "--dry-run"})
+	if err != nil {
+		t.Fatalf("dry run returned err: %v stderr=%q", err, stderr)
+	}
+	if !strings.Contains(stdout, "+## Home") || !strings.Contains(stdout, "Would import 1 tasks") {
+		t.Fatalf("unexpected dry-run output: %q", stdout)
+	}
+	if got := readFile(t, filepath.Join(dir, ".taskgraph", "issues.md")); got != "" {
+		t.Fatalf("dry run must not write, got %q", got)
+	}
+
+	for i, want := range []string{"Imported 1 tasks, 0 updated", "Imported 0 tasks, 0 updated, 1 unchanged"} {
+		stdout, stderr, err = run([]string{"import", "taskwarrior", "tw.json"})
+		if err != nil || !strings.Contains(stdout, want) {
+			t.Fatalf("import %d: err=%v stdout=%q stderr=%q", i, err, stdout, stderr)
+		}
+	}
+	stdout, _, err = run([]string{"list", "--label", "diy"})
+	if err != nil || !strings.Contains(stdout, "[tw:0a1b2c3d-0000-4000-8000-000000000001] Fix fence") {
+		t.Fatalf("expected imported task in index, got err=%v stdout=%q", err, stdout)
+	}
+}
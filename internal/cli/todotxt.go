@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/migrate"
+	"taskgraph/internal/project"
+	"taskgraph/internal/textdiff"
+)
+
+func runImportTodoTxt(args []string, stdout io.Writer, stderr io.Writer) error {
+	root, input, dryRun, err := parseImportFileArgs(args, stderr)
+	if err != nil {
+		return err
+	}
+	prefix, err := project.ReadPrefix(root)
+	if err != nil {
+		return err
+	}
+
+	var summary migrate.TodoTxtSummary
+	if dryRun {
+		var changes []migrate.FileChange
+		changes, summary, err = migrate.PlanTodoTxtImport(root, input, prefix, time.Now())
+		if err == nil {
+			for _, change := range changes {
+				fmt.Fprint(stdout, textdiff.Unified(change.Path, change.Before, change.After))
+			}
+		}
+	} else {
+		summary, err = migrate.ImportTodoTxt(root, input, prefix)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return err
+	}
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(stdout, "%s %d tasks (%d nested under parents, %d already present)\n", verb, summary.Imported, summary.Nested, summary.Duplicates)
+	if !dryRun && summary.Imported > 0 {
+		if _, _, err := buildAndStoreIndex(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runExportTodoTxt(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) > 1 {
+		fmt.Fprintln(stderr, exportUsage)
+		return errors.New(exportUsage)
+	}
+
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return errors.New("not initialized")
+	}
+
+	if _, _, err := buildAndStoreIndex(root); err != nil {
+		return err
+	}
+	nodes, err := indexer.ReadGraphNodes(filepath.Join(root, ".taskgraph", "taskgraph.db"))
+	if err != nil {
+		return err
+	}
+	out := migrate.ExportTodoTxt(nodes)
+	if len(args) == 0 {
+		fmt.Fprint(stdout, out)
+		return nil
+	}
+
+	path := args[0]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Exported %d tasks to %s\n", strings.Count(out, "\n"), path)
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTodoTxtExportThenImportIsLossless(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+	if _, stderr, err := run([]string{"init"}); err != nil {
+		t.Fatalf("init returned err: %v stderr=%q", err, stderr)
+	}
+	issues := strings.Join([]string{
+		"- [ ] ➕2026-10-01 [tg-a1b2] Plan trip 🔼 📅 2026-11-01 #travel #ctx-home #t-epic",
+		"  - [x] ➕2026-10-01 [tg-c3d4] Book flights **✅2026-10-03**",
+	}, "\n") + "\n"
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), issues)
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), "issue-prefix: tg\nscan-code: true\n")
+	mustWrite(t, filepath.Join(dir, "main.go"), "package main\n\n// TODO: handle errors\n")
+
+	stdout, stderr, err := run([]string{"export", "todotxt"})
+	if err != nil {
+		t.Fatalf("export returned err: %v stderr=%q", err, stderr)
+	}
+	want := "(C) 2026-10-01 Plan trip +travel @home type:epic due:2026-11-01 id:tg-a1b2\n" +
+		"x 2026-10-03 2026-10-01 Book flights id:tg-c3d4 parent:tg-a1b2\n"
+	if stdout != want {
+		t.Fatalf("unexpected export:\n got %q\nwant %q", stdout, want)
+	}
+
+	if _, stderr, err := run([]string{"export", "todotxt", "todo.txt"}); err != nil {
+		t.Fatalf("export to file returned err: %v stderr=%q", err, stderr)
+	}
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "")
+	stdout, stderr, err = run([]string{"import", "todotxt", "todo.txt", "--dry-run"})
+	if err != nil {
+		t.Fatalf("dry-run import returned err: %v stderr=%q", err, stderr)
+	}
+	if !strings.Contains(stdout, "+- [ ] ➕2026-10-01 [tg-a1b2] Plan trip") || !strings.Contains(stdout, "Would import 2 tasks") {
+		t.Fatalf("unexpected dry-run output: %q", stdout)
+	}
+	if got := readFile(t, filepath.Join(dir, ".taskgraph", "issues.md")); got != "" {
+		t.Fatalf("dry run wrote the inbox: %q", got)
+	}
+	stdout, stderr, err = run([]string{"import", "todotxt", "todo.txt"})
+	if err != nil {
+		t.Fatalf("import returned err: %v stderr=%q", err, stderr)
+	}
+	if !strings.Contains(stdout, "Imported 2 tasks (1 nested under parents, 0 already present)") {
+		t.Fatalf("unexpected import output: %q", stdout)
+	}
+	if got := readFile(t, filepath.Join(dir, ".taskgraph", "issues.md")); got != issues {
+		t.Fatalf("round trip changed markdown:\n got %q\nwant %q", got, issues)
+	}
+
+	stdout, _, err = run([]string{"import", "todotxt", "todo.txt"})
+	if err != nil || !strings.Contains(stdout, "Imported 0 tasks (0 nested under parents, 2 already present)") {
+		t.Fatalf("expected re-import to skip known ids, got err=%v stdout=%q", err, stdout)
+	}
+}
+
+func TestImportRequiresFormatAndFile(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	for _, args := range [][]string{{"import"}, {"import", "todotxt"}, {"import", "jira", "x.json"}} {
+		_, stderr, err := run(args)
+		if err == nil || !strings.Contains(stderr, importUsage) {
+			t.Fatalf("expected usage error for %v, got err=%v stderr=%q", args, err, stderr)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"taskgraph/internal/project"
+)
+
+const viewUsage = "usage: tg view <name> [list or graph flags]"
+
+// runView runs a view saved in .taskgraph/config.yml as the tg list or tg
+// graph command it stands for. Extra flags are passed through, so
+// "tg view bugs --all" works like adding --all to the saved command.
+func runView(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+		fmt.Fprintln(stderr, viewUsage)
+		return errors.New(viewUsage)
+	}
+	name := strings.TrimSpace(args[0])
+
+	views, err := readProjectViews(stderr)
+	if err != nil {
+		return err
+	}
+	for _, v := range views {
+		if v.Name != name {
+			continue
+		}
+		cmdArgs := append(viewArgs(v), args[1:]...)
+		if cmdArgs[0] == "list" {
+			return runList(cmdArgs, stdout, stderr)
+		}
+		return runGraph(cmdArgs[1:], stdout, stderr)
+	}
+
+	msg := fmt.Sprintf("unknown view %q", name)
+	if len(views) > 0 {
+		names := make([]string, 0, len(views))
+		for _, v := range views {
+			names = append(names, v.Name)
+		}
+		msg += " (defined: " + strings.Join(names, ", ") + ")"
+	}
+	fmt.Fprintln(stderr, msg)
+	return errors.New(msg)
+}
+
+// runViews lists the saved views with the command each one runs.
+func runViews(stdout io.Writer, stderr io.Writer) error {
+	views, err := readProjectViews(stderr)
+	if err != nil {
+		return err
+	}
+	if len(views) == 0 {
+		fmt.Fprintln(stdout, "No views defined. Add views: to .taskgraph/config.yml.")
+		return nil
+	}
+	for _, v := range views {
+		fmt.Fprintf(stdout, "%-20s  tg %s\n", v.Name, formatCommandArgs(viewArgs(v)))
+	}
+	return nil
+}
+
+func readProjectViews(stderr io.Writer) ([]project.View, error) {
+	cwd, err := effectiveCWD()
+	if err != nil {
+		return nil, err
+	}
+	root, found, err := project.FindTaskgraphRoot(cwd)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		fmt.Fprintln(stderr, "No .taskgraph found. Run `tg init` or `tg add \"task text\"`.")
+		return nil, errors.New("not initialized")
+	}
+	return project.ReadViews(root)
+}
+
+// viewArgs translates a view into tg list or tg graph arguments.
+func viewArgs(v project.View) []string {
+	var args []string
+	switch v.Format {
+	case "", "list":
+		args = []string{"list"}
+		if v.Sort != "" {
+			args = append(args, "--sort", v.Sort)
+		}
+	default:
+		args = []string{"graph"}
+		if v.Format != "graph" {
+			args = append(args, "--format", v.Format)
+		}
+		if v.Depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(v.Depth))
+		}
+		if v.MaxChildren > 0 {
+			args = append(args, "--max-children", strconv.Itoa(v.MaxChildren))
+		}
+	}
+	if v.All {
+		args = append(args, "--all")
+	}
+	where := v.Filter
+	if args[0] == "list" {
+		for _, label := range v.Labels {
+			args = append(args, "--label", label)
+		}
+	} else if len(v.Labels) > 0 {
+		// tg graph has no --label, so labels join the filter instead.
+		terms := make([]string, 0, len(v.Labels)+1)
+		if where != "" {
+			terms = append(terms, "("+where+")")
+		}
+		for _, label := range v.Labels {
+			terms = append(terms, "label:"+label)
+		}
+		where = strings.Join(terms, " ")
+	}
+	if where != "" {
+		args = append(args, "--where", where)
+	}
+	return args
+}
+
+// formatCommandArgs quotes arguments that contain spaces or #.
+func formatCommandArgs(args []string) string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " #\"") {
+			arg = strconv.Quote(arg)
+		}
+		out[i] = arg
+	}
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestViewRunsSavedQueries(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "config.yml"), strings.Join([]string{
+		"issue-prefix: tg",
+		"views:",
+		"  bugs:",
+		"    filter: type:bug",
+		"    sort: title",
+		"  launch:",
+		"    filter: under:tg-ep1",
+		"    format: mermaid",
+		"    depth: 2",
+	}, "\n")+"\n")
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), strings.Join([]string{
+		"- [ ] [tg-ep1] Launch #t-epic",
+		"  - [ ] [tg-b02] Zoom breaks #t-bug",
+		"- [ ] [tg-b01] Avatar missing #t-bug",
+		"- [x] [tg-b03] Old crash #t-bug",
+	}, "\n")+"\n")
+	if _, stderr, err := run([]string{"index"}); err != nil {
+		t.Fatalf("index returned err: %v stderr=%q", err, stderr)
+	}
+
+	stdout, stderr, err := run([]string{"views"})
+	if err != nil {
+		t.Fatalf("views returned err: %v stderr=%q", err, stderr)
+	}
+	if !strings.Contains(stdout, "tg list --sort title --where type:bug") || !strings.Contains(stdout, "tg graph --format mermaid --depth 2 --where under:tg-ep1") {
+		t.Fatalf("unexpected views output:\n%s", stdout)
+	}
+
+	stdout, stderr, err = run([]string{"view", "bugs"})
+	if err != nil {
+		t.Fatalf("view returned err: %v stderr=%q", err, stderr)
+	}
+	if strings.Index(stdout, "Avatar") > strings.Index(stdout, "Zoom") || strings.Contains(stdout, "Old crash") {
+		t.Fatalf("unexpected view output:\n%s", stdout)
+	}
+
+	stdout, _, err = run([]string{"view", "bugs", "--all"})
+	if err != nil || !strings.Contains(stdout, "Old crash") {
+		t.Fatalf("expected --all to pass through, err=%v output:\n%s", err, stdout)
+	}
+
+	stdout, _, err = run([]string{"view", "launch"})
+	if err != nil || !strings.HasPrefix(stdout, "graph") || !strings.Contains(stdout, "Zoom breaks") || strings.Contains(stdout, "Avatar") {
+		t.Fatalf("unexpected graph view, err=%v output:\n%s", err, stdout)
+	}
+
+	_, stderr, err = run([]string{"view", "nope"})
+	if err == nil || !strings.Contains(stderr, "defined: bugs, launch") {
+		t.Fatalf("expected unknown view error, got err=%v stderr=%q", err, stderr)
+	}
+}
@@ -0,0 +1,436 @@
+// Package doctor checks task files and the index for mistakes that tg would
+// otherwise skip over silently, and repairs the mechanical ones.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/project"
+	"taskgraph/internal/tasks"
+)
+
+// Severity grades a Problem. Errors make tg doctor exit non-zero.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Problem is one finding. Line is 0 for problems that concern a whole file.
+type Problem struct {
+	Path       string
+	Line       int
+	Severity   Severity
+	Message    string
+	Suggestion string
+	// Fixable reports whether Fix can repair the problem on its own.
+	Fixable bool
+
+	fix fixKind
+	// replacement is the corrected line for fixReplace.
+	replacement string
+}
+
+type fixKind int
+
+const (
+	fixNone fixKind = iota
+	fixReplace
+	fixDelete
+	fixReindex
+)
+
+func (p Problem) String() string {
+	loc := p.Path
+	if p.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", p.Path, p.Line)
+	}
+	out := fmt.Sprintf("%s: %s: %s", loc, p.Severity, p.Message)
+	if p.Suggestion != "" {
+		out += " (fix: " + p.Suggestion + ")"
+	}
+	return out
+}
+
+var (
+	fencePattern = regexp.MustCompile("^\\s*(```|~~~)")
+	// looseCheckboxPattern matches anything that looks like a checkbox: any
+	// list marker, optional spaces around it and an x or blank inside.
+	looseCheckboxPattern = regexp.MustCompile(`^([ \t]*)([-*+])([ \t]*)\[([ \t]*[xX]?[ \t]*)\]([ \t]*)(.*)$`)
+)
+
+// Check inspects the markdown files under root and the index built from
+// them. Problems are ordered by path and line.
+func Check(root string) ([]Problem, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, errors.New("root is required")
+	}
+	allowedTypes, err := project.ReadAllowedIssueTypes(root)
+	if err != nil {
+		return nil, err
+	}
+	allowed := map[string]bool{}
+	for _, t := range allowedTypes {
+		allowed[t] = true
+	}
+	registry, err := project.ReadLabelRegistry(root)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := indexer.SourceFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	type occurrence struct {
+		path string
+		line int
+		text string
+		// hasChildren is set when the next line is indented further, so
+		// deleting the line would re-parent those children.
+		hasChildren bool
+	}
+	ids := map[string][]occurrence{}
+	var idOrder []string
+	for _, absPath := range files {
+		if strings.ToLower(filepath.Ext(absPath)) != ".md" {
+			continue
+		}
+		rel, err := filepath.Rel(root, absPath)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(string(content), "\n")
+		inFence := false
+		for i, line := range lines {
+			lineNo := i + 1
+			if fencePattern.MatchString(line) {
+				inFence = !inFence
+				continue
+			}
+			if inFence {
+				continue
+			}
+			if p, ok := checkCheckbox(rel, lineNo, line); ok {
+				problems = append(problems, p)
+				continue
+			}
+			task, ok := tasks.ParseTask(line)
+			if !ok {
+				continue
+			}
+			problems = append(problems, checkTypes(rel, lineNo, task, allowed)...)
+			problems = append(problems, checkLabels(rel, lineNo, task, registry)...)
+			if task.ID != "" {
+				if _, seen := ids[task.ID]; !seen {
+					idOrder = append(idOrder, task.ID)
+				}
+				hasChildren := i+1 < len(lines) && indentOf(lines[i+1]) > indentOf(line) && strings.TrimSpace(lines[i+1]) != ""
+				ids[task.ID] = append(ids[task.ID], occurrence{path: rel, line: lineNo, text: line, hasChildren: hasChildren})
+			}
+		}
+	}
+
+	existing := map[string]bool{}
+	for _, id := range idOrder {
+		existing[id] = true
+	}
+	for _, id := range idOrder {
+		occ := ids[id]
+		if len(occ) < 2 {
+			continue
+		}
+		first := occ[0]
+		for _, o := range occ[1:] {
+			p := Problem{
+				Path:     o.path,
+				Line:     o.line,
+				Severity: Error,
+				Message:  fmt.Sprintf("duplicate task ID %s, first used at %s:%d", id, first.path, first.line),
+				Fixable:  true,
+			}
+			if strings.TrimSpace(o.text) == strings.TrimSpace(first.text) && !o.hasChildren {
+				p.Suggestion = "remove this repeated line"
+				p.fix = fixDelete
+			} else {
+				prefix, _, _ := strings.Cut(id, "-")
+				newID := tasks.NewTaskID(prefix, o.text, time.Now(), existing)
+				existing[newID] = true
+				p.Suggestion = "give this task a new ID, " + newID
+				p.fix = fixReplace
+				p.replacement = strings.Replace(o.text, "["+id+"]", "["+newID+"]", 1)
+			}
+			problems = append(problems, p)
+		}
+	}
+
+	indexProblems, err := checkIndex(root)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, indexProblems...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		return problems[i].Line < problems[j].Line
+	})
+	return problems, nil
+}
+
+// checkCheckbox reports checkboxes that the indexer or the task editing
+// commands do not read as tasks: a * or + marker, missing spaces, an empty []
+// or stray spaces around the x. It also warns about checkboxes with no text.
+func checkCheckbox(path string, lineNo int, line string) (Problem, bool) {
+	m := looseCheckboxPattern.FindStringSubmatch(line)
+	if m == nil {
+		return Problem{}, false
+	}
+	indent, marker, gap, box, after, text := m[1], m[2], m[3], m[4], m[5], m[6]
+	if strings.HasPrefix(text, "(") {
+		// A markdown link such as "- [x](url)" rather than a checkbox.
+		return Problem{}, false
+	}
+	if strings.TrimSpace(text) == "" {
+		return Problem{
+			Path:       path,
+			Line:       lineNo,
+			Severity:   Warning,
+			Message:    "checkbox has no task text",
+			Suggestion: "add a task title or remove the line",
+		}, true
+	}
+	if marker == "-" && gap != "" && after != "" && (box == " " || box == "x" || box == "X") {
+		return Problem{}, false
+	}
+	state := strings.TrimSpace(box)
+	if state == "" {
+		state = " "
+	}
+	fixed := indent + "- [" + state + "] " + text
+	return Problem{
+		Path:        path,
+		Line:        lineNo,
+		Severity:    Warning,
+		Message:     fmt.Sprintf("malformed checkbox %q, which tg does not treat as a task everywhere", strings.TrimSpace(line[:len(line)-len(text)])),
+		Suggestion:  fmt.Sprintf("write it as %q", strings.TrimSpace(fixed[:len(fixed)-len(text)])),
+		Fixable:     true,
+		fix:         fixReplace,
+		replacement: fixed,
+	}, true
+}
+
+// checkTypes reports tasks with several #t- types, which graph roots and
+// ResolveTaskType reject, and types the project does not allow.
+func checkTypes(path string, lineNo int, task tasks.Task, allowed map[string]bool) []Problem {
+	var types []string
+	for _, label := range task.Labels {
+		if t := strings.TrimPrefix(label, "t-"); t != label && t != "" && !containsString(types, t) {
+			types = append(types, t)
+		}
+	}
+	if len(types) > 1 {
+		return []Problem{{
+			Path:       path,
+			Line:       lineNo,
+			Severity:   Error,
+			Message:    "task has several types: " + strings.Join(types, ", "),
+			Suggestion: "keep a single #t- label",
+		}}
+	}
+	if len(types) == 1 && !allowed[types[0]] {
+		return []Problem{{
+			Path:       path,
+			Line:       lineNo,
+			Severity:   Warning,
+			Message:    fmt.Sprintf("unknown task type %q", types[0]),
+			Suggestion: "add it to issue-types in .taskgraph/config.yml or use a built-in type",
+		}}
+	}
+	return nil
+}
+
+// checkLabels reports labels missing from a strict label registry.
+func checkLabels(path string, lineNo int, task tasks.Task, registry project.LabelRegistry) []Problem {
+	var problems []Problem
+	for _, label := range task.Labels {
+		if registry.Allows(label) {
+			continue
+		}
+		suggestion := "add it under labels: in .taskgraph/config.yml"
+		if near := registry.Closest(label); near != "" {
+			suggestion = fmt.Sprintf("did you mean #%s? run tg labels merge %s %s", near, label, near)
+		}
+		problems = append(problems, Problem{
+			Path:       path,
+			Line:       lineNo,
+			Severity:   Error,
+			Message:    fmt.Sprintf("unregistered label #%s", label),
+			Suggestion: suggestion,
+		})
+	}
+	return problems
+}
+
+// checkIndex compares the stored index with a fresh scan and reports every
+// file whose nodes differ.
+func checkIndex(root string) ([]Problem, error) {
+	dbPath := filepath.Join(root, ".taskgraph", "taskgraph.db")
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return []Problem{{
+			Path:       ".taskgraph/taskgraph.db",
+			Severity:   Error,
+			Message:    "index has not been built",
+			Suggestion: "run tg index",
+			Fixable:    true,
+			fix:        fixReindex,
+		}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	fresh, err := indexer.BuildNodes(root)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := indexer.ReadGraphNodes(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	want, got := nodeSignatures(fresh), nodeSignatures(stored)
+	paths := map[string]bool{}
+	for p := range want {
+		paths[p] = true
+	}
+	for p := range got {
+		paths[p] = true
+	}
+	var problems []Problem
+	for p := range paths {
+		if want[p] == got[p] {
+			continue
+		}
+		msg := "index is out of date with this file"
+		if got[p] == "" {
+			msg = "file is missing from the index"
+		} else if want[p] == "" {
+			msg = "index lists a file that no longer has tasks or was removed"
+		}
+		problems = append(problems, Problem{
+			Path:       p,
+			Severity:   Error,
+			Message:    msg,
+			Suggestion: "run tg index",
+			Fixable:    true,
+			fix:        fixReindex,
+		})
+	}
+	return problems, nil
+}
+
+func nodeSignatures(nodes []indexer.Node) map[string]string {
+	byPath := map[string][]string{}
+	for _, n := range nodes {
+		// The index returns labels sorted, a fresh scan in line order.
+		labels := append([]string(nil), n.Labels...)
+		sort.Strings(labels)
+		byPath[n.Path] = append(byPath[n.Path], fmt.Sprintf("%s|%s|%d|%s|%s", n.ID, n.State, n.Line, n.Title, strings.Join(labels, ",")))
+	}
+	out := make(map[string]string, len(byPath))
+	for p, sigs := range byPath {
+		sort.Strings(sigs)
+		out[p] = strings.Join(sigs, "\n")
+	}
+	return out
+}
+
+// Fix repairs the fixable problems, rewriting each affected file once and
+// rebuilding the index afterwards. It returns how many problems it fixed.
+func Fix(root string, problems []Problem) (int, error) {
+	edits := map[string][]Problem{}
+	reindex := false
+	fixed := 0
+	for _, p := range problems {
+		switch p.fix {
+		case fixReplace, fixDelete:
+			edits[p.Path] = append(edits[p.Path], p)
+		case fixReindex:
+			reindex = true
+			fixed++
+		}
+	}
+
+	paths := make([]string, 0, len(edits))
+	for p := range edits {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, rel := range paths {
+		absPath := filepath.Join(root, filepath.FromSlash(rel))
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return fixed, err
+		}
+		lines := strings.Split(string(content), "\n")
+		fileEdits := edits[rel]
+		// Apply bottom-up so deletions do not shift the lines still to edit.
+		sort.SliceStable(fileEdits, func(i, j int) bool { return fileEdits[i].Line > fileEdits[j].Line })
+		for _, p := range fileEdits {
+			i := p.Line - 1
+			if i < 0 || i >= len(lines) {
+				continue
+			}
+			if p.fix == fixDelete {
+				lines = append(lines[:i], lines[i+1:]...)
+			} else {
+				lines[i] = p.replacement
+			}
+			fixed++
+		}
+		if err := os.WriteFile(absPath, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+			return fixed, err
+		}
+		reindex = true
+	}
+
+	if reindex {
+		nodes, err := indexer.BuildNodes(root)
+		if err != nil {
+			return fixed, err
+		}
+		if err := indexer.RebuildSQLite(filepath.Join(root, ".taskgraph", "taskgraph.db"), nodes); err != nil {
+			return fixed, err
+		}
+	}
+	return fixed, nil
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"taskgraph/internal/indexer"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
+
+func rebuildIndex(t *testing.T, root string) {
+	t.Helper()
+	nodes, err := indexer.BuildNodes(root)
+	if err != nil {
+		t.Fatalf("BuildNodes returned error: %v", err)
+	}
+	if err := indexer.RebuildSQLite(filepath.Join(root, ".taskgraph", "taskgraph.db"), nodes); err != nil {
+		t.Fatalf("RebuildSQLite returned error: %v", err)
+	}
+}
+
+func TestCheckReportsProblems(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	writeFile(t, filepath.Join(root, ".taskgraph", "issues.md"), strings.Join([]string{
+		"- [ ] [tg-aaa] Ship it",
+		"- [ ] [tg-bbb] Two types #t-bug #t-epic",
+		"- [ ] [tg-ccc] Odd type #t-spike",
+		"* [ ] Star marker",
+		"- [x]no space",
+		"```",
+		"- [] inside a code block",
+		"```",
+		"- [ ] [tg-aaa] Ship it",
+		"- [ ] [tg-aaa] Something else",
+		"- [link](https://example.com)",
+	}, "\n")+"\n")
+	rebuildIndex(t, root)
+
+	problems, err := Check(root)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	want := []string{
+		".taskgraph/issues.md:2: error: task has several types: bug, epic",
+		`.taskgraph/issues.md:3: warning: unknown task type "spike"`,
+		`.taskgraph/issues.md:4: warning: malformed checkbox "* [ ]"`,
+		`.taskgraph/issues.md:5: warning: malformed checkbox "- [x]"`,
+		".taskgraph/issues.md:9: error: duplicate task ID tg-aaa, first used at .taskgraph/issues.md:1 (fix: remove this repeated line)",
+		".taskgraph/issues.md:10: error: duplicate task ID tg-aaa, first used at .taskgraph/issues.md:1 (fix: give this task a new ID, tg-",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d problems, got:\n%s", len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Fatalf("problem %d:\n got %s\nwant prefix %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCheckReportsUnregisteredLabelsInStrictMode(t *testing.T) {
+	root := t.TempDir()
+	config := "issue-prefix: tg\nlabels:\n  flowershow:\n  area:\n"
+	writeFile(t, filepath.Join(root, ".taskgraph", "config.yml"), config)
+	writeFile(t, filepath.Join(root, ".taskgraph", "issues.md"), "- [ ] [tg-aaa] Stall #flowershwo #area/frontend #t-task\n- [ ] [tg-bbb] Misc #zzz\n")
+	rebuildIndex(t, root)
+
+	if problems, err := Check(root); err != nil || len(problems) != 0 {
+		t.Fatalf("expected no problems outside strict mode, got %v err=%v", problems, err)
+	}
+
+	writeFile(t, filepath.Join(root, ".taskgraph", "config.yml"), "labels-strict: true\n"+config)
+	problems, err := Check(root)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	want := []string{
+		".taskgraph/issues.md:1: error: unregistered label #flowershwo (fix: did you mean #flowershow? run tg labels merge flowershwo flowershow)",
+		".taskgraph/issues.md:2: error: unregistered label #zzz (fix: add it under labels: in .taskgraph/config.yml)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected problems:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestFixRepairsMechanicalProblems(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".taskgraph", "config.yml"), "issue-prefix: tg\n")
+	issues := filepath.Join(root, ".taskgraph", "issues.md")
+	writeFile(t, issues, strings.Join([]string{
+		"- [ ] [tg-aaa] Ship it",
+		"-[ ] Missing space",
+		"- [ ] [tg-aaa] Ship it",
+		"- [ ] [tg-aaa] Other task",
+		"- [ ] [tg-bbb] Two types #t-bug #t-epic",
+		"- [ ] Follow up on [tg-aaa]",
+	}, "\n")+"\n")
+
+	problems, err := Check(root)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if last := problems[len(problems)-1]; last.Path != ".taskgraph/taskgraph.db" || last.Message != "index has not been built" {
+		t.Fatalf("expected missing index to be reported, got %+v", problems)
+	}
+	fixed, err := Fix(root, problems)
+	if err != nil {
+		t.Fatalf("Fix returned error: %v", err)
+	}
+	if fixed != 4 {
+		t.Fatalf("expected 4 fixes, got %d", fixed)
+	}
+
+	b, err := os.ReadFile(issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if len(lines) != 6 || lines[1] != "- [ ] Missing space" || !strings.HasSuffix(lines[2], "] Other task") || strings.Contains(lines[2], "tg-aaa") || lines[4] != "- [ ] Follow up on [tg-aaa]" {
+		t.Fatalf("unexpected fixed file:\n%s", b)
+	}
+
+	problems, err = Check(root)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Fixable || !strings.Contains(problems[0].Message, "several types") {
+		t.Fatalf("expected only the unfixable type problem to remain, got %+v", problems)
+	}
+}
+
+func TestCheckReportsStaleIndex(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".taskgraph", "issues.md"), "- [ ] [tg-aaa] Ship it\n")
+	writeFile(t, filepath.Join(root, "notes.md"), "- [ ] Write notes\n")
+	rebuildIndex(t, root)
+
+	writeFile(t, filepath.Join(root, "notes.md"), "- [x] Write notes\n")
+	writeFile(t, filepath.Join(root, "plan.md"), "- [ ] Plan\n")
+
+	problems, err := Check(root)
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(problems) != 2 ||
+		problems[0].String() != "notes.md: error: index is out of date with this file (fix: run tg index)" ||
+		problems[1].String() != "plan.md: error: file is missing from the index (fix: run tg index)" {
+		t.Fatalf("unexpected problems: %+v", problems)
+	}
+}
@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"taskgraph/internal/tasks"
+)
+
+// CodeSource is the Node.Source of TODO/FIXME/HACK comments found in code.
+const CodeSource = "code"
+
+var (
+	codeCommentPattern = regexp.MustCompile(`(?://+|#+|--|/\*+|^\s*\*|;+|<!--)\s*(TODO|FIXME|HACK)(?:\(([^)]*)\))?(?::|\s|$)\s*(.*?)\s*(?:\*/|-->)?\s*$`)
+	taskMentionPattern = regexp.MustCompile(`\b[a-z0-9]+-[0-9a-z]{3,8}\b`)
+)
+
+// codeExtensions lists the languages scanned for comments when scan-code is
+// enabled.
+var codeExtensions = map[string]bool{
+	".go": true, ".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".cs": true,
+	".java": true, ".kt": true, ".scala": true, ".swift": true, ".m": true, ".rs": true, ".dart": true,
+	".js": true, ".jsx": true, ".mjs": true, ".cjs": true, ".ts": true, ".tsx": true, ".vue": true, ".svelte": true,
+	".py": true, ".rb": true, ".php": true, ".pl": true, ".lua": true, ".r": true, ".ex": true, ".exs": true,
+	".sh": true, ".bash": true, ".zsh": true, ".sql": true, ".css": true, ".scss": true, ".html": true,
+}
+
+func isCodePath(path string) bool {
+	return codeExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// codeParser indexes TODO, FIXME and HACK comments as open checklist nodes
+// under their file. Files without such comments produce no nodes.
+type codeParser struct{}
+
+func (codeParser) Parse(content, relPath, source string, sourceMTimeUnix int64) []Node {
+	o := newOutline(relPath, source, sourceMTimeUnix)
+	var nodes []Node
+	for i, line := range strings.Split(content, "\n") {
+		m := codeCommentPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		title := m[1]
+		if m[2] != "" {
+			title += "(" + m[2] + ")"
+		}
+		if m[3] != "" {
+			title += ": " + m[3]
+		}
+		if len(nodes) == 0 {
+			nodes = append(nodes, o.file)
+		}
+		lineNo := i + 1
+		nodes = append(nodes, o.node("checklist", title, "open", lineNo, o.file.ID, []string{title}, []string{strings.ToLower(m[1])}))
+	}
+	return nodes
+}
+
+// linkCodeRefs records on each code comment node the IDs of existing tasks
+// it mentions, such as tg-abc in "TODO(tg-abc): ...".
+func linkCodeRefs(nodes []Node) {
+	known := map[string]bool{}
+	for _, n := range nodes {
+		if n.Kind == "checklist" && n.Source != CodeSource {
+			if id := tasks.ExtractTaskID(n.Title); id != "" {
+				known[id] = true
+			}
+		}
+	}
+	for i, n := range nodes {
+		if n.Source != CodeSource || n.Kind != "checklist" {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, id := range taskMentionPattern.FindAllString(n.Title, -1) {
+			if known[id] && !seen[id] {
+				seen[id] = true
+				nodes[i].Refs = append(nodes[i].Refs, id)
+			}
+		}
+	}
+}
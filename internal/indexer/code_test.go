@@ -0,0 +1,73 @@
+package indexer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeParserFindsCommentMarkers(t *testing.T) {
+	content := "package x\n\n// TODO(tg-a1b2): handle retries\nfunc f() {} // FIXME leaks\n/* HACK: skip cache */\nvar todo = \"TODOS later\"\n# TODO\n"
+	nodes := codeParser{}.Parse(content, "pkg/x.go", CodeSource, 0)
+
+	var got []string
+	for _, n := range nodes[1:] {
+		got = append(got, n.Title)
+		if n.ParentID != nodes[0].ID || n.State != "open" || n.Kind != "checklist" {
+			t.Fatalf("unexpected node: %+v", n)
+		}
+	}
+	want := []string{"TODO(tg-a1b2): handle retries", "FIXME: leaks", "HACK: skip cache", "TODO"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected titles: %q", got)
+	}
+	if nodes[2].Line != 4 || !reflect.DeepEqual(nodes[2].Labels, []string{"fixme"}) {
+		t.Fatalf("unexpected FIXME node: %+v", nodes[2])
+	}
+	if nodes := (codeParser{}).Parse("package x\n", "y.go", CodeSource, 0); len(nodes) != 0 {
+		t.Fatalf("expected no nodes for files without markers, got %d", len(nodes))
+	}
+}
+
+func TestBuildNodesScansCodeOnlyWhenEnabled(t *testing.T) {
+	root := t.TempDir()
+	mustMkdirAll(t, filepath.Join(root, ".taskgraph"))
+	mustWrite(t, filepath.Join(root, ".taskgraph", "issues.md"), "- [ ] [tg-a1b2] Retry uploads\n")
+	mustMkdirAll(t, filepath.Join(root, "vendor"))
+	mustWrite(t, filepath.Join(root, "vendor", "dep.go"), "// TODO: not ours\n")
+	mustWrite(t, filepath.Join(root, "upload.py"), "# TODO(tg-a1b2): retry on 503\n# FIXME mentions tg-zzzz which does not exist\n")
+
+	nodes, err := BuildNodes(root)
+	if err != nil {
+		t.Fatalf("BuildNodes returned error: %v", err)
+	}
+	assertNoNodePath(t, nodes, "upload.py")
+
+	mustWrite(t, filepath.Join(root, ".taskgraph", "config.yml"), "issue-prefix: tg\nscan-code: true\n")
+	nodes, err = BuildNodes(root)
+	if err != nil {
+		t.Fatalf("BuildNodes returned error: %v", err)
+	}
+	assertNoNodePath(t, nodes, "vendor/dep.go")
+	assertHasChecklist(t, nodes, "upload.py", "TODO(tg-a1b2): retry on 503", "open")
+	for _, n := range nodes {
+		if n.Path == "upload.py" && n.Kind == "file" && len(n.Labels) != 0 {
+			t.Fatalf("code files must not be treated as projects: %v", n.Labels)
+		}
+	}
+
+	dbPath := filepath.Join(root, ".taskgraph", "taskgraph.db")
+	if err := RebuildSQLite(dbPath, nodes); err != nil {
+		t.Fatalf("RebuildSQLite returned error: %v", err)
+	}
+	refs, err := ReadRefNodes(dbPath, "tg-a1b2")
+	if err != nil {
+		t.Fatalf("ReadRefNodes returned error: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Path != "upload.py" || refs[0].Line != 1 {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+	if refs, _ := ReadRefNodes(dbPath, "tg-zzzz"); len(refs) != 0 {
+		t.Fatalf("expected unknown IDs not to be linked, got %+v", refs)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strings"
 
+	"taskgraph/internal/project"
 	"taskgraph/internal/tasks"
 )
 
@@ -21,37 +22,33 @@ var (
 
 // Node is one indexed markdown element.
 type Node struct {
-	ID              string
-	Kind            string
-	Title           string
-	State           string
-	Path            string
-	Line            int
-	ParentID        string
-	Context         string
-	SearchText      string
-	Source          string
-	SourceMTimeUnix int64
-	Labels          []string
+	ID              string   `json:"id"`
+	Kind            string   `json:"kind"`
+	Title           string   `json:"title"`
+	State           string   `json:"state"`
+	Path            string   `json:"path"`
+	Line            int      `json:"line"`
+	ParentID        string   `json:"parent_id,omitempty"`
+	Context         string   `json:"context"`
+	SearchText      string   `json:"-"`
+	Source          string   `json:"source"`
+	SourceMTimeUnix int64    `json:"source_mtime_unix"`
+	Labels          []string `json:"labels,omitempty"`
+	// Refs lists the task IDs a code comment mentions.
+	Refs []string `json:"refs,omitempty"`
 }
 
-// BuildNodes scans the root directory for markdown files and returns indexed nodes.
+// BuildNodes scans the root directory for source files and returns indexed nodes.
 func BuildNodes(root string) ([]Node, error) {
 	if strings.TrimSpace(root) == "" {
 		return nil, fmt.Errorf("root is required")
 	}
 
-	files, err := discoverMarkdownFiles(root)
+	files, err := SourceFiles(root)
 	if err != nil {
 		return nil, err
 	}
 
-	tasksPath := filepath.Join(root, ".taskgraph", "issues.md")
-	if _, err := os.Stat(tasksPath); err == nil {
-		files = appendUnique(files, tasksPath)
-	}
-	sort.Strings(files)
-
 	var nodes []Node
 	for _, absPath := range files {
 		rel, err := filepath.Rel(root, absPath)
@@ -63,6 +60,12 @@ func BuildNodes(root string) ([]Node, error) {
 		if rel == ".taskgraph/issues.md" {
 			source = "tasks_md"
 		}
+		parser, ok := parserForPath(rel)
+		if !ok && isCodePath(rel) {
+			parser, source = codeParser{}, CodeSource
+		} else if !ok {
+			parser = markdownParser{}
+		}
 
 		content, err := os.ReadFile(absPath)
 		if err != nil {
@@ -72,16 +75,38 @@ func BuildNodes(root string) ([]Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		fileNodes := indexMarkdown(string(content), rel, source, info.ModTime().Unix())
+		fileNodes := parser.Parse(string(content), rel, source, info.ModTime().Unix())
 		if source == "scan" && len(fileNodes) > 0 && fileNodes[0].Kind == "file" {
 			fileNodes[0].Labels = append(fileNodes[0].Labels, tasks.TypeLabel("project"))
 		}
 		nodes = append(nodes, fileNodes...)
 	}
 
+	linkCodeRefs(nodes)
 	return nodes, nil
 }
 
+// SourceFiles returns the absolute paths of every file BuildNodes indexes,
+// sorted, including .taskgraph/issues.md when it exists and source code when
+// the project config sets scan-code: true.
+func SourceFiles(root string) ([]string, error) {
+	scanCode, err := project.ReadScanCode(root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := discoverSourceFiles(root, scanCode)
+	if err != nil {
+		return nil, err
+	}
+
+	tasksPath := filepath.Join(root, ".taskgraph", "issues.md")
+	if _, err := os.Stat(tasksPath); err == nil {
+		files = appendUnique(files, tasksPath)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 func FileNodeCount(nodes []Node) int {
 	count := 0
 	for _, n := range nodes {
@@ -92,7 +117,10 @@ func FileNodeCount(nodes []Node) int {
 	return count
 }
 
-func discoverMarkdownFiles(root string) ([]string, error) {
+// discoverSourceFiles finds every file with a registered source parser, and
+// source code when scanCode is set, skipping hidden directories,
+// node_modules and, for code, vendor.
+func discoverSourceFiles(root string, scanCode bool) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -103,12 +131,12 @@ func discoverMarkdownFiles(root string) ([]string, error) {
 		}
 		name := d.Name()
 		if d.IsDir() {
-			if name == "node_modules" || strings.HasPrefix(name, ".") {
+			if name == "node_modules" || strings.HasPrefix(name, ".") || (scanCode && name == "vendor") {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-		if strings.EqualFold(filepath.Ext(name), ".md") {
+		if _, ok := parserForPath(name); ok || (scanCode && isCodePath(name)) {
 			files = append(files, path)
 		}
 		return nil
@@ -128,11 +156,11 @@ func appendUnique(paths []string, p string) []string {
 	return append(paths, p)
 }
 
-func indexMarkdown(content, relPath, source string, sourceMTimeUnix int64) []Node {
+// newFileNode returns the root node every parser emits first for a file.
+func newFileNode(relPath, source string, sourceMTimeUnix int64) Node {
 	fileTitle := strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath))
-	fileID := buildNodeID(relPath, nil, 0, "file")
-	nodes := []Node{{
-		ID:              fileID,
+	return Node{
+		ID:              buildNodeID(relPath, nil, 0, "file"),
 		Kind:            "file",
 		Title:           fileTitle,
 		State:           "unknown",
@@ -143,7 +171,13 @@ func indexMarkdown(content, relPath, source string, sourceMTimeUnix int64) []Nod
 		SearchText:      normalizeSearch(fileTitle),
 		Source:          source,
 		SourceMTimeUnix: sourceMTimeUnix,
-	}}
+	}
+}
+
+func indexMarkdown(content, relPath, source string, sourceMTimeUnix int64) []Node {
+	file := newFileNode(relPath, source, sourceMTimeUnix)
+	fileTitle, fileID := file.Title, file.ID
+	nodes := []Node{file}
 
 	type headingEntry struct {
 		level int
@@ -158,10 +192,21 @@ func indexMarkdown(content, relPath, source string, sourceMTimeUnix int64) []Nod
 	var stack []headingEntry
 	var checklistStack []checklistEntry
 
+	// lastTask is the checklist node on the line above, which Logseq and Org
+	// SCHEDULED:/DEADLINE: lines attach to.
+	lastTask := -1
 	lines := strings.Split(content, "\n")
 	for i, line := range lines {
 		lineNo := i + 1
 
+		if lastTask >= 0 {
+			if markers, ok := planningMarkers(line); ok {
+				addTitleMarkers(&nodes[lastTask], markers)
+				continue
+			}
+		}
+		lastTask = -1
+
 		if m := headingPattern.FindStringSubmatch(line); len(m) == 3 {
 			level := len(m[1])
 			title := strings.TrimSpace(m[2])
@@ -197,14 +242,8 @@ func indexMarkdown(content, relPath, source string, sourceMTimeUnix int64) []Nod
 			continue
 		}
 
-		if m := checklistPattern.FindStringSubmatch(line); len(m) == 4 {
-			indent := leadingIndentWidth(m[1])
-			checked := strings.EqualFold(m[2], "x")
-			title := strings.TrimSpace(m[3])
-			state := "open"
-			if checked {
-				state = "closed"
-			}
+		if indentText, title, state, ok := matchChecklistItem(line); ok {
+			indent := leadingIndentWidth(indentText)
 
 			for len(checklistStack) > 0 && checklistStack[len(checklistStack)-1].indent >= indent {
 				checklistStack = checklistStack[:len(checklistStack)-1]
@@ -244,6 +283,7 @@ func indexMarkdown(content, relPath, source string, sourceMTimeUnix int64) []Nod
 				title:  title,
 				id:     id,
 			})
+			lastTask = len(nodes) - 1
 		}
 	}
 
@@ -203,3 +203,18 @@ func mustWrite(t *testing.T, path, content string) {
 		t.Fatalf("write failed: %v", err)
 	}
 }
+
+func TestBuildNodesIndexesOrgAndTaskPaperSources(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "plans.org"), "* TODO Ship\n")
+	mustWrite(t, filepath.Join(root, "home.taskpaper"), "- Mow lawn @done\n")
+	mustWrite(t, filepath.Join(root, "ignored.txt"), "- [ ] not a source\n")
+
+	nodes, err := BuildNodes(root)
+	if err != nil {
+		t.Fatalf("BuildNodes returned error: %v", err)
+	}
+	assertHasChecklist(t, nodes, "plans.org", "Ship", "open")
+	assertHasChecklist(t, nodes, "home.taskpaper", "Mow lawn", "closed")
+	assertNoNodePath(t, nodes, "ignored.txt")
+}
@@ -0,0 +1,70 @@
+package indexer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	logseqTaskPattern     = regexp.MustCompile(`^(\s*)[-*+]\s+(TODO|DOING|DONE|NOW|LATER|WAITING|WAIT|IN-PROGRESS|CANCELED|CANCELLED)\s+(.*\S)\s*$`)
+	logseqPriorityPattern = regexp.MustCompile(`\s*\[#([A-Ca-c])\]`)
+	planningLinePattern   = regexp.MustCompile(`^\s*(?:(?:SCHEDULED|DEADLINE):\s*<\d{4}-\d{2}-\d{2}[^>]*>\s*)+$`)
+	planningPattern       = regexp.MustCompile(`(SCHEDULED|DEADLINE):\s*<(\d{4}-\d{2}-\d{2})[^>]*>`)
+)
+
+// matchChecklistItem recognises "- [ ]" checkboxes and Logseq task blocks
+// ("- TODO", "- DOING", "- DONE", "- NOW", "- LATER", ...). It returns the
+// leading indent, the title and the open or closed state.
+func matchChecklistItem(line string) (indent, title, state string, ok bool) {
+	if m := checklistPattern.FindStringSubmatch(line); len(m) == 4 {
+		state = "open"
+		if strings.EqualFold(m[2], "x") {
+			state = "closed"
+		}
+		return m[1], strings.TrimSpace(m[3]), state, true
+	}
+	if m := logseqTaskPattern.FindStringSubmatch(line); m != nil {
+		state = "open"
+		switch m[2] {
+		case "DONE", "CANCELED", "CANCELLED":
+			state = "closed"
+		}
+		return m[1], logseqTitle(m[3]), state, true
+	}
+	return "", "", "", false
+}
+
+// logseqTitle replaces a Logseq [#A] priority with the matching tg marker at
+// the end of the title.
+func logseqTitle(text string) string {
+	m := logseqPriorityPattern.FindStringSubmatch(text)
+	if m == nil {
+		return strings.TrimSpace(text)
+	}
+	text = strings.Join(strings.Fields(strings.Replace(text, m[0], " ", 1)), " ")
+	return strings.TrimSpace(text + " " + orgPriorityMarker(m[1]))
+}
+
+// planningMarkers converts a SCHEDULED:/DEADLINE: line, as written by Logseq
+// and Org below a task, into ⏳ and 📅 markers.
+func planningMarkers(line string) ([]string, bool) {
+	if !planningLinePattern.MatchString(line) {
+		return nil, false
+	}
+	var markers []string
+	for _, m := range planningPattern.FindAllStringSubmatch(line, -1) {
+		marker := "⏳ "
+		if m[1] == "DEADLINE" {
+			marker = "📅 "
+		}
+		markers = append(markers, marker+m[2])
+	}
+	return markers, true
+}
+
+// addTitleMarkers appends markers to an already emitted node's title.
+func addTitleMarkers(node *Node, markers []string) {
+	extra := strings.Join(markers, " ")
+	node.Title = strings.TrimSpace(node.Title + " " + extra)
+	node.SearchText = normalizeSearch(node.SearchText + " " + extra)
+}
@@ -0,0 +1,55 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndexMarkdownRecognisesLogseqTaskBlocks(t *testing.T) {
+	content := "- TODO [#A] Write release notes #docs\n  SCHEDULED: <2026-10-05 Mon>\n  DEADLINE: <2026-10-09 Fri 17:00>\n\t- DOING Draft intro\n\t- DONE Collect changes\n- NOW Review PR\n- LATER Tidy wiki\n- CANCELED Old plan\n- Plain bullet\n- TODO\n"
+	nodes := indexMarkdown(content, "pages/release.md", "scan", 0)
+
+	type row struct {
+		Title, State, Parent string
+		Line                 int
+	}
+	titles := map[string]string{}
+	for _, n := range nodes {
+		titles[n.ID] = n.Title
+	}
+	var got []row
+	for _, n := range nodes {
+		if n.Kind == "checklist" {
+			got = append(got, row{n.Title, n.State, titles[n.ParentID], n.Line})
+		}
+	}
+	want := []row{
+		{"Write release notes #docs ⏫ ⏳ 2026-10-05 📅 2026-10-09", "open", "release", 1},
+		{"Draft intro", "open", "Write release notes #docs ⏫ ⏳ 2026-10-05 📅 2026-10-09", 4},
+		{"Collect changes", "closed", "Write release notes #docs ⏫ ⏳ 2026-10-05 📅 2026-10-09", 5},
+		{"Review PR", "open", "release", 6},
+		{"Tidy wiki", "open", "release", 7},
+		{"Old plan", "closed", "release", 8},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected checklist nodes:\n got %+v\nwant %+v", got, want)
+	}
+	if labels := nodes[1].Labels; !reflect.DeepEqual(labels, []string{"docs"}) {
+		t.Fatalf("unexpected labels: %v", labels)
+	}
+}
+
+func TestPlanningLinesOnlyAttachDirectlyBelowATask(t *testing.T) {
+	content := "- [ ] Ship\n\nSCHEDULED: <2026-10-05 Mon>\n"
+	nodes := indexMarkdown(content, "a.md", "scan", 0)
+	if nodes[1].Title != "Ship" {
+		t.Fatalf("expected detached planning line to be ignored, got %q", nodes[1].Title)
+	}
+}
+
+func TestOrgParserReadsPlanningLines(t *testing.T) {
+	nodes := orgParser{}.Parse("* TODO Pay rent\n  DEADLINE: <2026-11-01 Sun> SCHEDULED: <2026-10-28 Wed>\n", "home.org", "scan", 0)
+	if nodes[1].Title != "Pay rent 📅 2026-11-01 ⏳ 2026-10-28" {
+		t.Fatalf("unexpected title: %q", nodes[1].Title)
+	}
+}
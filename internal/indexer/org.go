@@ -0,0 +1,130 @@
+package indexer
+
+import (
+	"regexp"
+	"strings"
+
+	"taskgraph/internal/tasks"
+)
+
+var (
+	orgHeadlinePattern = regexp.MustCompile(`^(\*+)\s+(.*?)\s*$`)
+	orgTagsPattern     = regexp.MustCompile(`\s*:([\w@#%:]+):$`)
+	orgPriorityPattern = regexp.MustCompile(`^\[#([A-Za-z])\]\s*`)
+)
+
+// orgKeywords maps Org TODO keywords to checklist states.
+var orgKeywords = map[string]string{
+	"TODO":      "open",
+	"NEXT":      "open",
+	"STARTED":   "open",
+	"DOING":     "open",
+	"WAITING":   "open",
+	"DONE":      "closed",
+	"CANCELED":  "closed",
+	"CANCELLED": "closed",
+}
+
+// checkboxDepth places plain-list checkboxes below every headline level in
+// the outline stack.
+const checkboxDepth = 1000
+
+// orgParser indexes Org-mode files. Headlines with a TODO keyword become
+// checklist nodes, other headlines become headings, :tags: become labels and
+// "- [ ]" checkboxes nest under the headline above them.
+type orgParser struct{}
+
+func (orgParser) Parse(content, relPath, source string, sourceMTimeUnix int64) []Node {
+	o := newOutline(relPath, source, sourceMTimeUnix)
+	nodes := []Node{o.file}
+	var stack outlineStack
+	lastTask := -1
+
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		if lastTask >= 0 {
+			if markers, ok := planningMarkers(line); ok {
+				addTitleMarkers(&nodes[lastTask], markers)
+				continue
+			}
+		}
+		lastTask = -1
+
+		if m := orgHeadlinePattern.FindStringSubmatch(line); m != nil {
+			depth := len(m[1])
+			title, state, labels := parseOrgHeadline(m[2])
+			if title == "" {
+				// A bare "* TODO" has nothing to show or search for.
+				continue
+			}
+			parentID, pathBits := stack.enter(depth, o.file.ID)
+			pathBits = append(pathBits, title)
+			kind := "heading"
+			if state != "" {
+				kind = "checklist"
+			} else {
+				state = "unknown"
+			}
+			node := o.node(kind, title, state, lineNo, parentID, pathBits, labels)
+			nodes = append(nodes, node)
+			stack.push(depth, title, node.ID)
+			if kind == "checklist" {
+				lastTask = len(nodes) - 1
+			}
+			continue
+		}
+
+		if m := checklistPattern.FindStringSubmatch(line); len(m) == 4 {
+			depth := checkboxDepth + leadingIndentWidth(m[1])
+			title := strings.TrimSpace(m[3])
+			state := "open"
+			if strings.EqualFold(m[2], "x") {
+				state = "closed"
+			}
+			parentID, pathBits := stack.enter(depth, o.file.ID)
+			pathBits = append(pathBits, title)
+			node := o.node("checklist", title, state, lineNo, parentID, pathBits, tasks.ExtractLabels(title))
+			nodes = append(nodes, node)
+			stack.push(depth, title, node.ID)
+		}
+	}
+	return nodes
+}
+
+// parseOrgHeadline splits a headline into its title, checklist state (empty
+// for plain headings) and tag labels. A [#A] priority cookie becomes the
+// matching tg priority marker at the end of the title.
+func parseOrgHeadline(text string) (title, state string, labels []string) {
+	if keyword, rest, _ := strings.Cut(text, " "); orgKeywords[keyword] != "" {
+		state = orgKeywords[keyword]
+		text = rest
+	} else if orgKeywords[text] != "" {
+		return "", orgKeywords[text], nil
+	}
+	if m := orgTagsPattern.FindStringSubmatch(text); m != nil {
+		labels = tasks.MergeLabels(strings.Split(m[1], ":"))
+		text = text[:len(text)-len(m[0])]
+	}
+	text = strings.TrimSpace(text)
+	if m := orgPriorityPattern.FindStringSubmatch(text); m != nil {
+		text = strings.TrimSpace(text[len(m[0]):])
+		if marker := orgPriorityMarker(m[1]); marker != "" {
+			text = strings.TrimSpace(text + " " + marker)
+		}
+	}
+	return text, state, labels
+}
+
+// orgPriorityMarker maps Org and Logseq [#A]/[#B]/[#C] priority cookies to
+// the high, medium and low tg priority markers.
+func orgPriorityMarker(letter string) string {
+	switch strings.ToUpper(letter) {
+	case "A":
+		return tasks.PriorityHigh
+	case "B":
+		return tasks.PriorityMedium
+	case "C":
+		return tasks.PriorityLow
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrgParserMapsHeadlinesAndCheckboxes(t *testing.T) {
+	content := "#+TITLE: Plans\n* Launch :work:\n** TODO [#A] Write copy :web:Docs:\n   - [ ] outline\n   - [X] draft\n** DONE Pick domain\n** TODO\n* Notes\n"
+	nodes := orgParser{}.Parse(content, "plans.org", "scan", 0)
+
+	type row struct {
+		Kind, Title, State, Parent string
+		Labels                     []string
+	}
+	titles := map[string]string{}
+	for _, n := range nodes {
+		titles[n.ID] = n.Title
+	}
+	var got []row
+	for _, n := range nodes[1:] {
+		got = append(got, row{n.Kind, n.Title, n.State, titles[n.ParentID], n.Labels})
+	}
+	want := []row{
+		{"heading", "Launch", "unknown", "plans", []string{"work"}},
+		{"checklist", "Write copy ⏫", "open", "Launch", []string{"web", "docs"}},
+		{"checklist", "outline", "open", "Write copy ⏫", []string{}},
+		{"checklist", "draft", "closed", "Write copy ⏫", []string{}},
+		{"checklist", "Pick domain", "closed", "Launch", nil},
+		{"heading", "Notes", "unknown", "plans", nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected nodes:\n got %+v\nwant %+v", got, want)
+	}
+	if nodes[2].Line != 3 || nodes[2].Context != "plans > Launch > Write copy ⏫" {
+		t.Fatalf("unexpected line/context: %d %q", nodes[2].Line, nodes[2].Context)
+	}
+}
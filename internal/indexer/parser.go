@@ -0,0 +1,90 @@
+package indexer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SourceParser turns the content of one source file into index nodes. Parse
+// returns the file node first, followed by headings and checklist items in
+// document order, so every format lands in the same tables.
+type SourceParser interface {
+	Parse(content, relPath, source string, sourceMTimeUnix int64) []Node
+}
+
+// sourceParsers maps lower-case file extensions to their parser.
+var sourceParsers = map[string]SourceParser{
+	".md":        markdownParser{},
+	".org":       orgParser{},
+	".taskpaper": taskPaperParser{},
+}
+
+func parserForPath(path string) (SourceParser, bool) {
+	parser, ok := sourceParsers[strings.ToLower(filepath.Ext(path))]
+	return parser, ok
+}
+
+type markdownParser struct{}
+
+func (markdownParser) Parse(content, relPath, source string, sourceMTimeUnix int64) []Node {
+	return indexMarkdown(content, relPath, source, sourceMTimeUnix)
+}
+
+// outline holds the per-file fields shared by every node a parser emits.
+type outline struct {
+	file Node
+}
+
+func newOutline(relPath, source string, sourceMTimeUnix int64) outline {
+	return outline{file: newFileNode(relPath, source, sourceMTimeUnix)}
+}
+
+// node builds a heading or checklist node. pathBits lists the titles from the
+// top of the file down to and including this node.
+func (o outline) node(kind, title, state string, line int, parentID string, pathBits []string, labels []string) Node {
+	context := buildContext(o.file.Title, pathBits)
+	return Node{
+		ID:              buildNodeID(o.file.Path, pathBits, line, kind),
+		Kind:            kind,
+		Title:           title,
+		State:           state,
+		Path:            o.file.Path,
+		Line:            line,
+		ParentID:        parentID,
+		Context:         context,
+		SearchText:      normalizeSearch(context + " " + title),
+		Source:          o.file.Source,
+		SourceMTimeUnix: o.file.SourceMTimeUnix,
+		Labels:          labels,
+	}
+}
+
+// outlineEntry is an open ancestor while walking an indented or leveled
+// outline.
+type outlineEntry struct {
+	depth int
+	title string
+	id    string
+}
+
+// outlineStack tracks the open ancestors of the current line.
+type outlineStack []outlineEntry
+
+// enter pops entries at depth or deeper and returns the parent ID and path
+// bits for a new entry at depth.
+func (s *outlineStack) enter(depth int, fileID string) (string, []string) {
+	for len(*s) > 0 && (*s)[len(*s)-1].depth >= depth {
+		*s = (*s)[:len(*s)-1]
+	}
+	parentID := fileID
+	var pathBits []string
+	for _, entry := range *s {
+		pathBits = append(pathBits, entry.title)
+		parentID = entry.id
+	}
+	return parentID, pathBits
+}
+
+func (s *outlineStack) push(depth int, title, id string) {
+	*s = append(*s, outlineEntry{depth: depth, title: title, id: id})
+}
@@ -0,0 +1,351 @@
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"taskgraph/internal/tasks"
+)
+
+// Query is a compiled tg query: a SQL condition over index_nodes columns
+// and the arguments it binds.
+//
+// The source language is a list of terms, AND-ed unless joined by OR, that
+// may be grouped with parentheses and negated with NOT or a leading "-":
+//
+//	type:bug state:open path:docs/** -label:someday under:tg-abc has:children
+//
+// Bare words and #labels are accepted as shorthands for text: and label:.
+type Query struct {
+	Where string
+	Args  []any
+
+	// hasKind records whether the query constrains kind; when it does not,
+	// ReadQueryNodes only returns checklist tasks.
+	hasKind bool
+}
+
+// queryFields lists the field names ParseQuery accepts, for error messages.
+var queryFields = []string{"type", "label", "state", "kind", "path", "under", "has", "id", "source", "text"}
+
+// ParseQuery compiles a query string into a SQL condition.
+func ParseQuery(src string) (Query, error) {
+	toks, err := lexQuery(src)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(toks) == 0 {
+		return Query{}, fmt.Errorf("empty query")
+	}
+	p := &queryParser{toks: toks}
+	where, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	if p.pos < len(p.toks) {
+		return Query{}, fmt.Errorf("unexpected %q in query", p.toks[p.pos].text)
+	}
+	return Query{Where: where, Args: p.args, hasKind: p.hasKind}, nil
+}
+
+// ReadQueryNodes returns the nodes matching q, in path and line order.
+func ReadQueryNodes(dbPath string, q Query) ([]Node, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
+
+	query := `
+SELECT id, kind, title, state, path, line, COALESCE(parent_id, ''), context, search_text, source, source_mtime_unix
+FROM index_nodes
+WHERE (` + q.Where + `)`
+	if !q.hasKind {
+		query += " AND kind = 'checklist'"
+	}
+	query += " ORDER BY path ASC, line ASC"
+
+	rows, err := db.Query(query, q.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("query nodes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Node{}
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(
+			&n.ID,
+			&n.Kind,
+			&n.Title,
+			&n.State,
+			&n.Path,
+			&n.Line,
+			&n.ParentID,
+			&n.Context,
+			&n.SearchText,
+			&n.Source,
+			&n.SourceMTimeUnix,
+		); err != nil {
+			return nil, fmt.Errorf("scan query node: %w", err)
+		}
+		out = append(out, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate query nodes: %w", err)
+	}
+
+	labelsByNodeID, err := readLabelsByNodeID(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Labels = labelsByNodeID[out[i].ID]
+	}
+	return out, nil
+}
+
+// queryToken is one lexed word. quoted marks words that start with a quote,
+// which are always searched as text rather than read as fields or keywords.
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// lexQuery splits src into words, parentheses and double-quoted strings. A
+// quote may also start inside a word, as in text:"two words".
+func lexQuery(src string) ([]queryToken, error) {
+	var toks []queryToken
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		switch r := rs[i]; {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+		case r == '(' || r == ')':
+			toks = append(toks, queryToken{text: string(r)})
+			i++
+		default:
+			var b strings.Builder
+			quoted := false
+			for i < len(rs) && !strings.ContainsRune(" \t\n()", rs[i]) {
+				if rs[i] != '"' {
+					b.WriteRune(rs[i])
+					i++
+					continue
+				}
+				end := i + 1
+				for end < len(rs) && rs[end] != '"' {
+					end++
+				}
+				if end == len(rs) {
+					return nil, fmt.Errorf("unterminated quote in query")
+				}
+				quoted = quoted || b.Len() == 0
+				b.WriteString(string(rs[i+1 : end]))
+				i = end + 1
+			}
+			toks = append(toks, queryToken{text: b.String(), quoted: quoted})
+		}
+	}
+	return toks, nil
+}
+
+type queryParser struct {
+	toks    []queryToken
+	pos     int
+	args    []any
+	hasKind bool
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.toks) {
+		return queryToken{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *queryParser) peekKeyword(word string) bool {
+	tok, ok := p.peek()
+	return ok && !tok.quoted && tok.text == word
+}
+
+func (p *queryParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{left}
+	for p.peekKeyword("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, right)
+	}
+	if len(parts) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", nil
+}
+
+func (p *queryParser) parseAnd() (string, error) {
+	var parts []string
+	for {
+		if p.peekKeyword("AND") {
+			p.pos++
+		}
+		tok, ok := p.peek()
+		if !ok || (!tok.quoted && (tok.text == ")" || tok.text == "OR")) {
+			break
+		}
+		part, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		if tok, ok := p.peek(); ok {
+			return "", fmt.Errorf("unexpected %q in query", tok.text)
+		}
+		return "", fmt.Errorf("query ends early")
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, " AND ") + ")", nil
+}
+
+func (p *queryParser) parseUnary() (string, error) {
+	tok, _ := p.peek()
+	switch {
+	case !tok.quoted && (tok.text == "NOT" || tok.text == "-"):
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	case !tok.quoted && strings.HasPrefix(tok.text, "-"):
+		p.toks[p.pos].text = strings.TrimPrefix(tok.text, "-")
+		inner, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	case !tok.quoted && tok.text == "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if !p.peekKeyword(")") {
+			return "", fmt.Errorf("missing ) in query")
+		}
+		p.pos++
+		return inner, nil
+	}
+	p.pos++
+	return p.term(tok)
+}
+
+// term compiles one field:value (or bare word) into a SQL condition.
+func (p *queryParser) term(tok queryToken) (string, error) {
+	text := tok.text
+	if strings.HasPrefix(text, "#") && !tok.quoted {
+		text = "label:" + text[1:]
+	}
+	field, value, ok := strings.Cut(text, ":")
+	if !ok || tok.quoted {
+		field, value = "text", text
+	}
+	field = strings.ToLower(field)
+	if value == "" {
+		return "", fmt.Errorf("query field %s: needs a value", field)
+	}
+
+	switch field {
+	case "type":
+		label := tasks.TypeLabel(value)
+		if label == "" {
+			return "", fmt.Errorf("invalid type %q in query", value)
+		}
+		return p.labelCond(label), nil
+	case "label":
+		labels := tasks.MergeLabels([]string{value})
+		if len(labels) == 0 {
+			return "", fmt.Errorf("invalid label %q in query", value)
+		}
+		return p.labelCond(labels[0]), nil
+	case "state":
+		value = strings.ToLower(value)
+		if value != "open" && value != "closed" {
+			return "", fmt.Errorf("state must be open or closed, got %q", value)
+		}
+		return p.bind("state = ?", value), nil
+	case "kind":
+		value = strings.ToLower(value)
+		switch value {
+		case "file", "heading", "checklist":
+		case "task":
+			value = "checklist"
+		default:
+			return "", fmt.Errorf("kind must be file, heading or checklist, got %q", value)
+		}
+		p.hasKind = true
+		return p.bind("kind = ?", value), nil
+	case "path":
+		return p.pathCond(value), nil
+	case "under":
+		return p.bind(`id IN (
+	WITH RECURSIVE sub(id) AS (
+		SELECT c.id FROM index_nodes a JOIN index_nodes c ON c.parent_id = a.id
+		WHERE a.id = ? OR (a.kind = 'checklist' AND a.source != 'code' AND a.title LIKE ? ESCAPE '\')
+		UNION
+		SELECT c.id FROM index_nodes c JOIN sub ON c.parent_id = sub.id
+	)
+	SELECT id FROM sub
+)`, value, "%["+escapeLike(value)+"]%"), nil
+	case "has":
+		switch strings.ToLower(value) {
+		case "children":
+			return "EXISTS (SELECT 1 FROM index_nodes c WHERE c.parent_id = index_nodes.id AND c.kind = 'checklist')", nil
+		case "open-children":
+			return "EXISTS (SELECT 1 FROM index_nodes c WHERE c.parent_id = index_nodes.id AND c.kind = 'checklist' AND c.state = 'open')", nil
+		case "labels":
+			return "EXISTS (SELECT 1 FROM index_node_labels l WHERE l.node_id = index_nodes.id)", nil
+		case "refs":
+			return "EXISTS (SELECT 1 FROM index_node_refs r WHERE r.node_id = index_nodes.id)", nil
+		}
+		return "", fmt.Errorf("has: must be children, open-children, labels or refs, got %q", value)
+	case "id":
+		return p.bind(`title LIKE ? ESCAPE '\'`, "%["+escapeLike(value)+"]%"), nil
+	case "source":
+		return p.bind("source = ?", value), nil
+	case "text":
+		return p.bind(`search_text LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(value))+"%"), nil
+	}
+	return "", fmt.Errorf("unknown query field %q (want one of %s)", field, strings.Join(queryFields, ", "))
+}
+
+func (p *queryParser) bind(cond string, args ...any) string {
+	p.args = append(p.args, args...)
+	return cond
+}
+
+func (p *queryParser) labelCond(label string) string {
+	cond, args := labelMatchCond(label)
+	return p.bind("EXISTS (SELECT 1 FROM index_node_labels l WHERE l.node_id = index_nodes.id AND "+cond+")", args...)
+}
+
+// pathCond matches path as a glob, where * and ** both cross directories. A
+// path without wildcards matches that file or anything below that directory.
+func (p *queryParser) pathCond(value string) string {
+	value = strings.TrimPrefix(value, "./")
+	if strings.ContainsAny(value, "*?[") {
+		return p.bind("path GLOB ?", strings.ReplaceAll(value, "**", "*"))
+	}
+	return p.bind("(path = ? OR path GLOB ?)", value, strings.TrimSuffix(value, "/")+"/*")
+}
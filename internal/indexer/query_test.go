@@ -0,0 +1,81 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadQueryNodes(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	roadmap := strings.Join([]string{
+		"# Roadmap",
+		"",
+		"- [ ] [tg-ep1] Launch #t-epic",
+		"  - [ ] [tg-b01] Fix login #t-bug",
+		"  - [x] [tg-b02] Fix logout #t-bug",
+		"- [ ] [tg-ep2] Rewrite docs #t-epic",
+		"  - [x] [tg-d01] Old page",
+		"- [ ] [tg-b03] Flaky upload #t-bug #someday",
+	}, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(root, "docs", "roadmap.md"), []byte(roadmap), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.md"), []byte("- [ ] [tg-b04] Crash on start #t-bug\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := BuildNodes(root)
+	if err != nil {
+		t.Fatalf("BuildNodes returned error: %v", err)
+	}
+	dbPath := filepath.Join(root, "taskgraph.db")
+	if err := RebuildSQLite(dbPath, nodes); err != nil {
+		t.Fatalf("RebuildSQLite returned error: %v", err)
+	}
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"type:bug state:open path:docs/** -label:someday", []string{"tg-b01"}},
+		{"type:bug state:open", []string{"tg-b01", "tg-b03", "tg-b04"}},
+		{"type:epic -has:open-children", []string{"tg-ep2"}},
+		{"under:tg-ep1", []string{"tg-b01", "tg-b02"}},
+		{"#someday OR (path:notes.md AND crash)", []string{"tg-b03", "tg-b04"}},
+		{"NOT type:bug", []string{"tg-ep1", "tg-ep2", "tg-d01"}},
+		{`"fix log"`, []string{"tg-b01", "tg-b02"}},
+		{"id:tg-d01", []string{"tg-d01"}},
+		{"kind:heading", []string{"Roadmap"}},
+	}
+	for _, tc := range cases {
+		q, err := ParseQuery(tc.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) returned error: %v", tc.query, err)
+		}
+		got, err := ReadQueryNodes(dbPath, q)
+		if err != nil {
+			t.Fatalf("ReadQueryNodes(%q) returned error: %v", tc.query, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%q: expected %d nodes, got %+v", tc.query, len(tc.want), got)
+		}
+		for i, want := range tc.want {
+			if !strings.Contains(got[i].Title, want) {
+				t.Fatalf("%q: expected node %d to contain %q, got %q", tc.query, i, want, got[i].Title)
+			}
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, src := range []string{"", "owner:me", "state:maybe", "(type:bug", `text:"open`, "type:bug OR", "has:wings"} {
+		if _, err := ParseQuery(src); err == nil {
+			t.Fatalf("expected error for %q", src)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -35,6 +36,13 @@ CREATE TABLE IF NOT EXISTS index_node_labels (
 
 CREATE INDEX IF NOT EXISTS idx_node_labels_node_id ON index_node_labels(node_id);
 CREATE INDEX IF NOT EXISTS idx_node_labels_label ON index_node_labels(label);
+
+CREATE TABLE IF NOT EXISTS index_node_refs (
+    node_id TEXT NOT NULL,
+    task_id TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_refs_task_id ON index_node_refs(task_id);
 `
 
 func RebuildSQLite(dbPath string, nodes []Node) error {
@@ -67,6 +75,9 @@ func RebuildSQLite(dbPath string, nodes []Node) error {
 	if _, err := tx.Exec("DELETE FROM index_node_labels"); err != nil {
 		return fmt.Errorf("clear index_node_labels: %w", err)
 	}
+	if _, err := tx.Exec("DELETE FROM index_node_refs"); err != nil {
+		return fmt.Errorf("clear index_node_refs: %w", err)
+	}
 
 	stmt, err := tx.Prepare(`
 INSERT INTO index_nodes
@@ -90,6 +101,17 @@ VALUES
 	}
 	defer labelStmt.Close()
 
+	refStmt, err := tx.Prepare(`
+INSERT INTO index_node_refs
+	(node_id, task_id)
+VALUES
+	(?, ?)
+`)
+	if err != nil {
+		return fmt.Errorf("prepare ref insert: %w", err)
+	}
+	defer refStmt.Close()
+
 	for _, n := range nodes {
 		var parent any
 		if n.ParentID != "" {
@@ -115,6 +137,11 @@ VALUES
 				return fmt.Errorf("insert node label %s/%s: %w", n.ID, label, err)
 			}
 		}
+		for _, ref := range n.Refs {
+			if _, err := refStmt.Exec(n.ID, ref); err != nil {
+				return fmt.Errorf("insert node ref %s/%s: %w", n.ID, ref, err)
+			}
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -140,19 +167,10 @@ WHERE kind = 'checklist'
 		query += " AND state = ?"
 		args = append(args, "open")
 	}
-	if len(requiredLabels) > 0 {
-		query += `
- AND id IN (
-	SELECT node_id
-	FROM index_node_labels
-	WHERE label IN (` + placeholders(len(requiredLabels)) + `)
-	GROUP BY node_id
-	HAVING COUNT(DISTINCT label) = ?
-)`
-		for _, label := range requiredLabels {
-			args = append(args, label)
-		}
-		args = append(args, len(requiredLabels))
+	for _, label := range requiredLabels {
+		cond, condArgs := labelMatchCond(label)
+		query += " AND EXISTS (SELECT 1 FROM index_node_labels l WHERE l.node_id = index_nodes.id AND " + cond + ")"
+		args = append(args, condArgs...)
 	}
 	query += " ORDER BY source_mtime_unix DESC, path ASC, line ASC"
 
@@ -185,6 +203,14 @@ WHERE kind = 'checklist'
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate checklist nodes: %w", err)
 	}
+
+	labelsByNodeID, err := readLabelsByNodeID(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Labels = labelsByNodeID[out[i].ID]
+	}
 	return out, nil
 }
 
@@ -239,12 +265,60 @@ ORDER BY path ASC, line ASC, id ASC
 	return out, nil
 }
 
+// ReadRefNodes returns the code comment nodes that mention taskID, in path
+// and line order.
+func ReadRefNodes(dbPath, taskID string) ([]Node, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+SELECT n.id, n.kind, n.title, n.state, n.path, n.line, COALESCE(n.parent_id, ''), n.context, n.search_text, n.source, n.source_mtime_unix
+FROM index_nodes n
+JOIN index_node_refs r ON r.node_id = n.id
+WHERE r.task_id = ?
+ORDER BY n.path ASC, n.line ASC
+`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("query ref nodes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Node{}
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(
+			&n.ID,
+			&n.Kind,
+			&n.Title,
+			&n.State,
+			&n.Path,
+			&n.Line,
+			&n.ParentID,
+			&n.Context,
+			&n.SearchText,
+			&n.Source,
+			&n.SourceMTimeUnix,
+		); err != nil {
+			return nil, fmt.Errorf("scan ref node: %w", err)
+		}
+		n.Refs = []string{taskID}
+		out = append(out, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate ref nodes: %w", err)
+	}
+	return out, nil
+}
+
 type ProjectNode struct {
-	ID              string
-	Title           string
-	Path            string
-	OpenTaskCount   int
-	SourceMTimeUnix int64
+	ID              string `json:"id"`
+	Title           string `json:"title"`
+	Path            string `json:"path"`
+	OpenTaskCount   int    `json:"open_task_count"`
+	SourceMTimeUnix int64  `json:"source_mtime_unix"`
 }
 
 func ReadProjectNodes(dbPath string) ([]ProjectNode, error) {
@@ -287,6 +361,136 @@ ORDER BY f.source_mtime_unix DESC, f.path ASC
 	return out, nil
 }
 
+// SearchNodes returns nodes whose search text contains every whitespace
+// separated term in query, checklist items first.
+func SearchNodes(dbPath string, query string, includeClosed bool, limit int) ([]Node, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return []Node{}, nil
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
+
+	q := `
+SELECT id, kind, title, state, path, line, COALESCE(parent_id, ''), context, search_text, source, source_mtime_unix
+FROM index_nodes
+WHERE 1 = 1
+`
+	var args []any
+	for _, term := range terms {
+		q += " AND search_text LIKE ? ESCAPE '\\'"
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+	if !includeClosed {
+		q += " AND state != 'closed'"
+	}
+	q += " ORDER BY kind = 'checklist' DESC, path ASC, line ASC"
+	if limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query search nodes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Node{}
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(
+			&n.ID,
+			&n.Kind,
+			&n.Title,
+			&n.State,
+			&n.Path,
+			&n.Line,
+			&n.ParentID,
+			&n.Context,
+			&n.SearchText,
+			&n.Source,
+			&n.SourceMTimeUnix,
+		); err != nil {
+			return nil, fmt.Errorf("scan search node: %w", err)
+		}
+		out = append(out, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate search nodes: %w", err)
+	}
+
+	labelsByNodeID, err := readLabelsByNodeID(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Labels = labelsByNodeID[out[i].ID]
+	}
+	return out, nil
+}
+
+// LabelCount is one label with the number of nodes carrying it.
+type LabelCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// ReadLabelCounts returns every indexed label, most used first.
+func ReadLabelCounts(dbPath string) ([]LabelCount, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+SELECT label, COUNT(DISTINCT node_id) AS uses
+FROM index_node_labels
+GROUP BY label
+ORDER BY uses DESC, label ASC
+`)
+	if err != nil {
+		return nil, fmt.Errorf("query label counts: %w", err)
+	}
+	defer rows.Close()
+
+	out := []LabelCount{}
+	for rows.Next() {
+		var lc LabelCount
+		if err := rows.Scan(&lc.Label, &lc.Count); err != nil {
+			return nil, fmt.Errorf("scan label count: %w", err)
+		}
+		out = append(out, lc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate label counts: %w", err)
+	}
+	return out, nil
+}
+
+// labelMatchCond matches l.label against label the way tasks.LabelMatches
+// does: the label itself, any label below it, and for "type" every type
+// label.
+func labelMatchCond(label string) (string, []any) {
+	cond := `(l.label = ? OR l.label LIKE ? ESCAPE '\'`
+	args := []any{label, escapeLike(label) + "/%"}
+	if label == "type" {
+		cond += ` OR l.label LIKE 't-%'`
+	}
+	return cond + ")", args
+}
+
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
+
 func readLabelsByNodeID(db *sql.DB) (map[string][]string, error) {
 	rows, err := db.Query(`
 SELECT node_id, label
@@ -313,17 +517,6 @@ ORDER BY node_id ASC, label ASC
 	return out, nil
 }
 
-func placeholders(n int) string {
-	if n <= 0 {
-		return ""
-	}
-	out := "?"
-	for i := 1; i < n; i++ {
-		out += ", ?"
-	}
-	return out
-}
-
 func ensureColumn(db *sql.DB, table, column, columnDef string) error {
 	rows, err := db.Query("PRAGMA table_info(" + table + ")")
 	if err != nil {
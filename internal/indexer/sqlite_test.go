@@ -127,6 +127,33 @@ func TestReadChecklistNodesFiltersByLabels(t *testing.T) {
 	}
 }
 
+func TestReadChecklistNodesMatchesLabelDescendants(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "taskgraph.db")
+	nodes := []Node{
+		{ID: "a", Kind: "checklist", Title: "nav #area/frontend", State: "open", Path: "a.md", Line: 1, Source: "scan", Labels: []string{"area/frontend"}},
+		{ID: "b", Kind: "checklist", Title: "db #area/backend/sql", State: "open", Path: "a.md", Line: 2, Source: "scan", Labels: []string{"area/backend/sql"}},
+		{ID: "c", Kind: "checklist", Title: "misc #areas #t-bug", State: "open", Path: "a.md", Line: 3, Source: "scan", Labels: []string{"areas", "t-bug"}},
+	}
+	if err := RebuildSQLite(dbPath, nodes); err != nil {
+		t.Fatalf("RebuildSQLite returned error: %v", err)
+	}
+
+	for filter, want := range map[string][]string{"area": {"a", "b"}, "area/backend": {"b"}, "type": {"c"}} {
+		got, err := ReadChecklistNodes(dbPath, false, []string{filter})
+		if err != nil {
+			t.Fatalf("ReadChecklistNodes returned error: %v", err)
+		}
+		var ids []string
+		for _, n := range got {
+			ids = append(ids, n.ID)
+		}
+		slices.Sort(ids)
+		if !slices.Equal(ids, want) {
+			t.Fatalf("label %s: got %v want %v", filter, ids, want)
+		}
+	}
+}
+
 func TestReadGraphNodesReturnsTreeMetadataAndLabels(t *testing.T) {
 	root := t.TempDir()
 	dbPath := filepath.Join(root, "taskgraph.db")
@@ -395,3 +422,64 @@ func openSQLiteDB(t *testing.T, dbPath string) *sql.DB {
 	}
 	return db
 }
+
+func TestSearchNodesMatchesAllTermsAndSkipsClosed(t *testing.T) {
+	root := t.TempDir()
+	dbPath := filepath.Join(root, "taskgraph.db")
+
+	nodes := []Node{
+		{ID: "h", Kind: "heading", Title: "Launch plan", State: "unknown", Path: "plan.md", Line: 1, Context: "plan > Launch plan", SearchText: "plan > launch plan launch plan", Source: "scan"},
+		{ID: "a", Kind: "checklist", Title: "Write launch post #blog", State: "open", Path: "plan.md", Line: 2, Context: "plan > Launch plan > Write launch post", SearchText: "plan > launch plan > write launch post write launch post #blog", Source: "scan", Labels: []string{"blog"}},
+		{ID: "b", Kind: "checklist", Title: "Draft launch email", State: "closed", Path: "plan.md", Line: 3, Context: "plan > Launch plan > Draft launch email", SearchText: "plan > launch plan > draft launch email draft launch email", Source: "scan"},
+		{ID: "c", Kind: "checklist", Title: "100% done_ish", State: "open", Path: "misc.md", Line: 1, Context: "misc", SearchText: "misc 100% done_ish", Source: "scan"},
+	}
+	if err := RebuildSQLite(dbPath, nodes); err != nil {
+		t.Fatalf("RebuildSQLite returned error: %v", err)
+	}
+
+	got, err := SearchNodes(dbPath, "Launch POST", false, 0)
+	if err != nil {
+		t.Fatalf("SearchNodes returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "a" || !slices.Equal(got[0].Labels, []string{"blog"}) {
+		t.Fatalf("unexpected search results: %#v", got)
+	}
+
+	got, err = SearchNodes(dbPath, "launch", true, 0)
+	if err != nil {
+		t.Fatalf("SearchNodes returned error: %v", err)
+	}
+	if len(got) != 3 || got[0].Kind != "checklist" || got[2].ID != "h" {
+		t.Fatalf("expected checklist items before headings, got %#v", got)
+	}
+
+	got, err = SearchNodes(dbPath, "0%", false, 0)
+	if err != nil {
+		t.Fatalf("SearchNodes returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "c" {
+		t.Fatalf("expected literal %% match, got %#v", got)
+	}
+}
+
+func TestReadLabelCounts(t *testing.T) {
+	root := t.TempDir()
+	dbPath := filepath.Join(root, "taskgraph.db")
+
+	nodes := []Node{
+		{ID: "a", Kind: "checklist", Title: "a", State: "open", Path: "a.md", Line: 1, Context: "a", SearchText: "a", Source: "scan", Labels: []string{"home", "errands"}},
+		{ID: "b", Kind: "checklist", Title: "b", State: "open", Path: "a.md", Line: 2, Context: "b", SearchText: "b", Source: "scan", Labels: []string{"home"}},
+	}
+	if err := RebuildSQLite(dbPath, nodes); err != nil {
+		t.Fatalf("RebuildSQLite returned error: %v", err)
+	}
+
+	got, err := ReadLabelCounts(dbPath)
+	if err != nil {
+		t.Fatalf("ReadLabelCounts returned error: %v", err)
+	}
+	want := []LabelCount{{Label: "home", Count: 2}, {Label: "errands", Count: 1}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %#v want %#v", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package indexer
+
+import (
+	"regexp"
+	"strings"
+
+	"taskgraph/internal/tasks"
+)
+
+var (
+	taskPaperTagPattern  = regexp.MustCompile(`(^|\s)@([\w.-]+)(\([^)]*\))?`)
+	taskPaperDatePattern = regexp.MustCompile(`^\(\s*(\d{4}-\d{2}-\d{2})`)
+)
+
+// taskPaperDateMarkers maps dated TaskPaper tags to the tg marker for them.
+var taskPaperDateMarkers = map[string]string{
+	"due":   "📅",
+	"start": "🛫",
+}
+
+// taskPaperParser indexes TaskPaper files. "- " lines are tasks, closed when
+// tagged @done; lines ending in ":" are projects and become headings. Nesting
+// follows indentation, and other @tags become labels.
+type taskPaperParser struct{}
+
+func (taskPaperParser) Parse(content, relPath, source string, sourceMTimeUnix int64) []Node {
+	o := newOutline(relPath, source, sourceMTimeUnix)
+	nodes := []Node{o.file}
+	var stack outlineStack
+
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		text := strings.TrimSpace(line)
+		if text == "" {
+			continue
+		}
+		depth := leadingIndentWidth(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+
+		var node Node
+		switch {
+		case strings.HasPrefix(text, "- "):
+			title, dates, done, labels := parseTaskPaperTags(strings.TrimPrefix(text, "- "))
+			title = strings.TrimSpace(strings.Join(append([]string{title}, dates...), " "))
+			if title == "" {
+				continue
+			}
+			state := "open"
+			if done {
+				state = "closed"
+			}
+			parentID, pathBits := stack.enter(depth, o.file.ID)
+			node = o.node("checklist", title, state, lineNo, parentID, append(pathBits, title), labels)
+		default:
+			title, _, _, _ := parseTaskPaperTags(text)
+			if !strings.HasSuffix(title, ":") {
+				// Notes belong to the item above and are not indexed.
+				continue
+			}
+			title = strings.TrimSpace(strings.TrimSuffix(title, ":"))
+			parentID, pathBits := stack.enter(depth, o.file.ID)
+			node = o.node("heading", title, "unknown", lineNo, parentID, append(pathBits, title), nil)
+		}
+		nodes = append(nodes, node)
+		stack.push(depth, node.Title, node.ID)
+	}
+	return nodes
+}
+
+// parseTaskPaperTags strips @tags from text, reporting @done separately from
+// the remaining tags, which are returned as labels. @due(date) and
+// @start(date) are returned as 📅 and 🛫 markers; with a value that is not a
+// date they stay in the title as written.
+func parseTaskPaperTags(text string) (title string, dates []string, done bool, labels []string) {
+	var names []string
+	text = taskPaperTagPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		m := taskPaperTagPattern.FindStringSubmatch(tag)
+		name := strings.ToLower(m[2])
+		if marker, ok := taskPaperDateMarkers[name]; ok && m[3] != "" {
+			if date := taskPaperDatePattern.FindStringSubmatch(m[3]); date != nil {
+				dates = append(dates, marker+" "+date[1])
+				return m[1]
+			}
+			return tag
+		}
+		if name == "done" {
+			done = true
+		} else {
+			names = append(names, m[2])
+		}
+		return m[1]
+	})
+	title = strings.Join(strings.Fields(text), " ")
+	return title, dates, done, tasks.MergeLabels(names)
+}
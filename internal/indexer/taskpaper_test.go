@@ -0,0 +1,36 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTaskPaperParserMapsProjectsTasksAndTags(t *testing.T) {
+	content := "Home:\n\t- Fix fence @weekend @priority(high)\n\t\t- Buy nails @done(2026-10-01)\n\tA note about the fence\n\tGarden: @outside\n\t\t- Plant bulbs @due(2026-01-02) @start(2025-12-20 09:00)\n\t\t- Mulch @due(soon)\n- Loose task\n- @done\n"
+	nodes := taskPaperParser{}.Parse(content, "todo.taskpaper", "scan", 0)
+
+	type row struct {
+		Kind, Title, State, Parent string
+		Labels                     []string
+	}
+	titles := map[string]string{}
+	for _, n := range nodes {
+		titles[n.ID] = n.Title
+	}
+	var got []row
+	for _, n := range nodes[1:] {
+		got = append(got, row{n.Kind, n.Title, n.State, titles[n.ParentID], n.Labels})
+	}
+	want := []row{
+		{"heading", "Home", "unknown", "todo", nil},
+		{"checklist", "Fix fence", "open", "Home", []string{"weekend", "priority"}},
+		{"checklist", "Buy nails", "closed", "Fix fence", []string{}},
+		{"heading", "Garden", "unknown", "Home", nil},
+		{"checklist", "Plant bulbs 📅 2026-01-02 🛫 2025-12-20", "open", "Garden", []string{}},
+		{"checklist", "Mulch @due(soon)", "open", "Garden", []string{}},
+		{"checklist", "Loose task", "open", "todo", []string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected nodes:\n got %+v\nwant %+v", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"taskgraph/internal/tasks"
+)
+
+// Milestones and assignees become namespaced labels next to the issue's own
+// labels.
+const (
+	milestoneLabelPrefix = "milestone-"
+	assigneeLabelPrefix  = "assignee-"
+)
+
+var githubRefPattern = regexp.MustCompile(`\[gh:([0-9]+)\]`)
+
+// GitHubSummary reports counts for one GitHub Issues import run. Imported
+// counts new issues; Updated and Unchanged count issues found from an earlier
+// import. The mapped-field counts say how many new issues carried each field.
+type GitHubSummary struct {
+	Imported       int
+	SkippedInvalid int
+	Updated        int
+	Unchanged      int
+	Labeled        int
+	Milestones     int
+	Assigned       int
+}
+
+// githubIssue is one entry of `gh issue list --json
+// number,title,state,labels,milestone,assignees,createdAt,closedAt`.
+type githubIssue struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	State     string `json:"state"`
+	CreatedAt string `json:"createdAt"`
+	ClosedAt  string `json:"closedAt"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+}
+
+// ImportGitHubIssues imports a `gh issue list --json` dump into the inbox
+// under root. Issues already present as [gh:NUMBER] in any indexed markdown
+// file get their title and state updated in place; only new issues are added.
+func ImportGitHubIssues(root, inputPath string) (GitHubSummary, error) {
+	changes, summary, err := PlanGitHubIssuesImport(root, inputPath)
+	if err != nil {
+		return summary, err
+	}
+	for _, change := range changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Path))
+		if err := os.WriteFile(path, []byte(change.After), 0o644); err != nil {
+			return summary, fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	return summary, nil
+}
+
+// PlanGitHubIssuesImport works out the file changes ImportGitHubIssues would
+// make without writing anything.
+func PlanGitHubIssuesImport(root, inputPath string) ([]FileChange, GitHubSummary, error) {
+	summary := GitHubSummary{}
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, summary, fmt.Errorf("open input %s: %w", inputPath, err)
+	}
+	var issues []githubIssue
+	if err := json.Unmarshal(content, &issues); err != nil {
+		return nil, summary, fmt.Errorf("parse %s: %w", inputPath, err)
+	}
+	// gh lists newest first; import in issue order so re-runs append stably.
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Number < issues[j].Number
+	})
+
+	docs, err := loadMarkdown(root)
+	if err != nil {
+		return nil, summary, err
+	}
+
+	located := docs.locateRefs(githubRefPattern)
+	var fresh []string
+	for _, issue := range issues {
+		issue.Title = strings.Join(strings.Fields(issue.Title), " ")
+		if issue.Number <= 0 || issue.Title == "" {
+			summary.SkippedInvalid++
+			continue
+		}
+		number := strconv.Itoa(issue.Number)
+		closed := strings.EqualFold(issue.State, "closed")
+		at, ok := located[number]
+		if !ok {
+			fresh = append(fresh, formatGitHubIssue(issue, &summary))
+			summary.Imported++
+			continue
+		}
+		line := at.doc.lines[at.line]
+		updated := updateRefLine(line, "gh:"+number, issue.Title, closed)
+		if closed {
+			updated = addDoneNote(updated, githubDate(issue.ClosedAt))
+		}
+		if updated == line {
+			summary.Unchanged++
+			continue
+		}
+		at.doc.lines[at.line] = updated
+		summary.Updated++
+	}
+
+	if len(fresh) > 0 {
+		inbox := docs.get(filepath.Join(".taskgraph", "issues.md"))
+		inbox.lines = insertUnderHeadingPath(inbox.lines, nil, fresh)
+	}
+	return docs.changes(), summary, nil
+}
+
+func formatGitHubIssue(issue githubIssue, summary *GitHubSummary) string {
+	task := tasks.Task{
+		Done:    strings.EqualFold(issue.State, "closed"),
+		Text:    fmt.Sprintf("[gh:%d] %s", issue.Number, issue.Title),
+		Created: githubDate(issue.CreatedAt),
+	}
+	if task.Done {
+		task.Completed = githubDate(issue.ClosedAt)
+	}
+
+	var labels []string
+	for _, label := range issue.Labels {
+		labels = append(labels, slugLabel(label.Name))
+	}
+	if len(tasks.MergeLabels(labels)) > 0 {
+		summary.Labeled++
+	}
+	if issue.Milestone != nil {
+		if slug := slugLabel(issue.Milestone.Title); slug != "" {
+			labels = append(labels, milestoneLabelPrefix+slug)
+			summary.Milestones++
+		}
+	}
+	var assigned bool
+	for _, assignee := range issue.Assignees {
+		if slug := slugLabel(assignee.Login); slug != "" {
+			labels = append(labels, assigneeLabelPrefix+slug)
+			assigned = true
+		}
+	}
+	if assigned {
+		summary.Assigned++
+	}
+	task.Labels = tasks.MergeLabels(labels)
+	return task.Format()
+}
+
+// slugLabel turns free-form names such as "good first issue" or "v1.2" into
+// label-safe words by replacing separators with hyphens.
+func slugLabel(raw string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '-'
+	}, raw)
+	if labels := tasks.MergeLabels([]string{slug}); len(labels) == 1 {
+		return labels[0]
+	}
+	return ""
+}
+
+// githubDate returns the UTC calendar date of an RFC 3339 timestamp, or an
+// empty string when it does not parse.
+func githubDate(raw string) string {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
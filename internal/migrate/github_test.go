@@ -0,0 +1,70 @@
+package migrate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const githubDump = `[
+  {"number":12,"title":"Crash on start","state":"CLOSED","createdAt":"2026-09-01T10:00:00Z","closedAt":"2026-09-03T12:00:00Z",
+   "labels":[{"name":"bug"},{"name":"good first issue"}],"milestone":{"title":"v1.2"},"assignees":[{"login":"Sam-Dev"}]},
+  {"number":3,"title":"Add  dark mode","state":"OPEN","labels":[],"milestone":null,"assignees":[]},
+  {"number":0,"title":"broken"}
+]`
+
+func TestImportGitHubIssuesMapsFields(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] local task\n\n## Later\n- [ ] someday\n")
+	input := filepath.Join(dir, "issues.json")
+	mustWrite(t, input, githubDump)
+
+	summary, err := ImportGitHubIssues(dir, input)
+	if err != nil {
+		t.Fatalf("ImportGitHubIssues returned err: %v", err)
+	}
+	if summary.Imported != 2 || summary.SkippedInvalid != 1 || summary.Labeled != 1 || summary.Milestones != 1 || summary.Assigned != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	want := strings.Join([]string{
+		"- [ ] local task",
+		"- [ ] [gh:3] Add dark mode",
+		"- [x] ➕2026-09-01 [gh:12] Crash on start #bug #good-first-issue #milestone-v1-2 #assignee-sam-dev **✅2026-09-03**",
+		"",
+		"## Later",
+		"- [ ] someday",
+	}, "\n") + "\n"
+	if got := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md")); got != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportGitHubIssuesUpdatesKnownNumbers(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, "docs"))
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "")
+	mustWrite(t, filepath.Join(dir, "docs", "plan.md"), "- [ ] [gh:3] Add dark mode #ui\n")
+	input := filepath.Join(dir, "issues.json")
+	mustWrite(t, input, `[{"number":3,"title":"Add dark theme","state":"CLOSED","closedAt":"2026-10-04T09:00:00Z"}]`)
+
+	changes, summary, err := PlanGitHubIssuesImport(dir, input)
+	if err != nil {
+		t.Fatalf("PlanGitHubIssuesImport returned err: %v", err)
+	}
+	if summary.Updated != 1 || summary.Imported != 0 || len(changes) != 1 || changes[0].Path != "docs/plan.md" {
+		t.Fatalf("unexpected plan: summary=%+v changes=%+v", summary, changes)
+	}
+	if changes[0].After != "- [x] [gh:3] Add dark theme #ui **✅2026-10-04**\n" {
+		t.Fatalf("unexpected markdown: %q", changes[0].After)
+	}
+}
+
+func TestSlugLabel(t *testing.T) {
+	for raw, want := range map[string]string{"good first issue": "good-first-issue", "area: UI": "area-ui", "v1.2": "v1-2", "!!": ""} {
+		if got := slugLabel(raw); got != want {
+			t.Fatalf("slugLabel(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
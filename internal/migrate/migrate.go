@@ -6,51 +6,179 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/tasks"
 )
 
-// Summary reports migration counts for one import run.
+// Summary reports migration counts for one import run. Imported counts new
+// issues; Updated and Unchanged count issues found from an earlier import. The
+// mapped-field counts say how many new issues carried each Beads field.
 type Summary struct {
 	Imported         int
 	SkippedTombstone int
 	SkippedInvalid   int
+	Updated          int
+	Unchanged        int
+	Types            int
+	Priorities       int
+	Labeled          int
+	Descriptions     int
+	ParentLinks      int
+	Dependencies     int
+	SkippedLinks     int
+}
+
+type beadsDependency struct {
+	IssueID     string `json:"issue_id"`
+	DependsOnID string `json:"depends_on_id"`
+	Type        string `json:"type"`
 }
 
 type beadsIssue struct {
-	ID     string `json:"id"`
-	Title  string `json:"title"`
-	Status string `json:"status"`
+	ID           string            `json:"id"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Status       string            `json:"status"`
+	Priority     *int              `json:"priority"`
+	IssueType    string            `json:"issue_type"`
+	Labels       []string          `json:"labels"`
+	Dependencies []beadsDependency `json:"dependencies"`
+	ClosedAt     string            `json:"closed_at"`
+}
+
+var (
+	checklistLinePattern = regexp.MustCompile(`^\s*[-*+]\s+\[( |x|X)\]`)
+	beadsRefPattern      = regexp.MustCompile(`\[beads:([^\]\s]+)\]`)
+	doneNotePattern      = regexp.MustCompile(`\s*\*\*✅[^*]*\*\*\s*$`)
+)
+
+// trailingMarkers start the metadata that follows a task title.
+var trailingMarkers = []string{
+	tasks.PriorityHighest, tasks.PriorityHigh, tasks.PriorityMedium, tasks.PriorityLow, tasks.PriorityLowest,
+	tasks.DependsOnMarker, "➕", "📅", "⏳", "🛫", "✅",
+}
+
+// FileChange is the planned rewrite of one markdown file. Path is relative to
+// the project root.
+type FileChange struct {
+	Path   string
+	Before string
+	After  string
 }
 
-// ImportBeadsIssues imports ./.beads/issues.jsonl into ./.taskgraph/issues.md.
-func ImportBeadsIssues(cwd string) (Summary, error) {
+// ImportBeadsIssues imports .beads/issues.jsonl under root. Issues already
+// present as [beads:ID] in any indexed markdown file get their title and
+// status updated in place; only new issues are added.
+func ImportBeadsIssues(root string) (Summary, error) {
+	changes, summary, err := PlanBeadsImport(root)
+	if err != nil {
+		return summary, err
+	}
+	for _, change := range changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Path))
+		if err := os.WriteFile(path, []byte(change.After), 0o644); err != nil {
+			return summary, fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	return summary, nil
+}
+
+// PlanBeadsImport works out the file changes ImportBeadsIssues would make
+// without writing anything.
+func PlanBeadsImport(root string) ([]FileChange, Summary, error) {
 	summary := Summary{}
 
-	beadsDir := filepath.Join(cwd, ".beads")
-	taskgraphDir := filepath.Join(cwd, ".taskgraph")
+	inputPath, err := beadsInputPath(root)
+	if err != nil {
+		return nil, summary, err
+	}
+	issues, err := readBeadsIssues(inputPath, &summary)
+	if err != nil {
+		return nil, summary, err
+	}
+
+	docs, err := loadMarkdown(root)
+	if err != nil {
+		return nil, summary, err
+	}
+
+	// Update issues that were imported before, wherever they live now.
+	located := docs.locateRefs(beadsRefPattern)
+	var fresh []beadsIssue
+	for _, issue := range issues {
+		at, ok := located[issue.ID]
+		if !ok {
+			fresh = append(fresh, issue)
+			continue
+		}
+		updated := updateBeadsLine(at.doc.lines[at.line], issue)
+		if updated == at.doc.lines[at.line] {
+			summary.Unchanged++
+			continue
+		}
+		at.doc.lines[at.line] = updated
+		summary.Updated++
+	}
+
+	if err := docs.addBeadsIssues(located, fresh, &summary); err != nil {
+		return nil, summary, err
+	}
+	return docs.changes(), summary, nil
+}
+
+// beadsInputPath checks the project layout and returns the Beads JSONL path.
+func beadsInputPath(root string) (string, error) {
+	beadsDir := filepath.Join(root, ".beads")
+	taskgraphDir := filepath.Join(root, ".taskgraph")
 	if !dirExists(beadsDir) || !dirExists(taskgraphDir) {
-		return summary, fmt.Errorf("expected .beads and .taskgraph in current directory: %s", cwd)
+		return "", fmt.Errorf("expected .beads and .taskgraph in current directory or a parent: %s", root)
 	}
 
 	inputPath := filepath.Join(beadsDir, "issues.jsonl")
 	if _, err := os.Stat(inputPath); err != nil {
-		return summary, fmt.Errorf("missing input file %s: %w", inputPath, err)
+		return "", fmt.Errorf("missing input file %s: %w", inputPath, err)
 	}
+	return inputPath, nil
+}
 
-	issuesPath := filepath.Join(taskgraphDir, "issues.md")
-	in, err := os.Open(inputPath)
-	if err != nil {
-		return summary, fmt.Errorf("open input %s: %w", inputPath, err)
+// addBeadsIssues renders issues not yet in the markdown. Children of issues
+// already imported go under their parent; everything else is appended to the
+// inbox.
+func (d *markdownDocs) addBeadsIssues(located map[string]refLocation, fresh []beadsIssue, summary *Summary) error {
+	existing := make(map[string]bool, len(located))
+	for id := range located {
+		existing[id] = true
 	}
-	defer in.Close()
+	roots, under, parents := renderBeadsIssues(fresh, existing, summary)
+	for _, parent := range parents {
+		doc := located[parent].doc
+		lines, err := tasks.InsertUnderParent(doc.lines, "beads:"+parent, under[parent])
+		if err != nil {
+			return err
+		}
+		doc.lines = lines
+	}
+	inbox := d.get(filepath.Join(".taskgraph", "issues.md"))
+	inbox.lines = append(inbox.lines, roots...)
+	return nil
+}
 
-	out, err := os.OpenFile(issuesPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+// readBeadsIssues parses the JSONL export, dropping tombstones and issues
+// without an ID or title.
+func readBeadsIssues(inputPath string, summary *Summary) ([]beadsIssue, error) {
+	in, err := os.Open(inputPath)
 	if err != nil {
-		return summary, fmt.Errorf("open output %s: %w", issuesPath, err)
+		return nil, fmt.Errorf("open input %s: %w", inputPath, err)
 	}
-	defer out.Close()
+	defer in.Close()
 
+	var issues []beadsIssue
 	s := bufio.NewScanner(in)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
 	lineNo := 0
 	for s.Scan() {
 		lineNo++
@@ -61,7 +189,7 @@ func ImportBeadsIssues(cwd string) (Summary, error) {
 
 		var issue beadsIssue
 		if err := json.Unmarshal([]byte(line), &issue); err != nil {
-			return summary, fmt.Errorf("parse %s line %d: %w", inputPath, lineNo, err)
+			return nil, fmt.Errorf("parse %s line %d: %w", inputPath, lineNo, err)
 		}
 
 		issue.ID = strings.TrimSpace(issue.ID)
@@ -71,27 +199,185 @@ func ImportBeadsIssues(cwd string) (Summary, error) {
 			continue
 		}
 
-		status := strings.ToLower(strings.TrimSpace(issue.Status))
-		if status == "tombstone" {
+		if strings.ToLower(strings.TrimSpace(issue.Status)) == "tombstone" {
 			summary.SkippedTombstone++
 			continue
 		}
+		issues = append(issues, issue)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", inputPath, err)
+	}
+	return issues, nil
+}
+
+// renderBeadsIssues turns new issues into checklist lines. Parent-child links
+// nest children under their parent, blocking links become dependency
+// markers, and links to issues outside the import are counted as skipped.
+// Issues whose parent was imported earlier are returned in under, keyed by
+// that parent, with the parents listed in input order.
+func renderBeadsIssues(issues []beadsIssue, existing map[string]bool, summary *Summary) ([]string, map[string][]string, []string) {
+	known := make(map[string]bool, len(issues)+len(existing))
+	for id := range existing {
+		known[id] = true
+	}
+	for _, issue := range issues {
+		known[issue.ID] = true
+	}
 
-		checkbox := " "
-		if status == "closed" || status == "done" || status == "resolved" {
-			checkbox = "x"
+	parentOf := map[string]string{}
+	dependsOn := map[string][]string{}
+	for _, issue := range issues {
+		for _, dep := range issue.Dependencies {
+			if dep.IssueID != "" && dep.IssueID != issue.ID {
+				continue
+			}
+			target := strings.TrimSpace(dep.DependsOnID)
+			if !known[target] || target == issue.ID {
+				summary.SkippedLinks++
+				continue
+			}
+			switch strings.ToLower(strings.TrimSpace(dep.Type)) {
+			case "parent-child":
+				if _, ok := parentOf[issue.ID]; ok || createsCycle(parentOf, issue.ID, target) {
+					summary.SkippedLinks++
+					continue
+				}
+				parentOf[issue.ID] = target
+				summary.ParentLinks++
+			case "", "blocks":
+				dependsOn[issue.ID] = append(dependsOn[issue.ID], "beads:"+target)
+				summary.Dependencies++
+			default:
+				summary.SkippedLinks++
+			}
 		}
+	}
 
-		if _, err := fmt.Fprintf(out, "- [%s] [beads:%s] %s\n", checkbox, issue.ID, issue.Title); err != nil {
-			return summary, fmt.Errorf("append to %s: %w", issuesPath, err)
+	children := map[string][]beadsIssue{}
+	var roots []beadsIssue
+	for _, issue := range issues {
+		parent, ok := parentOf[issue.ID]
+		switch {
+		case !ok:
+			roots = append(roots, issue)
+		case !existing[parent]:
+			children[parent] = append(children[parent], issue)
 		}
+	}
+
+	var lines []string
+	var emit func(issue beadsIssue, indent string)
+	emit = func(issue beadsIssue, indent string) {
+		lines = append(lines, indent+formatBeadsIssue(issue, dependsOn[issue.ID], summary))
 		summary.Imported++
+		description := descriptionLines(issue.Description)
+		for _, line := range description {
+			if line == "" {
+				lines = append(lines, "")
+				continue
+			}
+			lines = append(lines, indent+"  "+line)
+		}
+		if len(description) > 0 {
+			summary.Descriptions++
+		}
+		for _, child := range children[issue.ID] {
+			emit(child, indent+"  ")
+		}
 	}
-	if err := s.Err(); err != nil {
-		return summary, fmt.Errorf("read %s: %w", inputPath, err)
+	for _, issue := range roots {
+		emit(issue, "")
 	}
+	rootLines := lines
 
-	return summary, nil
+	under := map[string][]string{}
+	var parents []string
+	for _, issue := range issues {
+		parent, ok := parentOf[issue.ID]
+		if !ok || !existing[parent] {
+			continue
+		}
+		lines = nil
+		emit(issue, "")
+		if _, seen := under[parent]; !seen {
+			parents = append(parents, parent)
+		}
+		under[parent] = append(under[parent], lines...)
+	}
+	return rootLines, under, parents
+}
+
+func createsCycle(parentOf map[string]string, child, parent string) bool {
+	for id, ok := parent, true; ok; id, ok = parentOf[id] {
+		if id == child {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBeadsIssue(issue beadsIssue, deps []string, summary *Summary) string {
+	checkbox := " "
+	if isClosedStatus(issue.Status) {
+		checkbox = "x"
+	}
+	parts := []string{fmt.Sprintf("- [%s] [beads:%s] %s", checkbox, issue.ID, issue.Title)}
+
+	if issue.Priority != nil {
+		if marker := tasks.PriorityMarker(*issue.Priority); marker != "" {
+			parts = append(parts, marker)
+			summary.Priorities++
+		}
+	}
+	if marker := tasks.FormatDependsOn(deps); marker != "" {
+		parts = append(parts, marker)
+	}
+
+	var labels []string
+	if typeLabel := tasks.TypeLabel(issue.IssueType); typeLabel != "" {
+		labels = append(labels, typeLabel)
+		summary.Types++
+	}
+	if extra := tasks.MergeLabels(issue.Labels); len(extra) > 0 {
+		labels = append(labels, extra...)
+		summary.Labeled++
+	}
+	for _, label := range tasks.MergeLabels(labels) {
+		parts = append(parts, "#"+label)
+	}
+	line := strings.Join(parts, " ")
+	if checkbox == "x" {
+		line = addDoneNote(line, githubDate(issue.ClosedAt))
+	}
+	return line
+}
+
+// descriptionLines splits a description into continuation lines, escaping
+// lines that would otherwise be indexed as checklist items.
+func descriptionLines(description string) []string {
+	description = strings.TrimSpace(strings.ReplaceAll(description, "\r\n", "\n"))
+	if description == "" {
+		return nil
+	}
+	lines := strings.Split(description, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if checklistLinePattern.MatchString(line) {
+			trimmed := strings.TrimLeft(line, " \t")
+			line = line[:len(line)-len(trimmed)] + "\\" + trimmed
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func isClosedStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "closed", "done", "resolved":
+		return true
+	}
+	return false
 }
 
 func dirExists(path string) bool {
@@ -101,3 +387,191 @@ func dirExists(path string) bool {
 	}
 	return info.IsDir()
 }
+
+// updateBeadsLine rewrites the title and status of a previously imported
+// checklist line, leaving its indent, markers and labels alone. A line the
+// issue closes gets a done note dated from closed_at.
+func updateBeadsLine(line string, issue beadsIssue) string {
+	closed := isClosedStatus(issue.Status)
+	line = updateRefLine(line, "beads:"+issue.ID, issue.Title, closed)
+	if closed {
+		line = addDoneNote(line, githubDate(issue.ClosedAt))
+	}
+	return line
+}
+
+// updateRefLine sets the checkbox and the title following [ref] on an
+// imported checklist line.
+func updateRefLine(line, ref, newTitle string, closed bool) string {
+	line = tasks.SetCheckboxState(line, closed)
+
+	head, title, tail, ok := splitRefLine(line, ref)
+	if !ok || titleMatches(title+tail, newTitle) {
+		return line
+	}
+	return head + " " + newTitle + tail
+}
+
+// addDoneNote appends a **✅date** note to a ticked line that has none yet.
+func addDoneNote(line, date string) string {
+	if date == "" || doneNotePattern.MatchString(line) {
+		return line
+	}
+	return line + " **✅" + date + "**"
+}
+
+// splitRefLine splits a checklist line around the title that follows its
+// [ref] reference, such as [beads:ID]. tail holds the trailing markers,
+// labels and done note.
+func splitRefLine(line, ref string) (head, title, tail string, ok bool) {
+	ref = "[" + ref + "]"
+	idx := strings.Index(line, ref)
+	if idx < 0 {
+		return "", "", "", false
+	}
+	head = line[:idx+len(ref)]
+	body := strings.TrimPrefix(line[idx+len(ref):], " ")
+	title, tail = splitTitleTail(body)
+	return head, title, tail, true
+}
+
+// splitTitleTail splits the text after an import reference into the title
+// and the trailing markers, labels and done note.
+func splitTitleTail(body string) (title, tail string) {
+	titleEnd := len(body)
+	if loc := doneNotePattern.FindStringIndex(body); loc != nil {
+		titleEnd = loc[0]
+	}
+	for _, marker := range trailingMarkers {
+		if i := strings.Index(body[:titleEnd], " "+marker); i >= 0 {
+			titleEnd = i
+		}
+	}
+	title = strings.TrimRight(body[:titleEnd], " ")
+	for {
+		i := strings.LastIndex(title, " ")
+		if i < 0 || !strings.HasPrefix(title[i+1:], "#") {
+			break
+		}
+		title = strings.TrimRight(title[:i], " ")
+	}
+	return title, body[len(title):]
+}
+
+// titleMatches reports whether the text after an import reference is title
+// followed only by markers, labels and a done note. Imported titles may end
+// in #words that would otherwise be read as trailing labels, so the tail is
+// split off after title rather than before it.
+func titleMatches(body, title string) bool {
+	if body == title {
+		return true
+	}
+	rest, ok := strings.CutPrefix(body, title+" ")
+	if !ok {
+		return false
+	}
+	extra, _ := splitTitleTail(" " + rest)
+	return strings.TrimSpace(extra) == ""
+}
+
+// markdownDoc is an indexed markdown file held in memory while an import is
+// planned.
+type markdownDoc struct {
+	path            string
+	before          string
+	lines           []string
+	trailingNewline bool
+}
+
+// refLocation is the checklist line carrying an import reference.
+type refLocation struct {
+	doc  *markdownDoc
+	line int
+}
+
+type markdownDocs struct {
+	byRel map[string]*markdownDoc
+	order []string
+}
+
+func loadMarkdown(root string) (*markdownDocs, error) {
+	files, err := indexer.SourceFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	docs := &markdownDocs{byRel: map[string]*markdownDoc{}}
+	for _, path := range files {
+		// Org and TaskPaper sources are indexed but never rewritten here.
+		if !strings.EqualFold(filepath.Ext(path), ".md") {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, err
+		}
+		doc := docs.get(rel)
+		doc.before = string(content)
+		doc.trailingNewline = strings.HasSuffix(doc.before, "\n")
+		if doc.before != "" {
+			doc.lines = strings.Split(strings.TrimSuffix(doc.before, "\n"), "\n")
+		}
+	}
+	return docs, nil
+}
+
+// get returns the document at rel, adding an empty one if it does not exist.
+func (d *markdownDocs) get(rel string) *markdownDoc {
+	rel = filepath.ToSlash(rel)
+	doc, ok := d.byRel[rel]
+	if !ok {
+		doc = &markdownDoc{path: rel, trailingNewline: true}
+		d.byRel[rel] = doc
+		d.order = append(d.order, rel)
+	}
+	return doc
+}
+
+// locateRefs finds the first checklist line carrying each reference matched
+// by pattern, keyed by the pattern's first group.
+func (d *markdownDocs) locateRefs(pattern *regexp.Regexp) map[string]refLocation {
+	located := map[string]refLocation{}
+	for _, rel := range d.order {
+		doc := d.byRel[rel]
+		for i, line := range doc.lines {
+			if !checklistLinePattern.MatchString(line) {
+				continue
+			}
+			m := pattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			if _, ok := located[m[1]]; !ok {
+				located[m[1]] = refLocation{doc: doc, line: i}
+			}
+		}
+	}
+	return located
+}
+
+func (d *markdownDocs) changes() []FileChange {
+	var out []FileChange
+	for _, rel := range d.order {
+		doc := d.byRel[rel]
+		after := strings.Join(doc.lines, "\n")
+		if after != "" && (doc.trailingNewline || doc.before == "") {
+			after += "\n"
+		}
+		if after == doc.before {
+			continue
+		}
+		out = append(out, FileChange{Path: rel, Before: doc.before, After: after})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
@@ -42,7 +42,7 @@ func TestImportBeadsIssuesMapsStatusesAndSkipsTombstones(t *testing.T) {
 	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] existing\n")
 	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join([]string{
 		`{"id":"pl-1","title":"Open item","status":"open"}`,
-		`{"id":"pl-2","title":"Closed item","status":"closed"}`,
+		`{"id":"pl-2","title":"Closed item","status":"closed","closed_at":"2026-10-03T12:00:00Z"}`,
 		`{"id":"pl-3","title":"Gone","status":"tombstone"}`,
 	}, "\n")+"\n")
 
@@ -61,7 +61,7 @@ func TestImportBeadsIssuesMapsStatusesAndSkipsTombstones(t *testing.T) {
 	if !strings.Contains(got, "- [ ] [beads:pl-1] Open item\n") {
 		t.Fatalf("expected open issue line, got: %q", got)
 	}
-	if !strings.Contains(got, "- [x] [beads:pl-2] Closed item\n") {
+	if !strings.Contains(got, "- [x] [beads:pl-2] Closed item **✅2026-10-03**\n") {
 		t.Fatalf("expected closed issue line, got: %q", got)
 	}
 	if strings.Contains(got, "pl-3") {
@@ -85,6 +85,148 @@ func TestImportBeadsIssuesErrorsOnMalformedJSONWithLineNumber(t *testing.T) {
 	}
 }
 
+func TestImportBeadsIssuesMapsFieldsLinksAndDescriptions(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".beads"))
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "")
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join([]string{
+		`{"id":"pl-1","title":"Launch","status":"open","priority":1,"issue_type":"epic","labels":["web"]}`,
+		`{"id":"pl-2","title":"Write copy","status":"open","priority":2,"issue_type":"task","description":"Tone: friendly.\n- [ ] not a task","dependencies":[{"issue_id":"pl-2","depends_on_id":"pl-1","type":"parent-child"}]}`,
+		`{"id":"pl-3","title":"Publish","status":"closed","priority":0,"dependencies":[{"issue_id":"pl-3","depends_on_id":"pl-2","type":"blocks"},{"issue_id":"pl-3","depends_on_id":"pl-1","type":"parent-child"},{"issue_id":"pl-3","depends_on_id":"pl-9","type":"blocks"},{"issue_id":"pl-3","depends_on_id":"pl-1","type":"related"}]}`,
+	}, "\n")+"\n")
+
+	summary, err := ImportBeadsIssues(dir)
+	if err != nil {
+		t.Fatalf("ImportBeadsIssues returned err: %v", err)
+	}
+	want := Summary{Imported: 3, Types: 2, Priorities: 3, Labeled: 1, Descriptions: 1, ParentLinks: 2, Dependencies: 1, SkippedLinks: 2}
+	if summary != want {
+		t.Fatalf("unexpected summary:\n got %+v\nwant %+v", summary, want)
+	}
+
+	got := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md"))
+	wantContent := strings.Join([]string{
+		"- [ ] [beads:pl-1] Launch ⏫ #t-epic #web",
+		"  - [ ] [beads:pl-2] Write copy 🔼 #t-task",
+		"    Tone: friendly.",
+		`    \- [ ] not a task`,
+		"  - [x] [beads:pl-3] Publish 🔺 ⛔ beads:pl-2",
+	}, "\n") + "\n"
+	if got != wantContent {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, wantContent)
+	}
+}
+
+func TestImportBeadsIssuesIgnoresParentCycles(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".beads"))
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join([]string{
+		`{"id":"pl-1","title":"A","status":"open","dependencies":[{"depends_on_id":"pl-2","type":"parent-child"}]}`,
+		`{"id":"pl-2","title":"B","status":"open","dependencies":[{"depends_on_id":"pl-1","type":"parent-child"}]}`,
+	}, "\n")+"\n")
+
+	summary, err := ImportBeadsIssues(dir)
+	if err != nil {
+		t.Fatalf("ImportBeadsIssues returned err: %v", err)
+	}
+	if summary.Imported != 2 || summary.ParentLinks != 1 || summary.SkippedLinks != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	got := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md"))
+	if got != "- [ ] [beads:pl-2] B\n  - [ ] [beads:pl-1] A\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestImportBeadsIssuesIsIdempotentAndUpdatesExistingIssues(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".beads"))
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join([]string{
+		`{"id":"pl-1","title":"Launch","status":"open","priority":1,"labels":["web"]}`,
+		`{"id":"pl-2","title":"Moved item","status":"open"}`,
+	}, "\n")+"\n")
+
+	if _, err := ImportBeadsIssues(dir); err != nil {
+		t.Fatalf("first import returned err: %v", err)
+	}
+	summary, err := ImportBeadsIssues(dir)
+	if err != nil {
+		t.Fatalf("second import returned err: %v", err)
+	}
+	if summary.Imported != 0 || summary.Unchanged != 2 {
+		t.Fatalf("expected re-import to change nothing, got %+v", summary)
+	}
+
+	// Move one issue into a project file, as a user might after importing.
+	issuesPath := filepath.Join(dir, ".taskgraph", "issues.md")
+	mustWrite(t, issuesPath, "- [ ] [beads:pl-1] Launch ⏫ #web #mine\n")
+	mustWrite(t, filepath.Join(dir, "roadmap.md"), "# Roadmap\n\n- [ ] [beads:pl-2] Moved item\n")
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join([]string{
+		`{"id":"pl-1","title":"Launch site","status":"closed","priority":1,"labels":["web"],"closed_at":"2026-10-04T08:30:00Z"}`,
+		`{"id":"pl-2","title":"Moved item","status":"open"}`,
+		`{"id":"pl-3","title":"Child of moved","status":"open","dependencies":[{"depends_on_id":"pl-2","type":"parent-child"}]}`,
+		`{"id":"pl-4","title":"Brand new","status":"open"}`,
+	}, "\n")+"\n")
+
+	changes, summary, err := PlanBeadsImport(dir)
+	if err != nil {
+		t.Fatalf("PlanBeadsImport returned err: %v", err)
+	}
+	if summary.Imported != 2 || summary.Updated != 1 || summary.Unchanged != 1 || summary.ParentLinks != 1 {
+		t.Fatalf("unexpected plan summary: %+v", summary)
+	}
+	if len(changes) != 2 || changes[0].Path != ".taskgraph/issues.md" || changes[1].Path != "roadmap.md" {
+		t.Fatalf("unexpected planned changes: %#v", changes)
+	}
+	if got := mustRead(t, issuesPath); got != "- [ ] [beads:pl-1] Launch ⏫ #web #mine\n" {
+		t.Fatalf("dry run must not write, got %q", got)
+	}
+
+	if _, err := ImportBeadsIssues(dir); err != nil {
+		t.Fatalf("third import returned err: %v", err)
+	}
+	if got := mustRead(t, issuesPath); got != "- [x] [beads:pl-1] Launch site ⏫ #web #mine **✅2026-10-04**\n- [ ] [beads:pl-4] Brand new\n" {
+		t.Fatalf("unexpected issues.md: %q", got)
+	}
+	if got := mustRead(t, filepath.Join(dir, "roadmap.md")); got != "# Roadmap\n\n- [ ] [beads:pl-2] Moved item\n  - [ ] [beads:pl-3] Child of moved\n" {
+		t.Fatalf("unexpected roadmap.md: %q", got)
+	}
+}
+
+func TestUpdateBeadsLineReopensAndKeepsTrailingMetadata(t *testing.T) {
+	line := "  - [x] [beads:pl-1] Old title 🔼 ⛔ beads:pl-2 #t-task **✅2026-01-02 done**"
+	got := updateBeadsLine(line, beadsIssue{ID: "pl-1", Title: "New title", Status: "open"})
+	if got != "  - [ ] [beads:pl-1] New title 🔼 ⛔ beads:pl-2 #t-task" {
+		t.Fatalf("unexpected line: %q", got)
+	}
+}
+
+func TestUpdateBeadsLineRetitlesToPrefixOfOldTitle(t *testing.T) {
+	tests := []struct {
+		line, title, want string
+	}{
+		{
+			"- [ ] [beads:pl-1] Write copy now #t-task",
+			"Write copy",
+			"- [ ] [beads:pl-1] Write copy #t-task",
+		},
+		{
+			"- [ ] [beads:pl-1] Support #hashtags 🔼 #t-task",
+			"Support #hashtags",
+			"- [ ] [beads:pl-1] Support #hashtags 🔼 #t-task",
+		},
+	}
+	for _, tt := range tests {
+		got := updateBeadsLine(tt.line, beadsIssue{ID: "pl-1", Title: tt.title, Status: "open"})
+		if got != tt.want {
+			t.Fatalf("retitle %q to %q: got %q want %q", tt.line, tt.title, got, tt.want)
+		}
+	}
+}
+
 func mustMkdirAll(t *testing.T, path string) {
 	t.Helper()
 	if err := os.MkdirAll(path, 0o755); err != nil {
@@ -0,0 +1,422 @@
+package migrate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"taskgraph/internal/tasks"
+)
+
+// beadsSyncStatePath is where the last-synced snapshot lives, relative to the
+// project root.
+var beadsSyncStatePath = filepath.Join(".taskgraph", "beads-sync.json")
+
+// beadsSnapshot is the synced value of one issue's title and open/closed
+// state, as last agreed by both sides.
+type beadsSnapshot struct {
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+type beadsSyncState struct {
+	Issues map[string]beadsSnapshot `json:"issues"`
+}
+
+// SyncSummary reports what one sync moved in each direction.
+type SyncSummary struct {
+	PulledNew      int
+	PulledUpdates  int
+	PushedNew      int
+	PushedUpdates  int
+	RemovedInBeads int
+	RemovedInTG    int
+	Conflicts      []string
+}
+
+// beadsRecord is one JSONL line. Fields holds the whole object so that
+// properties tg does not understand survive a rewrite.
+type beadsRecord struct {
+	raw    string
+	fields map[string]json.RawMessage
+	issue  beadsIssue
+	valid  bool
+	dirty  bool
+}
+
+func (r *beadsRecord) set(key string, value any) {
+	data, _ := json.Marshal(value)
+	r.fields[key] = data
+	r.dirty = true
+}
+
+func (r *beadsRecord) line() string {
+	if !r.dirty {
+		return r.raw
+	}
+	data, _ := json.Marshal(r.fields)
+	return string(data)
+}
+
+// SyncBeads reconciles .beads/issues.jsonl with the tg markdown in both
+// directions. prefer ("tg" or "beads") settles issues changed on both sides
+// since the last sync; without it they are reported as conflicts. New tg
+// tasks are pushed only from the inbox, or with pushLabel set, only those
+// carrying that label.
+func SyncBeads(root, prefer, pushLabel string) (SyncSummary, error) {
+	changes, summary, err := PlanBeadsSync(root, prefer, pushLabel, time.Now())
+	if err != nil {
+		return summary, err
+	}
+	for _, change := range changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Path))
+		if err := os.WriteFile(path, []byte(change.After), 0o644); err != nil {
+			return summary, fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	return summary, nil
+}
+
+// PlanBeadsSync works out the file changes SyncBeads would make without
+// writing anything.
+func PlanBeadsSync(root, prefer, pushLabel string, now time.Time) ([]FileChange, SyncSummary, error) {
+	summary := SyncSummary{}
+	if prefer != "" && prefer != "tg" && prefer != "beads" {
+		return nil, summary, fmt.Errorf("unknown sync preference: %s (use tg or beads)", prefer)
+	}
+
+	inputPath, err := beadsInputPath(root)
+	if err != nil {
+		return nil, summary, err
+	}
+	beadsBefore, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, summary, fmt.Errorf("read %s: %w", inputPath, err)
+	}
+	records, err := parseBeadsRecords(inputPath, string(beadsBefore))
+	if err != nil {
+		return nil, summary, err
+	}
+	state, stateBefore, err := readBeadsSyncState(root)
+	if err != nil {
+		return nil, summary, err
+	}
+	docs, err := loadMarkdown(root)
+	if err != nil {
+		return nil, summary, err
+	}
+	located := docs.locateRefs(beadsRefPattern)
+
+	next := map[string]beadsSnapshot{}
+	inBeads := map[string]bool{}
+	var fresh []beadsIssue
+	for _, record := range records {
+		if !record.valid {
+			continue
+		}
+		id := record.issue.ID
+		inBeads[id] = true
+		if strings.EqualFold(strings.TrimSpace(record.issue.Status), "tombstone") {
+			continue
+		}
+		base, synced := state.Issues[id]
+		at, inTG := located[id]
+		if !inTG {
+			if synced {
+				// Deleted from the markdown after a sync: keep the snapshot so
+				// the issue is not pulled back in.
+				summary.RemovedInTG++
+				next[id] = base
+				continue
+			}
+			fresh = append(fresh, record.issue)
+			next[id] = beadsSnapshot{Title: record.issue.Title, Status: syncStatus(record.issue.Status)}
+			continue
+		}
+
+		line := at.doc.lines[at.line]
+		ours := tgSnapshot(line, id, record.issue.Title)
+		theirs := beadsSnapshot{Title: record.issue.Title, Status: syncStatus(record.issue.Status)}
+
+		title, titlePull, titlePush, titleOK := reconcileField(base.Title, synced, ours.Title, theirs.Title, prefer)
+		status, statusPull, statusPush, statusOK := reconcileField(base.Status, synced, ours.Status, theirs.Status, prefer)
+		if !titleOK || !statusOK {
+			summary.Conflicts = append(summary.Conflicts, id)
+			if synced {
+				next[id] = base
+			}
+		} else {
+			next[id] = beadsSnapshot{Title: title, Status: status}
+		}
+
+		if titlePull || statusPull {
+			pulled := beadsIssue{ID: id, Title: ours.Title, Status: ours.Status}
+			if titlePull {
+				pulled.Title = title
+			}
+			if statusPull {
+				pulled.Status, pulled.ClosedAt = status, record.issue.ClosedAt
+			}
+			at.doc.lines[at.line] = updateBeadsLine(line, pulled)
+			summary.PulledUpdates++
+		}
+		if titlePush || statusPush {
+			if titlePush {
+				record.set("title", title)
+			}
+			if statusPush {
+				record.set("status", status)
+				if status == "closed" {
+					record.set("closed_at", now.UTC().Format(time.RFC3339))
+				} else {
+					delete(record.fields, "closed_at")
+				}
+			}
+			record.set("updated_at", now.UTC().Format(time.RFC3339))
+			summary.PushedUpdates++
+		}
+	}
+
+	// Tracked issues that vanished or were tombstoned in Beads stay in the
+	// markdown; they are reported rather than deleted.
+	for id := range located {
+		if _, ok := next[id]; ok {
+			continue
+		}
+		if base, ok := state.Issues[id]; ok || inBeads[id] {
+			summary.RemovedInBeads++
+			if ok {
+				next[id] = base
+			}
+		}
+	}
+
+	pushed := pushNewTGTasks(docs, inBeads, pushLabel, now, &summary)
+	for _, record := range pushed {
+		records = append(records, record)
+		next[record.issue.ID] = beadsSnapshot{Title: record.issue.Title, Status: record.issue.Status}
+	}
+
+	importSummary := Summary{}
+	if err := docs.addBeadsIssues(located, fresh, &importSummary); err != nil {
+		return nil, summary, err
+	}
+	summary.PulledNew = importSummary.Imported
+
+	changes := docs.changes()
+	var b strings.Builder
+	for _, record := range records {
+		b.WriteString(record.line())
+		b.WriteString("\n")
+	}
+	if after := b.String(); after != string(beadsBefore) {
+		changes = append(changes, FileChange{Path: ".beads/issues.jsonl", Before: string(beadsBefore), After: after})
+	}
+	stateAfter, err := json.MarshalIndent(beadsSyncState{Issues: next}, "", "  ")
+	if err != nil {
+		return nil, summary, err
+	}
+	if after := string(stateAfter) + "\n"; after != stateBefore {
+		changes = append(changes, FileChange{Path: filepath.ToSlash(beadsSyncStatePath), Before: stateBefore, After: after})
+	}
+	return changes, summary, nil
+}
+
+// reconcileField does a three-way merge of one field against the last synced
+// value. It returns the merged value, which side needs updating, and false
+// when both sides changed and prefer does not settle it.
+func reconcileField(base string, synced bool, ours, theirs, prefer string) (value string, pull, push, ok bool) {
+	switch {
+	case ours == theirs:
+		return ours, false, false, true
+	case synced && theirs == base:
+		return ours, false, true, true
+	case synced && ours == base:
+		return theirs, true, false, true
+	case prefer == "tg":
+		return ours, false, true, true
+	case prefer == "beads":
+		return theirs, true, false, true
+	}
+	return "", false, false, false
+}
+
+// tgSnapshot reads the title and state of a markdown line carrying
+// [beads:id]. A title that is the Beads title followed only by labels and
+// markers counts as equal, since trailing #words in Beads titles look like
+// labels in markdown.
+func tgSnapshot(line, id, beadsTitle string) beadsSnapshot {
+	snapshot := beadsSnapshot{Status: "open"}
+	if m := checklistLinePattern.FindStringSubmatch(line); m != nil && m[1] != " " {
+		snapshot.Status = "closed"
+	}
+	_, title, tail, _ := splitRefLine(line, "beads:"+id)
+	snapshot.Title = title
+	if titleMatches(title+tail, beadsTitle) {
+		snapshot.Title = beadsTitle
+	}
+	return snapshot
+}
+
+func syncStatus(status string) string {
+	if isClosedStatus(status) {
+		return "closed"
+	}
+	return "open"
+}
+
+// pushNewTGTasks creates Beads issues for tg tasks that have an ID but no
+// Beads reference yet. Only inbox tasks are pushed, or with pushLabel set,
+// tasks carrying that label anywhere, so a first sync does not copy every
+// project file into Beads. Closed tasks are pushed closed. The Beads issue
+// reuses the tg ID and the markdown line gains a [beads:ID] reference next
+// to it.
+func pushNewTGTasks(docs *markdownDocs, inBeads map[string]bool, pushLabel string, now time.Time, summary *SyncSummary) []*beadsRecord {
+	var out []*beadsRecord
+	stamp := now.UTC().Format(time.RFC3339)
+	inbox := filepath.ToSlash(filepath.Join(".taskgraph", "issues.md"))
+	pushLabel = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(pushLabel)), "#")
+	for _, rel := range docs.order {
+		if pushLabel == "" && rel != inbox {
+			continue
+		}
+		doc := docs.byRel[rel]
+		for i, line := range doc.lines {
+			task, ok := tasks.ParseTask(line)
+			if !ok || beadsRefPattern.MatchString(line) {
+				continue
+			}
+			id := task.ID
+			if id == "" {
+				continue
+			}
+			if pushLabel != "" && !hasLabel(tasks.ExtractLabels(line), pushLabel) {
+				continue
+			}
+			if inBeads[id] {
+				summary.Conflicts = append(summary.Conflicts, id)
+				continue
+			}
+
+			ref := "[" + id + "]"
+			line = strings.Replace(line, ref, ref+" [beads:"+id+"]", 1)
+			doc.lines[i] = line
+			_, title, _, _ := splitRefLine(line, "beads:"+id)
+
+			labels := tasks.ExtractLabels(line)
+			issueType, _ := tasks.ExtractTaskTypeFromLabels(labels)
+			var plain []string
+			for _, label := range labels {
+				if label != tasks.TypeLabel(issueType) {
+					plain = append(plain, label)
+				}
+			}
+			if issueType == "" {
+				issueType = "task"
+			}
+			priority := 2
+			if level, ok := tasks.ParsePriority(line); ok {
+				priority = level
+			}
+
+			status := "open"
+			if task.Done {
+				status = "closed"
+			}
+			record := &beadsRecord{
+				fields: map[string]json.RawMessage{},
+				issue:  beadsIssue{ID: id, Title: title, Status: status},
+				valid:  true,
+			}
+			record.set("id", id)
+			record.set("title", title)
+			record.set("status", status)
+			record.set("priority", priority)
+			record.set("issue_type", issueType)
+			if len(plain) > 0 {
+				record.set("labels", plain)
+			}
+			record.set("created_at", syncDate(task.Created, stamp))
+			record.set("updated_at", stamp)
+			if task.Done {
+				record.set("closed_at", syncDate(task.Completed, stamp))
+			}
+			out = append(out, record)
+			inBeads[id] = true
+			summary.PushedNew++
+		}
+	}
+	return out
+}
+
+// hasLabel reports whether any of labels is filter or nested below it.
+func hasLabel(labels []string, filter string) bool {
+	for _, label := range labels {
+		if tasks.LabelMatches(label, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncDate turns a YYYY-MM-DD marker date into a Beads timestamp, falling
+// back to stamp when the line has none.
+func syncDate(date, stamp string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return stamp
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseBeadsRecords(inputPath, content string) ([]*beadsRecord, error) {
+	var records []*beadsRecord
+	s := bufio.NewScanner(strings.NewReader(content))
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for s.Scan() {
+		lineNo++
+		raw := s.Text()
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		record := &beadsRecord{raw: raw}
+		if err := json.Unmarshal([]byte(raw), &record.fields); err != nil {
+			return nil, fmt.Errorf("parse %s line %d: %w", inputPath, lineNo, err)
+		}
+		if err := json.Unmarshal([]byte(raw), &record.issue); err != nil {
+			return nil, fmt.Errorf("parse %s line %d: %w", inputPath, lineNo, err)
+		}
+		record.issue.ID = strings.TrimSpace(record.issue.ID)
+		record.issue.Title = strings.TrimSpace(record.issue.Title)
+		record.valid = record.issue.ID != "" && record.issue.Title != ""
+		records = append(records, record)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", inputPath, err)
+	}
+	return records, nil
+}
+
+func readBeadsSyncState(root string) (beadsSyncState, string, error) {
+	state := beadsSyncState{Issues: map[string]beadsSnapshot{}}
+	path := filepath.Join(root, beadsSyncStatePath)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, "", nil
+		}
+		return state, "", err
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return state, "", fmt.Errorf("parse %s: %w", path, err)
+	}
+	if state.Issues == nil {
+		state.Issues = map[string]beadsSnapshot{}
+	}
+	return state, string(content), nil
+}
@@ -0,0 +1,151 @@
+package migrate
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSyncProject(t *testing.T, issuesMD string, jsonl ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".beads"))
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), issuesMD)
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join(jsonl, "\n")+"\n")
+	return dir
+}
+
+func readBeadsJSONL(t *testing.T, dir string) map[string]map[string]any {
+	t.Helper()
+	out := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(mustRead(t, filepath.Join(dir, ".beads", "issues.jsonl"))), "\n") {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		out[fields["id"].(string)] = fields
+	}
+	return out
+}
+
+func TestSyncBeadsMovesNewIssuesBothWays(t *testing.T) {
+	dir := newSyncProject(t,
+		"- [ ] ➕2026-10-01 [tg-a1b2] Write docs ⏫ #t-feature #web\n- [x] ➕2026-10-01 [tg-c3d4] Old closed task **✅2026-10-03**\n- [ ] Follow up on [tg-a1b2]\n",
+		`{"id":"bd-1","title":"From beads","status":"open","assignee":"sam"}`,
+	)
+
+	summary, err := SyncBeads(dir, "", "")
+	if err != nil {
+		t.Fatalf("SyncBeads returned err: %v", err)
+	}
+	if summary.PulledNew != 1 || summary.PushedNew != 2 || len(summary.Conflicts) != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	md := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md"))
+	if !strings.Contains(md, "[tg-a1b2] [beads:tg-a1b2] Write docs ⏫ #t-feature #web\n") || !strings.Contains(md, "- [ ] [beads:bd-1] From beads\n") {
+		t.Fatalf("unexpected markdown: %q", md)
+	}
+	if !strings.Contains(md, "- [x] ➕2026-10-01 [tg-c3d4] [beads:tg-c3d4] Old closed task **✅2026-10-03**\n- [ ] Follow up on [tg-a1b2]\n") {
+		t.Fatalf("expected closed tg task pushed: %q", md)
+	}
+
+	issues := readBeadsJSONL(t, dir)
+	pushed := issues["tg-a1b2"]
+	if pushed["title"] != "Write docs" || pushed["issue_type"] != "feature" || pushed["priority"] != float64(1) {
+		t.Fatalf("unexpected pushed issue: %#v", pushed)
+	}
+	if labels, _ := pushed["labels"].([]any); len(labels) != 1 || labels[0] != "web" {
+		t.Fatalf("unexpected pushed labels: %#v", pushed["labels"])
+	}
+	if closed := issues["tg-c3d4"]; closed["status"] != "closed" || closed["closed_at"] != "2026-10-03T00:00:00Z" || closed["created_at"] != "2026-10-01T00:00:00Z" {
+		t.Fatalf("unexpected pushed closed issue: %#v", closed)
+	}
+	if issues["bd-1"]["assignee"] != "sam" {
+		t.Fatalf("expected untouched beads fields preserved, got %#v", issues["bd-1"])
+	}
+
+	changes, summary, err := PlanBeadsSync(dir, "", "", time.Now())
+	if err != nil || len(changes) != 0 || summary.PulledNew+summary.PushedNew+summary.PulledUpdates+summary.PushedUpdates != 0 {
+		t.Fatalf("expected second sync to be a no-op, got changes=%d summary=%+v err=%v", len(changes), summary, err)
+	}
+}
+
+func TestSyncBeadsPushesProjectTasksOnlyByLabel(t *testing.T) {
+	dir := newSyncProject(t, "", `{"id":"bd-1","title":"From beads","status":"open"}`)
+	mustWrite(t, filepath.Join(dir, "roadmap.md"), "# Roadmap\n\n- [ ] [tg-aaaa] Plan launch #team/web\n- [ ] [tg-bbbb] Private chore\n")
+
+	summary, err := SyncBeads(dir, "", "")
+	if err != nil {
+		t.Fatalf("SyncBeads returned err: %v", err)
+	}
+	if summary.PushedNew != 0 || strings.Contains(mustRead(t, filepath.Join(dir, "roadmap.md")), "beads:") {
+		t.Fatalf("expected project tasks left alone without --label, got %+v", summary)
+	}
+
+	summary, err = SyncBeads(dir, "", "team")
+	if err != nil {
+		t.Fatalf("SyncBeads --label returned err: %v", err)
+	}
+	roadmap := mustRead(t, filepath.Join(dir, "roadmap.md"))
+	if summary.PushedNew != 1 || !strings.Contains(roadmap, "[tg-aaaa] [beads:tg-aaaa] Plan launch") || strings.Contains(roadmap, "beads:tg-bbbb") {
+		t.Fatalf("expected only the labeled task pushed, got %+v %q", summary, roadmap)
+	}
+}
+
+func TestSyncBeadsPropagatesClosesAndDetectsConflicts(t *testing.T) {
+	dir := newSyncProject(t,
+		"- [ ] [beads:bd-1] Closed in tg\n- [ ] [beads:bd-2] Closed in beads\n- [ ] [beads:bd-3] Renamed twice\n",
+		`{"id":"bd-1","title":"Closed in tg","status":"open"}`,
+		`{"id":"bd-2","title":"Closed in beads","status":"open"}`,
+		`{"id":"bd-3","title":"Renamed twice","status":"open"}`,
+	)
+	if _, err := SyncBeads(dir, "", ""); err != nil {
+		t.Fatalf("initial sync returned err: %v", err)
+	}
+
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"),
+		"- [x] [beads:bd-1] Closed in tg **✅2026-10-02**\n- [ ] [beads:bd-2] Closed in beads\n- [ ] [beads:bd-3] Renamed in tg\n")
+	mustWrite(t, filepath.Join(dir, ".beads", "issues.jsonl"), strings.Join([]string{
+		`{"id":"bd-1","title":"Closed in tg","status":"open"}`,
+		`{"id":"bd-2","title":"Closed in beads","status":"closed"}`,
+		`{"id":"bd-3","title":"Renamed in beads","status":"open"}`,
+	}, "\n")+"\n")
+
+	summary, err := SyncBeads(dir, "", "")
+	if err != nil {
+		t.Fatalf("SyncBeads returned err: %v", err)
+	}
+	if summary.PushedUpdates != 1 || summary.PulledUpdates != 1 || len(summary.Conflicts) != 1 || summary.Conflicts[0] != "bd-3" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	issues := readBeadsJSONL(t, dir)
+	if issues["bd-1"]["status"] != "closed" || issues["bd-1"]["closed_at"] == nil {
+		t.Fatalf("expected bd-1 closed in beads, got %#v", issues["bd-1"])
+	}
+	md := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md"))
+	if !strings.Contains(md, "- [x] [beads:bd-2] Closed in beads\n") || !strings.Contains(md, "[beads:bd-3] Renamed in tg\n") {
+		t.Fatalf("unexpected markdown: %q", md)
+	}
+
+	if _, err := SyncBeads(dir, "beads", ""); err != nil {
+		t.Fatalf("SyncBeads --prefer beads returned err: %v", err)
+	}
+	if md := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md")); !strings.Contains(md, "[beads:bd-3] Renamed in beads\n") {
+		t.Fatalf("expected beads title to win, got %q", md)
+	}
+	_, summary, err = PlanBeadsSync(dir, "", "", time.Now())
+	if err != nil || len(summary.Conflicts) != 0 {
+		t.Fatalf("expected conflict resolved, got %+v err=%v", summary, err)
+	}
+}
+
+func TestSyncBeadsRejectsUnknownPreference(t *testing.T) {
+	dir := newSyncProject(t, "", `{"id":"bd-1","title":"x","status":"open"}`)
+	if _, err := SyncBeads(dir, "mine", ""); err == nil || !strings.Contains(err.Error(), "unknown sync preference") {
+		t.Fatalf("expected preference error, got %v", err)
+	}
+}
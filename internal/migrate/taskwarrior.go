@@ -0,0 +1,322 @@
+package migrate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"taskgraph/internal/tasks"
+)
+
+var (
+	taskwarriorRefPattern = regexp.MustCompile(`\[tw:([0-9A-Fa-f-]+)\]`)
+	headingLinePattern    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+)
+
+// TaskwarriorSummary reports counts for one Taskwarrior import run. Imported
+// counts new tasks; Updated and Unchanged count tasks found from an earlier
+// import. The mapped-field counts say how many new tasks carried each field.
+type TaskwarriorSummary struct {
+	Imported         int
+	SkippedDeleted   int
+	SkippedRecurring int
+	SkippedInvalid   int
+	Updated          int
+	Unchanged        int
+	Projects         int
+	Priorities       int
+	Labeled          int
+	Dates            int
+	Dependencies     int
+	SkippedLinks     int
+}
+
+// taskwarriorTask is one task from `task export`. Depends is a JSON array in
+// Taskwarrior 2.6+ and a comma-separated string before that.
+type taskwarriorTask struct {
+	UUID        string          `json:"uuid"`
+	Description string          `json:"description"`
+	Project     string          `json:"project"`
+	Tags        []string        `json:"tags"`
+	Priority    string          `json:"priority"`
+	Status      string          `json:"status"`
+	Entry       string          `json:"entry"`
+	Due         string          `json:"due"`
+	Scheduled   string          `json:"scheduled"`
+	End         string          `json:"end"`
+	Depends     json.RawMessage `json:"depends"`
+}
+
+// ImportTaskwarrior imports a `task export` JSON file into the inbox under
+// root. Tasks already present as [tw:UUID] in any indexed markdown file get
+// their description and status updated in place; only new tasks are added.
+func ImportTaskwarrior(root, inputPath string) (TaskwarriorSummary, error) {
+	changes, summary, err := PlanTaskwarriorImport(root, inputPath)
+	if err != nil {
+		return summary, err
+	}
+	for _, change := range changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Path))
+		if err := os.WriteFile(path, []byte(change.After), 0o644); err != nil {
+			return summary, fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	return summary, nil
+}
+
+// PlanTaskwarriorImport works out the file changes ImportTaskwarrior would
+// make without writing anything.
+func PlanTaskwarriorImport(root, inputPath string) ([]FileChange, TaskwarriorSummary, error) {
+	summary := TaskwarriorSummary{}
+	all, err := readTaskwarriorTasks(inputPath, &summary)
+	if err != nil {
+		return nil, summary, err
+	}
+
+	docs, err := loadMarkdown(root)
+	if err != nil {
+		return nil, summary, err
+	}
+
+	located := docs.locateRefs(taskwarriorRefPattern)
+	known := map[string]bool{}
+	var fresh []taskwarriorTask
+	for _, task := range all {
+		known[task.UUID] = true
+		at, ok := located[task.UUID]
+		if !ok {
+			fresh = append(fresh, task)
+			continue
+		}
+		line := at.doc.lines[at.line]
+		updated := updateRefLine(line, "tw:"+task.UUID, task.Description, task.Status == "completed")
+		if task.Status == "completed" {
+			updated = addDoneNote(updated, taskwarriorDate(task.End))
+		}
+		if updated == line {
+			summary.Unchanged++
+			continue
+		}
+		at.doc.lines[at.line] = updated
+		summary.Updated++
+	}
+	for id := range located {
+		known[id] = true
+	}
+
+	// Group new tasks by project, keeping the order projects first appear in.
+	byProject := map[string][]string{}
+	var projects []string
+	for _, task := range fresh {
+		project := strings.TrimSpace(task.Project)
+		if _, ok := byProject[project]; !ok {
+			projects = append(projects, project)
+		}
+		byProject[project] = append(byProject[project], formatTaskwarriorTask(task, known, &summary))
+		summary.Imported++
+		if project != "" {
+			summary.Projects++
+		}
+	}
+
+	inbox := docs.get(filepath.Join(".taskgraph", "issues.md"))
+	for _, project := range projects {
+		var path []string
+		if project != "" {
+			path = strings.Split(project, ".")
+		}
+		inbox.lines = insertUnderHeadingPath(inbox.lines, path, byProject[project])
+	}
+	return docs.changes(), summary, nil
+}
+
+// readTaskwarriorTasks parses a JSON array export, or one JSON object per
+// line as older Taskwarrior versions write, dropping deleted tasks, the
+// templates recurring tasks are generated from, and tasks without a UUID or
+// description.
+func readTaskwarriorTasks(inputPath string, summary *TaskwarriorSummary) ([]taskwarriorTask, error) {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("open input %s: %w", inputPath, err)
+	}
+
+	var raw []taskwarriorTask
+	if trimmed := strings.TrimSpace(string(content)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &raw); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", inputPath, err)
+		}
+	} else {
+		s := bufio.NewScanner(strings.NewReader(trimmed))
+		s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		lineNo := 0
+		for s.Scan() {
+			lineNo++
+			line := strings.TrimSuffix(strings.TrimSpace(s.Text()), ",")
+			if line == "" {
+				continue
+			}
+			var task taskwarriorTask
+			if err := json.Unmarshal([]byte(line), &task); err != nil {
+				return nil, fmt.Errorf("parse %s line %d: %w", inputPath, lineNo, err)
+			}
+			raw = append(raw, task)
+		}
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("read %s: %w", inputPath, err)
+		}
+	}
+
+	var out []taskwarriorTask
+	for _, task := range raw {
+		task.UUID = strings.ToLower(strings.TrimSpace(task.UUID))
+		task.Description = strings.Join(strings.Fields(task.Description), " ")
+		task.Status = strings.ToLower(strings.TrimSpace(task.Status))
+		if task.UUID == "" || task.Description == "" {
+			summary.SkippedInvalid++
+			continue
+		}
+		switch task.Status {
+		case "deleted":
+			summary.SkippedDeleted++
+			continue
+		case "recurring":
+			summary.SkippedRecurring++
+			continue
+		}
+		out = append(out, task)
+	}
+	return out, nil
+}
+
+// formatTaskwarriorTask renders a new task as a checklist line. Priorities
+// H/M/L become ⏫🔼🔽 and dependencies on tasks outside the import and the
+// markdown are counted as skipped.
+func formatTaskwarriorTask(tw taskwarriorTask, known map[string]bool, summary *TaskwarriorSummary) string {
+	task := tasks.Task{
+		Done:      tw.Status == "completed",
+		Text:      "[tw:" + tw.UUID + "] " + tw.Description,
+		Created:   taskwarriorDate(tw.Entry),
+		Due:       taskwarriorDate(tw.Due),
+		Scheduled: taskwarriorDate(tw.Scheduled),
+		Labels:    tasks.MergeLabels(tw.Tags),
+	}
+	if task.Done {
+		task.Completed = taskwarriorDate(tw.End)
+	}
+	if task.Due != "" || task.Scheduled != "" {
+		summary.Dates++
+	}
+	if len(task.Labels) > 0 {
+		summary.Labeled++
+	}
+	switch strings.ToUpper(strings.TrimSpace(tw.Priority)) {
+	case "H":
+		task.Priority = tasks.PriorityHigh
+	case "M":
+		task.Priority = tasks.PriorityMedium
+	case "L":
+		task.Priority = tasks.PriorityLow
+	}
+	if task.Priority != "" {
+		summary.Priorities++
+	}
+	for _, dep := range taskwarriorDepends(tw.Depends) {
+		if !known[dep] || dep == tw.UUID {
+			summary.SkippedLinks++
+			continue
+		}
+		task.DependsOn = append(task.DependsOn, "tw:"+dep)
+		summary.Dependencies++
+	}
+	return task.Format()
+}
+
+func taskwarriorDepends(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		var joined string
+		if err := json.Unmarshal(raw, &joined); err != nil {
+			return nil
+		}
+		list = strings.Split(joined, ",")
+	}
+	var out []string
+	for _, dep := range list {
+		if dep = strings.ToLower(strings.TrimSpace(dep)); dep != "" {
+			out = append(out, dep)
+		}
+	}
+	return out
+}
+
+// taskwarriorDate converts a 20261005T120000Z timestamp to a UTC calendar
+// date, or returns an empty string when it does not parse.
+func taskwarriorDate(raw string) string {
+	t, err := time.Parse("20060102T150405Z", strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// insertUnderHeadingPath adds block to the section reached by following path
+// through nested headings, starting at level 2 and creating any headings that
+// are missing. Within a section the block goes after the existing items and
+// before the first subheading; an empty path means the top of the file, below
+// any level 1 title.
+func insertUnderHeadingPath(lines []string, path []string, block []string) []string {
+	lo, hi := 0, len(lines)
+	for depth, name := range path {
+		level := depth + 2
+		at := -1
+		for i := lo; i < hi; i++ {
+			m := headingLinePattern.FindStringSubmatch(lines[i])
+			if m != nil && len(m[1]) == level && strings.TrimSpace(m[2]) == name {
+				at = i
+				break
+			}
+		}
+		if at < 0 {
+			heading := []string{strings.Repeat("#", level) + " " + name}
+			if hi > 0 && strings.TrimSpace(lines[hi-1]) != "" {
+				heading = append([]string{""}, heading...)
+			}
+			at = hi + len(heading) - 1
+			if hi < len(lines) {
+				heading = append(heading, "")
+			}
+			lines = append(lines[:hi], append(heading, lines[hi:]...)...)
+			hi = at + 1
+		} else {
+			end := hi
+			for i := at + 1; i < hi; i++ {
+				if m := headingLinePattern.FindStringSubmatch(lines[i]); m != nil && len(m[1]) <= level {
+					end = i
+					break
+				}
+			}
+			hi = end
+		}
+		lo = at + 1
+	}
+
+	pos := hi
+	for i := lo; i < hi; i++ {
+		if m := headingLinePattern.FindStringSubmatch(lines[i]); m != nil && len(m[1]) > len(path)+1 {
+			pos = i
+			break
+		}
+	}
+	for pos > lo && strings.TrimSpace(lines[pos-1]) == "" {
+		pos--
+	}
+	out := make([]string, 0, len(lines)+len(block))
+	out = append(out, lines[:pos]...)
+	out = append(out, block...)
+	return append(out, lines[pos:]...)
+}
@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	twGarden = "5f1c6a4e-1111-4d3b-9f7e-aaaaaaaaaaaa"
+	twSeeds  = "5f1c6a4e-2222-4d3b-9f7e-bbbbbbbbbbbb"
+	twTaxes  = "5f1c6a4e-3333-4d3b-9f7e-cccccccccccc"
+	twGone   = "5f1c6a4e-4444-4d3b-9f7e-dddddddddddd"
+	twWeekly = "5f1c6a4e-5555-4d3b-9f7e-eeeeeeeeeeee"
+)
+
+func newTaskwarriorProject(t *testing.T, issuesMD, export string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), issuesMD)
+	input := filepath.Join(dir, "export.json")
+	mustWrite(t, input, export)
+	return dir, input
+}
+
+func TestImportTaskwarriorMapsFieldsAndProjects(t *testing.T) {
+	dir, input := newTaskwarriorProject(t, "# Inbox\n\n- [ ] existing\n", `[
+{"uuid":"`+twGarden+`","description":"Plant tomatoes","project":"Home.Garden","tags":["outside","Spring"],"priority":"H","status":"pending","entry":"20261001T080000Z","due":"20261010T000000Z","depends":["`+twSeeds+`"]},
+{"uuid":"`+twSeeds+`","description":"Buy seeds","project":"Home.Garden","status":"completed","entry":"20261001T080000Z","end":"20261002T090000Z"},
+{"uuid":"`+twTaxes+`","description":"File taxes","status":"waiting","depends":"`+twGone+`"},
+{"uuid":"`+twGone+`","description":"Old idea","status":"deleted"},
+{"uuid":"`+twWeekly+`","description":"Water plants","status":"recurring","recur":"weekly"}
+]`)
+
+	summary, err := ImportTaskwarrior(dir, input)
+	if err != nil {
+		t.Fatalf("ImportTaskwarrior returned err: %v", err)
+	}
+	if summary.Imported != 3 || summary.SkippedDeleted != 1 || summary.SkippedRecurring != 1 || summary.Projects != 2 || summary.Priorities != 1 ||
+		summary.Labeled != 1 || summary.Dates != 1 || summary.Dependencies != 1 || summary.SkippedLinks != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	want := strings.Join([]string{
+		"# Inbox",
+		"",
+		"- [ ] existing",
+		"- [ ] [tw:" + twTaxes + "] File taxes",
+		"",
+		"## Home",
+		"",
+		"### Garden",
+		"- [ ] ➕2026-10-01 [tw:" + twGarden + "] Plant tomatoes ⏫ 📅 2026-10-10 ⛔ tw:" + twSeeds + " #outside #spring",
+		"- [x] ➕2026-10-01 [tw:" + twSeeds + "] Buy seeds **✅2026-10-02**",
+	}, "\n") + "\n"
+	if got := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md")); got != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportTaskwarriorUpdatesInsteadOfDuplicating(t *testing.T) {
+	dir, input := newTaskwarriorProject(t, "",
+		`{"uuid":"`+twGarden+`","description":"Plant tomatoes","project":"Home","status":"pending"}`+"\n")
+	if _, err := ImportTaskwarrior(dir, input); err != nil {
+		t.Fatalf("first import returned err: %v", err)
+	}
+
+	mustWrite(t, input, `{"uuid":"`+twGarden+`","description":"Plant cherry tomatoes","project":"Home","status":"completed","end":"20261005T120000Z"}`+"\n"+
+		`{"uuid":"`+twSeeds+`","description":"Buy seeds","project":"Home","status":"pending"}`+"\n")
+	changes, summary, err := PlanTaskwarriorImport(dir, input)
+	if err != nil {
+		t.Fatalf("PlanTaskwarriorImport returned err: %v", err)
+	}
+	if summary.Imported != 1 || summary.Updated != 1 || len(changes) != 1 {
+		t.Fatalf("unexpected plan: summary=%+v changes=%d", summary, len(changes))
+	}
+	want := "## Home\n- [x] [tw:" + twGarden + "] Plant cherry tomatoes **✅2026-10-05**\n- [ ] [tw:" + twSeeds + "] Buy seeds\n"
+	if changes[0].After != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", changes[0].After, want)
+	}
+
+	if _, err := ImportTaskwarrior(dir, input); err != nil {
+		t.Fatalf("second import returned err: %v", err)
+	}
+	_, summary, err = PlanTaskwarriorImport(dir, input)
+	if err != nil || summary.Unchanged != 2 || summary.Updated != 0 {
+		t.Fatalf("expected re-run to leave both tasks unchanged, got %+v err=%v", summary, err)
+	}
+}
+
+func TestInsertUnderHeadingPathKeepsSubsectionsLast(t *testing.T) {
+	lines := []string{"## Work", "- [ ] a", "", "### Later", "- [ ] b", "", "## Home"}
+	got := insertUnderHeadingPath(lines, []string{"Work"}, []string{"- [ ] new"})
+	want := []string{"## Work", "- [ ] a", "- [ ] new", "", "### Later", "- [ ] b", "", "## Home"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected lines:\n%s", strings.Join(got, "\n"))
+	}
+
+	got = insertUnderHeadingPath(want, []string{"Work", "Soon"}, []string{"- [ ] next"})
+	if joined := strings.Join(got, "\n"); !strings.Contains(joined, "- [ ] b\n\n### Soon\n- [ ] next\n\n## Home") {
+		t.Fatalf("unexpected lines:\n%s", joined)
+	}
+}
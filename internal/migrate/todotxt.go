@@ -0,0 +1,309 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/tasks"
+)
+
+// contextLabelPrefix namespaces todo.txt @contexts among tg labels so they
+// can be told apart from +projects on export.
+const contextLabelPrefix = "ctx-"
+
+var (
+	todoDatePattern      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoPriorityPattern  = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoExtensionPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*):([^\s/]\S*)$`)
+)
+
+// TodoTxtSummary reports counts for one todo.txt import.
+type TodoTxtSummary struct {
+	Imported   int
+	Nested     int
+	Duplicates int
+}
+
+// ImportTodoTxt adds the tasks in the todo.txt file at inputPath to the inbox
+// under root. Tasks carrying an id: already present in the markdown are
+// skipped, so importing an export again adds nothing.
+func ImportTodoTxt(root, inputPath, prefix string) (TodoTxtSummary, error) {
+	changes, summary, err := PlanTodoTxtImport(root, inputPath, prefix, time.Now())
+	if err != nil {
+		return summary, err
+	}
+	for _, change := range changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Path))
+		if err := os.WriteFile(path, []byte(change.After), 0o644); err != nil {
+			return summary, fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	return summary, nil
+}
+
+// PlanTodoTxtImport works out the file changes ImportTodoTxt would make
+// without writing anything. Tasks without an id: get a new tg ID.
+func PlanTodoTxtImport(root, inputPath, prefix string, now time.Time) ([]FileChange, TodoTxtSummary, error) {
+	summary := TodoTxtSummary{}
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return nil, summary, fmt.Errorf("read %s: %w", inputPath, err)
+	}
+	defer file.Close()
+
+	docs, err := loadMarkdown(root)
+	if err != nil {
+		return nil, summary, err
+	}
+	existing := map[string]bool{}
+	for _, rel := range docs.order {
+		for _, line := range docs.byRel[rel].lines {
+			if id := tasks.ExtractTaskID(line); id != "" {
+				existing[id] = true
+			}
+		}
+	}
+	inbox := docs.get(filepath.Join(".taskgraph", "issues.md"))
+
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		task, parent, ok := ParseTodoTxt(s.Text())
+		if !ok {
+			continue
+		}
+		if task.ID != "" && existing[task.ID] {
+			summary.Duplicates++
+			continue
+		}
+		if task.ID == "" {
+			task.ID = tasks.NewTaskID(prefix, task.Text, now, existing)
+		}
+		existing[task.ID] = true
+		summary.Imported++
+
+		line := task.Format()
+		if parent != "" && docs.insertUnder(parent, line) {
+			summary.Nested++
+			continue
+		}
+		inbox.lines = append(inbox.lines, line)
+	}
+	if err := s.Err(); err != nil {
+		return nil, summary, fmt.Errorf("read %s: %w", inputPath, err)
+	}
+	return docs.changes(), summary, nil
+}
+
+// insertUnder adds line as a child of the checklist item carrying [parent]
+// in whichever document holds it, reporting false when there is none.
+func (d *markdownDocs) insertUnder(parent, line string) bool {
+	for _, rel := range d.order {
+		doc := d.byRel[rel]
+		lines, err := tasks.InsertUnderParent(doc.lines, parent, []string{line})
+		if err == nil {
+			doc.lines = lines
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTodoTxt maps one todo.txt line onto a tg task. Priorities A-E become
+// 🔺⏫🔼🔽⏬ (later letters count as lowest), +projects become labels,
+// @contexts become ctx- labels, and the due:, t:, scheduled:, id:, type:,
+// dep: and pri: extensions become the matching tg markers. It also returns
+// the parent: extension. Unknown key:value pairs stay in the text.
+func ParseTodoTxt(line string) (tasks.Task, string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return tasks.Task{}, "", false
+	}
+	task := tasks.Task{}
+	if fields[0] == "x" {
+		task.Done = true
+		fields = fields[1:]
+		if len(fields) > 0 && todoDatePattern.MatchString(fields[0]) {
+			task.Completed = fields[0]
+			fields = fields[1:]
+		}
+	} else if len(fields) > 0 {
+		if m := todoPriorityPattern.FindStringSubmatch(fields[0]); m != nil {
+			task.Priority = todoPriorityMarker(m[1])
+			fields = fields[1:]
+		}
+	}
+	if len(fields) > 0 && todoDatePattern.MatchString(fields[0]) {
+		task.Created = fields[0]
+		fields = fields[1:]
+	}
+
+	var parent string
+	var words, labels []string
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '+':
+			labels = append(labels, field[1:])
+			continue
+		case len(field) > 1 && field[0] == '@':
+			labels = append(labels, contextLabelPrefix+field[1:])
+			continue
+		}
+		if m := todoExtensionPattern.FindStringSubmatch(field); m != nil && applyTodoExtension(&task, &parent, &labels, m[1], m[2]) {
+			continue
+		}
+		words = append(words, field)
+	}
+	task.Text = strings.Join(words, " ")
+	task.Labels = tasks.MergeLabels(labels)
+	if task.Text == "" && task.ID == "" {
+		return tasks.Task{}, "", false
+	}
+	return task, parent, true
+}
+
+// applyTodoExtension records a known key:value extension on task and reports
+// whether it was consumed.
+func applyTodoExtension(task *tasks.Task, parent *string, labels *[]string, key, value string) bool {
+	isDate := todoDatePattern.MatchString(value)
+	isID := tasks.ExtractTaskID("["+value+"]") == value
+	switch key {
+	case "due":
+		if isDate {
+			task.Due = value
+			return true
+		}
+	case "t":
+		if isDate {
+			task.Start = value
+			return true
+		}
+	case "scheduled":
+		if isDate {
+			task.Scheduled = value
+			return true
+		}
+	case "id":
+		if isID {
+			task.ID = value
+			return true
+		}
+	case "parent":
+		if isID {
+			*parent = value
+			return true
+		}
+	case "type":
+		if label := tasks.TypeLabel(value); label != "" {
+			*labels = append(*labels, label)
+			return true
+		}
+	case "dep":
+		task.DependsOn = append(task.DependsOn, strings.Split(value, ",")...)
+		return true
+	case "pri":
+		if len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z' {
+			task.Priority = todoPriorityMarker(value)
+			return true
+		}
+	}
+	return false
+}
+
+func todoPriorityMarker(letter string) string {
+	return tasks.PriorityMarker(min(int(letter[0]-'A'), 4))
+}
+
+// FormatTodoTxt renders a tg task as one todo.txt line, the inverse of
+// ParseTodoTxt. Completed tasks keep their priority as a pri: extension, as
+// todo.txt reserves the (A) prefix for open tasks.
+func FormatTodoTxt(task tasks.Task, parent string) string {
+	var parts []string
+	priority := ""
+	if level, ok := tasks.ParsePriority(task.Priority); ok {
+		priority = string(rune('A' + level))
+	}
+	if task.Done {
+		parts = append(parts, "x")
+		// todo.txt only allows a creation date after a completion date.
+		if task.Completed != "" {
+			parts = append(parts, task.Completed)
+			if task.Created != "" {
+				parts = append(parts, task.Created)
+			}
+		}
+	} else {
+		if priority != "" {
+			parts = append(parts, "("+priority+")")
+		}
+		if task.Created != "" {
+			parts = append(parts, task.Created)
+		}
+	}
+	if task.Text != "" {
+		parts = append(parts, task.Text)
+	}
+	for _, label := range task.Labels {
+		taskType, _ := tasks.ExtractTaskTypeFromLabels([]string{label})
+		switch {
+		case strings.HasPrefix(label, contextLabelPrefix):
+			parts = append(parts, "@"+strings.TrimPrefix(label, contextLabelPrefix))
+		case taskType != "":
+			parts = append(parts, "type:"+taskType)
+		default:
+			parts = append(parts, "+"+label)
+		}
+	}
+	if task.Done && priority != "" {
+		parts = append(parts, "pri:"+priority)
+	}
+	for _, ext := range [][2]string{
+		{"due", task.Due},
+		{"t", task.Start},
+		{"scheduled", task.Scheduled},
+		{"dep", strings.Join(task.DependsOn, ",")},
+		{"id", task.ID},
+		{"parent", parent},
+	} {
+		if ext[1] != "" {
+			parts = append(parts, ext[0]+":"+ext[1])
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ExportTodoTxt renders every checklist node as a todo.txt line, in index
+// order, leaving out TODO comments indexed from source code. Subtasks record
+// their parent's task ID as a parent: extension.
+func ExportTodoTxt(nodes []indexer.Node) string {
+	byID := make(map[string]indexer.Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+	var b strings.Builder
+	for _, node := range nodes {
+		if node.Kind != "checklist" || node.Source == indexer.CodeSource {
+			continue
+		}
+		box := "- [ ] "
+		if node.State == "closed" {
+			box = "- [x] "
+		}
+		task, ok := tasks.ParseTask(box + node.Title)
+		if !ok {
+			continue
+		}
+		parent := ""
+		if p, ok := byID[node.ParentID]; ok && p.Kind == "checklist" {
+			parent = tasks.ExtractTaskID(p.Title)
+		}
+		b.WriteString(FormatTodoTxt(task, parent))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
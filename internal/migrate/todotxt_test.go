@@ -0,0 +1,97 @@
+package migrate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"taskgraph/internal/indexer"
+	"taskgraph/internal/tasks"
+)
+
+func TestParseTodoTxtMapsFields(t *testing.T) {
+	task, parent, ok := ParseTodoTxt("(B) 2026-10-01 Call Sam +Family @phone due:2026-10-05 t:2026-10-02 type:chore rec:1w id:tg-a1b2 parent:tg-c3d4")
+	if !ok {
+		t.Fatalf("expected line to parse")
+	}
+	got := task.Format()
+	want := "- [ ] ➕2026-10-01 [tg-a1b2] Call Sam rec:1w ⏫ 🛫 2026-10-02 📅 2026-10-05 #family #ctx-phone #t-chore"
+	if got != want || parent != "tg-c3d4" {
+		t.Fatalf("unexpected task:\n got %q parent=%q\nwant %q", got, parent, want)
+	}
+}
+
+func TestParseTodoTxtReadsCompletedTasks(t *testing.T) {
+	task, _, ok := ParseTodoTxt("x 2026-10-03 2026-10-01 Pay rent pri:A")
+	if !ok || !task.Done || task.Completed != "2026-10-03" || task.Created != "2026-10-01" || task.Priority != tasks.PriorityHighest {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if _, _, ok := ParseTodoTxt("   "); ok {
+		t.Fatalf("expected blank line to be skipped")
+	}
+}
+
+func TestTodoTxtRoundTripsTGLines(t *testing.T) {
+	for _, line := range []string{
+		"- [ ] ➕2026-10-01 [tg-a1b2] Write docs 🔼 📅 2026-10-09 ⛔ tg-c3d4 #web #ctx-desk #t-feature",
+		"- [x] ➕2026-10-01 [tg-c3d4] Ship it ⏬ **✅2026-10-04**",
+		"- [ ] [tg-e5f6] Read http://example.com/a:b ⏳ 2026-10-06",
+	} {
+		task, ok := tasks.ParseTask(line)
+		if !ok {
+			t.Fatalf("expected %q to parse", line)
+		}
+		back, _, ok := ParseTodoTxt(FormatTodoTxt(task, ""))
+		if !ok || back.Format() != line {
+			t.Fatalf("round trip changed line:\n got %q\nwant %q\nvia %q", back.Format(), line, FormatTodoTxt(task, ""))
+		}
+	}
+}
+
+func TestExportTodoTxtRecordsParents(t *testing.T) {
+	nodes := []indexer.Node{
+		{ID: "f", Kind: "file", Title: "issues"},
+		{ID: "a", Kind: "checklist", State: "open", Title: "[tg-a1b2] Parent", ParentID: "f"},
+		{ID: "b", Kind: "checklist", State: "closed", Title: "[tg-c3d4] Child **✅2026-10-02**", ParentID: "a"},
+	}
+	want := "Parent id:tg-a1b2\nx 2026-10-02 Child id:tg-c3d4 parent:tg-a1b2\n"
+	if got := ExportTodoTxt(nodes); got != want {
+		t.Fatalf("unexpected export:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestImportTodoTxtNestsChildrenAndSkipsKnownIDs(t *testing.T) {
+	dir := t.TempDir()
+	mustMkdirAll(t, filepath.Join(dir, ".taskgraph"))
+	mustWrite(t, filepath.Join(dir, ".taskgraph", "issues.md"), "- [ ] [tg-a1b2] Existing\n")
+	input := filepath.Join(dir, "todo.txt")
+	mustWrite(t, input, strings.Join([]string{
+		"Existing id:tg-a1b2",
+		"(A) New child parent:tg-a1b2",
+		"",
+		"Plain task @home",
+	}, "\n")+"\n")
+
+	changes, summary, err := PlanTodoTxtImport(dir, input, "tg", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("PlanTodoTxtImport returned err: %v", err)
+	}
+	if summary.Imported != 2 || summary.Nested != 1 || summary.Duplicates != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected one changed file, got %d", len(changes))
+	}
+	lines := strings.Split(strings.TrimSpace(changes[0].After), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "  - [ ] [tg-") || !strings.HasSuffix(lines[1], "New child 🔺") || !strings.HasSuffix(lines[2], "Plain task #ctx-home") {
+		t.Fatalf("unexpected markdown: %q", changes[0].After)
+	}
+
+	if _, err := ImportTodoTxt(dir, input, "tg"); err != nil {
+		t.Fatalf("ImportTodoTxt returned err: %v", err)
+	}
+	if md := mustRead(t, filepath.Join(dir, ".taskgraph", "issues.md")); strings.Count(md, "- [ ]") != 3 {
+		t.Fatalf("unexpected markdown after import: %q", md)
+	}
+}
@@ -0,0 +1,203 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// LabelDef is one label registered under labels: in .taskgraph/config.yml:
+//
+//	labels-strict: true
+//	labels:
+//	  flowershow: Flower show planning
+//	  area:
+//	    description: Part of the product a task touches
+//	    color: "#4dabf7"
+//	  someday:
+//
+// A label with nothing after the colon is registered without a description.
+type LabelDef struct {
+	Name        string
+	Description string
+	Color       string
+}
+
+// LabelRegistry is the labels: section of the config. In strict mode only
+// registered labels, and labels nested below them, may be used.
+type LabelRegistry struct {
+	Labels []LabelDef
+	Strict bool
+}
+
+var labelColorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[a-zA-Z]+)$`)
+
+// ReadLabelRegistry reads the labels: section and labels-strict: setting of
+// .taskgraph/config.yml. A missing config has an empty, non-strict registry.
+func ReadLabelRegistry(rootDir string) (LabelRegistry, error) {
+	if strings.TrimSpace(rootDir) == "" {
+		return LabelRegistry{}, errors.New("root directory is required")
+	}
+	configPath := filepath.Join(rootDir, taskgraphDirName, "config.yml")
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return LabelRegistry{}, nil
+		}
+		return LabelRegistry{}, err
+	}
+	return parseLabelRegistry(string(b))
+}
+
+func parseLabelRegistry(config string) (LabelRegistry, error) {
+	var reg LabelRegistry
+	inLabels := false
+	nameIndent := -1
+	for i, raw := range strings.Split(config, "\n") {
+		lineNo := i + 1
+		text := strings.TrimSpace(raw)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+		if indent == 0 {
+			inLabels = text == "labels:"
+			nameIndent = -1
+			if value, ok := strings.CutPrefix(text, "labels-strict:"); ok {
+				switch strings.ToLower(unquoteConfigValue(value)) {
+				case "true", "yes", "on":
+					reg.Strict = true
+				case "false", "no", "off", "":
+					reg.Strict = false
+				default:
+					return LabelRegistry{}, fmt.Errorf("config.yml:%d: labels-strict must be true or false", lineNo)
+				}
+			}
+			continue
+		}
+		if !inLabels {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			return LabelRegistry{}, fmt.Errorf("config.yml:%d: expected key: value in labels", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = unquoteConfigValue(value)
+		if nameIndent < 0 || indent <= nameIndent {
+			name := normalizeRegistryLabel(key)
+			if name == "" {
+				return LabelRegistry{}, fmt.Errorf("config.yml:%d: invalid label name %q", lineNo, key)
+			}
+			if _, ok := reg.lookup(name); ok {
+				return LabelRegistry{}, fmt.Errorf("config.yml:%d: label %s is registered twice", lineNo, name)
+			}
+			nameIndent = indent
+			reg.Labels = append(reg.Labels, LabelDef{Name: name, Description: value})
+			continue
+		}
+
+		def := &reg.Labels[len(reg.Labels)-1]
+		switch key {
+		case "description":
+			def.Description = value
+		case "color", "colour":
+			if !labelColorPattern.MatchString(value) {
+				return LabelRegistry{}, fmt.Errorf("config.yml:%d: label %s: color must be #rgb, #rrggbb or a color name", lineNo, def.Name)
+			}
+			def.Color = value
+		default:
+			return LabelRegistry{}, fmt.Errorf("config.yml:%d: label %s: unknown setting %q", lineNo, def.Name, key)
+		}
+	}
+	return reg, nil
+}
+
+// normalizeRegistryLabel normalises each slash-separated segment of a label
+// name the way task labels are normalised.
+func normalizeRegistryLabel(raw string) string {
+	var segments []string
+	for _, segment := range strings.Split(strings.TrimPrefix(strings.TrimSpace(raw), "#"), "/") {
+		if segment = normalizeIssueType(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (r LabelRegistry) lookup(name string) (LabelDef, bool) {
+	for _, def := range r.Labels {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return LabelDef{}, false
+}
+
+// Lookup returns the registration covering label: its own, or else that of
+// its nearest registered ancestor, so registering area covers area/frontend.
+func (r LabelRegistry) Lookup(label string) (LabelDef, bool) {
+	for name := label; name != ""; {
+		if def, ok := r.lookup(name); ok {
+			return def, true
+		}
+		slash := strings.LastIndex(name, "/")
+		if slash < 0 {
+			break
+		}
+		name = name[:slash]
+	}
+	return LabelDef{}, false
+}
+
+// Allows reports whether label may be used. Everything is allowed outside
+// strict mode, and type labels are checked against issue-types instead.
+func (r LabelRegistry) Allows(label string) bool {
+	if !r.Strict || strings.HasPrefix(label, "t-") {
+		return true
+	}
+	_, ok := r.Lookup(label)
+	return ok
+}
+
+// Closest returns the registered label nearest to label by edit distance, or
+// "" when none is close enough to be a likely typo.
+func (r LabelRegistry) Closest(label string) string {
+	best, bestDist := "", -1
+	for _, def := range r.Labels {
+		d := editDistance(label, def.Name)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = def.Name, d
+		}
+	}
+	if bestDist < 0 || bestDist > max(2, len([]rune(label))/3) {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
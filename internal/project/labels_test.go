@@ -0,0 +1,75 @@
+package project
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabelRegistry(t *testing.T) {
+	config := strings.Join([]string{
+		"issue-prefix: demo",
+		"labels-strict: true",
+		"labels:",
+		"  flowershow: Flower show planning",
+		"  # grouped by area",
+		"  Area:",
+		"    description: \"Part of the product: UI, API\"",
+		"    color: \"#4dabf7\"",
+		"  area/frontend:",
+		"    colour: teal",
+		"  someday:",
+		"views:",
+		"  mine:",
+		"    labels: nope",
+	}, "\n") + "\n"
+
+	reg, err := parseLabelRegistry(config)
+	if err != nil {
+		t.Fatalf("parseLabelRegistry returned err: %v", err)
+	}
+	want := LabelRegistry{
+		Strict: true,
+		Labels: []LabelDef{
+			{Name: "flowershow", Description: "Flower show planning"},
+			{Name: "area", Description: "Part of the product: UI, API", Color: "#4dabf7"},
+			{Name: "area/frontend", Color: "teal"},
+			{Name: "someday"},
+		},
+	}
+	if !reflect.DeepEqual(reg, want) {
+		t.Fatalf("unexpected registry:\n got %+v\nwant %+v", reg, want)
+	}
+
+	if def, ok := reg.Lookup("area/backend/sql"); !ok || def.Name != "area" {
+		t.Fatalf("expected area/backend/sql to fall under area, got %+v %v", def, ok)
+	}
+	for label, allowed := range map[string]bool{"flowershow": true, "area/backend": true, "t-spike": true, "flowershwo": false, "areas": false} {
+		if got := reg.Allows(label); got != allowed {
+			t.Errorf("Allows(%q) = %v, want %v", label, got, allowed)
+		}
+	}
+	if got := reg.Closest("flowershwo"); got != "flowershow" {
+		t.Fatalf("Closest(flowershwo) = %q", got)
+	}
+	if got := reg.Closest("groceries"); got != "" {
+		t.Fatalf("expected no suggestion, got %q", got)
+	}
+	if (LabelRegistry{Labels: want.Labels}).Allows("anything") != true {
+		t.Fatalf("expected every label to be allowed outside strict mode")
+	}
+}
+
+func TestParseLabelRegistryRejectsBadSettings(t *testing.T) {
+	for _, config := range []string{
+		"labels-strict: maybe\n",
+		"labels:\n  a:\n    color: not a colour\n",
+		"labels:\n  a:\n    size: 3\n",
+		"labels:\n  a:\n  A:\n",
+		"labels:\n  just text\n",
+	} {
+		if _, err := parseLabelRegistry(config); err == nil {
+			t.Fatalf("expected error for %q", config)
+		}
+	}
+}
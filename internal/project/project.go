@@ -186,6 +186,37 @@ func ReadAllowedIssueTypes(rootDir string) ([]string, error) {
 	return merged, nil
 }
 
+// ReadScanCode reports whether .taskgraph/config.yml opts in to indexing
+// TODO/FIXME/HACK comments from source code via "scan-code: true".
+func ReadScanCode(rootDir string) (bool, error) {
+	if strings.TrimSpace(rootDir) == "" {
+		return false, errors.New("root directory is required")
+	}
+	configPath := filepath.Join(rootDir, taskgraphDirName, "config.yml")
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return parseScanCode(string(b)), nil
+}
+
+func parseScanCode(config string) bool {
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "scan-code:") {
+			switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "scan-code:"))) {
+			case "true", "yes", "on":
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
+
 func parsePrefix(config string) string {
 	for _, line := range strings.Split(config, "\n") {
 		line = strings.TrimSpace(line)
@@ -176,6 +176,26 @@ func TestReadPrefixBackwardCompatibleWithOldKey(t *testing.T) {
 	}
 }
 
+func TestReadScanCodeIsOptIn(t *testing.T) {
+	root := t.TempDir()
+	if got, err := ReadScanCode(root); err != nil || got {
+		t.Fatalf("expected scan-code off without config, got %v err=%v", got, err)
+	}
+	mustMkdirAll(t, filepath.Join(root, ".taskgraph"))
+	for content, want := range map[string]bool{
+		"issue-prefix: demo\n":                   false,
+		"issue-prefix: demo\nscan-code: true\n":  true,
+		"issue-prefix: demo\nscan-code: false\n": false,
+	} {
+		if err := os.WriteFile(filepath.Join(root, ".taskgraph", "config.yml"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write config failed: %v", err)
+		}
+		if got, err := ReadScanCode(root); err != nil || got != want {
+			t.Fatalf("config %q: got %v want %v err=%v", content, got, want, err)
+		}
+	}
+}
+
 func TestReadAllowedIssueTypesIncludesBuiltins(t *testing.T) {
 	root := t.TempDir()
 	mustMkdirAll(t, filepath.Join(root, ".taskgraph"))
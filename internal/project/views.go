@@ -0,0 +1,159 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// View is a named query saved under views: in .taskgraph/config.yml:
+//
+//	views:
+//	  open-bugs:
+//	    filter: type:bug state:open -label:someday
+//	    sort: path
+//	    format: list
+//	  roadmap:
+//	    filter: "path:docs/roadmap.md #launch"
+//	    format: graph
+//	    depth: 3
+//	    max-children: 8
+//
+// Zero values mean the command's own default.
+type View struct {
+	Name        string
+	Filter      string
+	Sort        string
+	Format      string
+	Depth       int
+	MaxChildren int
+	All         bool
+	Labels      []string
+}
+
+// ViewFormats lists the accepted view format: values. list prints tasks like
+// tg list; the others print tg graph output in that format.
+var ViewFormats = []string{"list", "graph", "dot", "mermaid"}
+
+// ViewSorts lists the accepted view sort: values, which apply to list views.
+var ViewSorts = []string{"mtime", "path", "title"}
+
+// ReadViews reads the views: section of .taskgraph/config.yml in config
+// order. A missing config has no views.
+func ReadViews(rootDir string) ([]View, error) {
+	if strings.TrimSpace(rootDir) == "" {
+		return nil, errors.New("root directory is required")
+	}
+	configPath := filepath.Join(rootDir, taskgraphDirName, "config.yml")
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseViews(string(b))
+}
+
+func parseViews(config string) ([]View, error) {
+	var views []View
+	inViews := false
+	nameIndent := -1
+	for i, raw := range strings.Split(config, "\n") {
+		lineNo := i + 1
+		text := strings.TrimSpace(raw)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+		if indent == 0 {
+			inViews = text == "views:"
+			nameIndent = -1
+			continue
+		}
+		if !inViews {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("config.yml:%d: expected key: value in views", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = unquoteConfigValue(value)
+		if nameIndent < 0 || indent <= nameIndent {
+			if value != "" {
+				return nil, fmt.Errorf("config.yml:%d: view %q needs its settings on the lines below", lineNo, key)
+			}
+			nameIndent = indent
+			views = append(views, View{Name: key})
+			continue
+		}
+
+		v := &views[len(views)-1]
+		switch key {
+		case "filter", "where":
+			v.Filter = value
+		case "sort":
+			if !containsString(ViewSorts, value) {
+				return nil, fmt.Errorf("config.yml:%d: view %s: sort must be one of %s", lineNo, v.Name, strings.Join(ViewSorts, ", "))
+			}
+			v.Sort = value
+		case "format":
+			if !containsString(ViewFormats, value) {
+				return nil, fmt.Errorf("config.yml:%d: view %s: format must be one of %s", lineNo, v.Name, strings.Join(ViewFormats, ", "))
+			}
+			v.Format = value
+		case "depth", "max-children":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("config.yml:%d: view %s: %s must be a positive number", lineNo, v.Name, key)
+			}
+			if key == "depth" {
+				v.Depth = n
+			} else {
+				v.MaxChildren = n
+			}
+		case "all":
+			switch strings.ToLower(value) {
+			case "true", "yes", "on":
+				v.All = true
+			case "false", "no", "off":
+				v.All = false
+			default:
+				return nil, fmt.Errorf("config.yml:%d: view %s: all must be true or false", lineNo, v.Name)
+			}
+		case "labels", "label":
+			for _, label := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if label = strings.TrimSpace(label); label != "" {
+					v.Labels = append(v.Labels, label)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("config.yml:%d: view %s: unknown setting %q", lineNo, v.Name, key)
+		}
+	}
+	return views, nil
+}
+
+// unquoteConfigValue trims a value and strips one pair of matching quotes,
+// which filters need when they start with # or contain ": ".
+func unquoteConfigValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadViews(t *testing.T) {
+	root := t.TempDir()
+	if views, err := ReadViews(root); err != nil || views != nil {
+		t.Fatalf("expected no views without config, got %v err=%v", views, err)
+	}
+
+	mustMkdirAll(t, filepath.Join(root, ".taskgraph"))
+	config := strings.Join([]string{
+		"issue-prefix: demo",
+		"views:",
+		"  open-bugs:",
+		"    filter: type:bug state:open -label:someday",
+		"    sort: path",
+		"    labels: backend, api",
+		"  # shared with the docs team",
+		"  roadmap:",
+		`    filter: "#launch OR type:epic"`,
+		"    format: mermaid",
+		"    depth: 3",
+		"    max-children: 8",
+		"    all: true",
+		"scan-code: true",
+	}, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(root, ".taskgraph", "config.yml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	views, err := ReadViews(root)
+	if err != nil {
+		t.Fatalf("ReadViews returned err: %v", err)
+	}
+	want := []View{
+		{Name: "open-bugs", Filter: "type:bug state:open -label:someday", Sort: "path", Labels: []string{"backend", "api"}},
+		{Name: "roadmap", Filter: "#launch OR type:epic", Format: "mermaid", Depth: 3, MaxChildren: 8, All: true},
+	}
+	if !reflect.DeepEqual(views, want) {
+		t.Fatalf("unexpected views:\n got %+v\nwant %+v", views, want)
+	}
+}
+
+func TestParseViewsRejectsBadSettings(t *testing.T) {
+	for _, config := range []string{
+		"views:\n  a:\n    format: table\n",
+		"views:\n  a:\n    depth: zero\n",
+		"views:\n  a:\n    colour: red\n",
+		"views:\n  a: type:bug\n",
+	} {
+		if _, err := parseViews(config); err == nil {
+			t.Fatalf("expected error for %q", config)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tasks
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var leadingCreatedPattern = regexp.MustCompile(`^\s*➕\s?\d{4}-\d{2}-\d{2}`)
+
+// CollectTaskIDs returns every [prefix-id] reference in content.
+func CollectTaskIDs(content string) map[string]bool {
+	return collectExistingIDs(content)
+}
+
+// AdoptTasks gives every open checklist item in content that has no ID a new
+// [prefix-xxxx] one, skipping fenced code blocks and items keep rejects. New
+// IDs are added to existing so that later calls do not reuse them. It
+// returns the rewritten content and the IDs it assigned.
+func AdoptTasks(content, prefix string, existing map[string]bool, now time.Time, keep func(Task) bool) (string, []string) {
+	prefix = normalizePrefix(prefix)
+	lines := strings.Split(content, "\n")
+	inFence := false
+	var adopted []string
+	for i, line := range lines {
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		task, ok := ParseTask(line)
+		if !ok || task.Done || task.ID != "" || strings.TrimSpace(task.Text) == "" {
+			continue
+		}
+		if keep != nil && !keep(task) {
+			continue
+		}
+		id := generateIssueID(prefix, task.Text, now, existing)
+		existing[id] = true
+		lines[i] = insertTaskID(line, id)
+		adopted = append(adopted, id)
+	}
+	return strings.Join(lines, "\n"), adopted
+}
+
+// insertTaskID places [id] right after the checkbox, or after a leading
+// ➕created date, leaving the rest of the line as it was.
+func insertTaskID(line, id string) string {
+	m := checkboxLinePattern.FindStringSubmatch(line)
+	if m == nil {
+		return line
+	}
+	rest := m[3]
+	created := leadingCreatedPattern.FindString(rest)
+	rest = rest[len(created):]
+	if rest != "" && !strings.HasPrefix(rest, " ") && !strings.HasPrefix(rest, "\t") {
+		rest = " " + rest
+	}
+	return m[1] + "[" + m[2] + "]" + created + " [" + id + "]" + rest
+}
@@ -0,0 +1,46 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdoptTasks(t *testing.T) {
+	content := strings.Join([]string{
+		"# Plan",
+		"- [ ] Write docs #docs",
+		"  - [ ] ➕2024-01-02 Draft outline",
+		"- [x] Already done",
+		"- [ ] [tg-abc] Tracked",
+		"- [ ] Skip me",
+		"```",
+		"- [ ] Example in code",
+		"```",
+		"- [ ]",
+	}, "\n") + "\n"
+	existing := CollectTaskIDs(content)
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	got, adopted := AdoptTasks(content, "tg", existing, now, func(task Task) bool {
+		return task.Text != "Skip me"
+	})
+	if len(adopted) != 2 {
+		t.Fatalf("expected 2 adopted tasks, got %v\n%s", adopted, got)
+	}
+	lines := strings.Split(got, "\n")
+	if lines[1] != "- [ ] ["+adopted[0]+"] Write docs #docs" {
+		t.Fatalf("unexpected line: %q", lines[1])
+	}
+	if lines[2] != "  - [ ] ➕2024-01-02 ["+adopted[1]+"] Draft outline" {
+		t.Fatalf("unexpected line: %q", lines[2])
+	}
+	for _, i := range []int{0, 3, 4, 5, 6, 7, 8, 9} {
+		if lines[i] != strings.Split(content, "\n")[i] {
+			t.Fatalf("line %d changed: %q", i+1, lines[i])
+		}
+	}
+	if !existing[adopted[0]] || !existing[adopted[1]] || !existing["tg-abc"] {
+		t.Fatalf("expected existing to track new IDs, got %v", existing)
+	}
+}
@@ -0,0 +1,223 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// typeLabelTokenPattern matches a type label in either spelling, #t-bug or
+// #type/bug, with the whitespace before it.
+var typeLabelTokenPattern = regexp.MustCompile(`(?i)[ \t]+#(?:t-|` + typeNamespace + `/)[a-z0-9][a-z0-9-]*(?:/[a-z0-9][a-z0-9-]*)*`)
+
+// AddTaskLabels appends the labels the task carrying id does not have yet.
+func AddTaskLabels(tasksFile, id string, labels []string) error {
+	return UpdateTaskLine(tasksFile, id, func(line string) (string, error) {
+		task, ok := ParseTask(line)
+		if !ok {
+			return "", errors.New("not a checklist item")
+		}
+		have := map[string]bool{}
+		for _, l := range task.Labels {
+			have[l] = true
+		}
+		var missing []string
+		for _, l := range MergeLabels(labels) {
+			if !have[l] {
+				missing = append(missing, l)
+			}
+		}
+		return appendToTaskLine(line, formatLabels(missing)), nil
+	})
+}
+
+// SetTaskType replaces the task's type label, #t-name or #type/name, with the
+// #t- label for taskType.
+func SetTaskType(tasksFile, id, taskType string) error {
+	label := TypeLabel(taskType)
+	if label == "" {
+		return errors.New("task type is required")
+	}
+	return UpdateTaskLine(tasksFile, id, func(line string) (string, error) {
+		if !checkboxLinePattern.MatchString(line) {
+			return "", errors.New("not a checklist item")
+		}
+		return appendToTaskLine(typeLabelTokenPattern.ReplaceAllString(line, ""), "#"+label), nil
+	})
+}
+
+// appendToTaskLine adds text at the end of a checklist line, before a
+// **✅date note** completion note when there is one.
+func appendToTaskLine(line, text string) string {
+	if text == "" {
+		return line
+	}
+	body, note := line, ""
+	if loc := doneNoteFieldsPattern.FindStringIndex(line); loc != nil {
+		body, note = line[:loc[0]], line[loc[0]:]
+	}
+	return strings.TrimRight(body, " \t") + " " + text + note
+}
+
+// UpdateTaskLine rewrites the checklist line whose own ID is id in tasksFile with
+// the result of edit.
+func UpdateTaskLine(tasksFile, id string, edit func(line string) (string, error)) error {
+	if strings.TrimSpace(tasksFile) == "" {
+		return errors.New("tasks file is required")
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return errors.New("task id is required")
+	}
+	content, err := os.ReadFile(tasksFile)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if task, ok := ParseTask(line); !ok || task.ID != id {
+			continue
+		}
+		edited, err := edit(line)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, id)
+		}
+		lines[i] = edited
+		return os.WriteFile(tasksFile, []byte(strings.Join(lines, "\n")), 0o644)
+	}
+	return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+}
+
+// TaskEdit describes changes to an existing task. Empty fields are left as
+// they are.
+type TaskEdit struct {
+	// Title replaces the task text. #labels in it are added to the task.
+	Title        string
+	AddLabels    []string
+	RemoveLabels []string
+	// Type replaces the task's #t- type label.
+	Type string
+}
+
+// EditTaskLine applies edit to a checklist line. A new title renders the line
+// in the canonical layout tg add writes; label and type edits change only the
+// labels. It returns the new line and the edited task, and fails when the
+// edit leaves the task with conflicting types.
+func EditTaskLine(line string, edit TaskEdit) (string, Task, error) {
+	task, ok := ParseTask(line)
+	if !ok {
+		return "", Task{}, errors.New("not a checklist item")
+	}
+
+	labels := task.Labels
+	if title := strings.TrimSpace(edit.Title); title != "" {
+		task.Text = stripLabels(title)
+		if task.Text == "" {
+			return "", Task{}, errors.New("task title is required")
+		}
+		labels = MergeLabels(labels, ExtractLabels(title))
+	}
+	labels = MergeLabels(labels, edit.AddLabels)
+
+	remove := map[string]bool{}
+	for _, l := range MergeLabels(edit.RemoveLabels) {
+		remove[l] = true
+	}
+	taskType := NormalizeTaskType(edit.Type)
+	kept := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if remove[l] || (taskType != "" && strings.HasPrefix(l, typeLabelPrefix)) {
+			continue
+		}
+		kept = append(kept, l)
+	}
+
+	resolvedType, clean, err := ResolveTaskType("", kept, taskType)
+	if err != nil {
+		return "", Task{}, err
+	}
+	task.Labels = clean
+	if resolvedType != "" {
+		task.Labels = MergeLabels(clean, []string{TypeLabel(resolvedType)})
+	}
+	if strings.TrimSpace(edit.Title) != "" {
+		return task.Format(), task, nil
+	}
+
+	// Without a new title only the labels change, so edit them where they
+	// stand and leave the rest of the line as written.
+	want, have := map[string]bool{}, map[string]bool{}
+	for _, l := range task.Labels {
+		want[l] = true
+	}
+	out := replaceLabels(line, func(_, label string) (string, bool) {
+		name := normalizeLabel(label)
+		have[name] = true
+		return "", name != "" && !want[name]
+	})
+	var missing []string
+	for _, l := range task.Labels {
+		if !have[l] {
+			missing = append(missing, l)
+		}
+	}
+	return appendToTaskLine(out, formatLabels(missing)), task, nil
+}
+
+// RemoveTask deletes the checklist item carrying id from tasksFile. With
+// withChildren its nested lines go too; otherwise they move up one level to
+// take its place. It returns how many lines were nested below the task.
+func RemoveTask(tasksFile, id string, withChildren bool) (int, error) {
+	if strings.TrimSpace(tasksFile) == "" {
+		return 0, errors.New("tasks file is required")
+	}
+	lines, trailing, err := readLines(tasksFile)
+	if err != nil {
+		return 0, err
+	}
+	start, end, err := findTaskBlock(lines, id)
+	if err != nil {
+		return 0, err
+	}
+	children := lines[start+1 : end]
+	nested := 0
+	for _, line := range children {
+		if strings.TrimSpace(line) != "" {
+			nested++
+		}
+	}
+
+	var keep []string
+	if !withChildren && nested > 0 {
+		parentIndent := listItemPattern.FindStringSubmatch(lines[start])[1]
+		childIndent := ""
+		for _, line := range children {
+			if m := listItemPattern.FindStringSubmatch(line); m != nil {
+				childIndent = m[1]
+				break
+			}
+		}
+		if childIndent == "" {
+			for _, line := range children {
+				if strings.TrimSpace(line) != "" {
+					childIndent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+					break
+				}
+			}
+		}
+		for _, line := range children {
+			if strings.HasPrefix(line, childIndent) {
+				line = parentIndent + line[len(childIndent):]
+			}
+			keep = append(keep, line)
+		}
+	}
+
+	out := append(append(append([]string(nil), lines[:start]...), keep...), lines[end:]...)
+	if err := writeLines(tasksFile, out, trailing); err != nil {
+		return 0, err
+	}
+	return nested, nil
+}
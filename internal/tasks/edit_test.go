@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAddTaskLabelsAndSetTaskType(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "issues.md")
+	writeTestFile(t, path, "- [ ] Follow up on [tg-aaa]\n- [ ] [tg-aaa] Plan #launch #t-task\n- [x] [tg-bbb] Done **✅2024-01-01 ok**\n")
+
+	if err := AddTaskLabels(path, "tg-aaa", []string{"launch", "Q3"}); err != nil {
+		t.Fatalf("AddTaskLabels returned error: %v", err)
+	}
+	if err := SetTaskType(path, "tg-aaa", "epic"); err != nil {
+		t.Fatalf("SetTaskType returned error: %v", err)
+	}
+	if err := AddTaskLabels(path, "tg-bbb", []string{"old"}); err != nil {
+		t.Fatalf("AddTaskLabels returned error: %v", err)
+	}
+	assertFile(t, path, "- [ ] Follow up on [tg-aaa]\n- [ ] [tg-aaa] Plan #launch #q3 #t-epic\n- [x] [tg-bbb] Done #old **✅2024-01-01 ok**\n")
+
+	if err := SetTaskType(path, "tg-zzz", "bug"); err == nil {
+		t.Fatalf("expected error for unknown task")
+	}
+
+	writeTestFile(t, path, "- [ ] [tg-ccc] Plan #type/epic #Type/Story launch\n")
+	if err := SetTaskType(path, "tg-ccc", "bug"); err != nil {
+		t.Fatalf("SetTaskType returned error: %v", err)
+	}
+	assertFile(t, path, "- [ ] [tg-ccc] Plan launch #t-bug\n")
+}
+
+func TestEditTaskLine(t *testing.T) {
+	line := "  - [ ] ➕2024-01-02 [tg-aaa] Plan #launch #t-task 📅 2024-03-01"
+	got, task, err := EditTaskLine(line, TaskEdit{
+		Title:        "Plan the #beta launch",
+		AddLabels:    []string{"Q3"},
+		RemoveLabels: []string{"launch"},
+		Type:         "epic",
+	})
+	if err != nil {
+		t.Fatalf("EditTaskLine returned error: %v", err)
+	}
+	want := "  - [ ] ➕2024-01-02 [tg-aaa] Plan the launch 📅 2024-03-01 #beta #q3 #t-epic"
+	if got != want || task.ID != "tg-aaa" {
+		t.Fatalf("EditTaskLine = %q, want %q", got, want)
+	}
+
+	line = "- [ ] [tg-aaa] 📅 2024-03-01 see [docs](#setup) first #Launch #t-task"
+	got, _, err = EditTaskLine(line, TaskEdit{AddLabels: []string{"web"}, RemoveLabels: []string{"launch"}, Type: "bug"})
+	if err != nil {
+		t.Fatalf("EditTaskLine returned error: %v", err)
+	}
+	want = "- [ ] [tg-aaa] 📅 2024-03-01 see [docs](#setup) first #web #t-bug"
+	if got != want {
+		t.Fatalf("EditTaskLine = %q, want %q", got, want)
+	}
+
+	if _, _, err := EditTaskLine("- [ ] [tg-aaa] Plan #t-task", TaskEdit{AddLabels: []string{"t-bug"}}); err == nil {
+		t.Fatalf("expected conflicting types to fail")
+	}
+	if _, _, err := EditTaskLine("plain text", TaskEdit{Title: "x"}); err == nil {
+		t.Fatalf("expected non-checklist line to fail")
+	}
+}
+
+func TestRemoveTask(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	content := "- [ ] [tg-aaa] Epic\n  - [ ] [tg-bbb] Child\n    - [ ] [tg-ccc] Grandchild\n- [ ] [tg-ddd] Next\n"
+
+	writeTestFile(t, path, content)
+	n, err := RemoveTask(path, "tg-aaa", false)
+	if err != nil || n != 2 {
+		t.Fatalf("RemoveTask = %d, %v", n, err)
+	}
+	assertFile(t, path, "- [ ] [tg-bbb] Child\n  - [ ] [tg-ccc] Grandchild\n- [ ] [tg-ddd] Next\n")
+
+	writeTestFile(t, path, content)
+	if _, err := RemoveTask(path, "tg-aaa", true); err != nil {
+		t.Fatalf("RemoveTask returned error: %v", err)
+	}
+	assertFile(t, path, "- [ ] [tg-ddd] Next\n")
+
+	if _, err := RemoveTask(path, "tg-zzz", true); err == nil {
+		t.Fatalf("expected error for unknown task")
+	}
+}
@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var fencePattern = regexp.MustCompile("^\\s*(```|~~~)")
+
+// FormatLine rewrites one checklist line into the canonical layout written
+// by AppendTask and Task.Format: lowercase [x], created date and ID first,
+// labels gathered at the end. It reports false, and returns line unchanged,
+// for lines that are not checklist items or that would not survive the
+// rewrite with the same meaning.
+func FormatLine(line string) (string, bool) {
+	task, ok := ParseTask(line)
+	if !ok {
+		return line, false
+	}
+	return formatParsed(line, task)
+}
+
+func formatParsed(line string, task Task) (string, bool) {
+	indent := task.Prefix[:len(task.Prefix)-len(strings.TrimLeft(task.Prefix, " \t"))]
+	task.Prefix = indent + strings.TrimSpace(task.Prefix) + " "
+	out := task.Format()
+
+	again, ok := ParseTask(out)
+	if !ok {
+		return line, false
+	}
+	again.Prefix = task.Prefix
+	if !reflect.DeepEqual(again, task) {
+		return line, false
+	}
+	return out, true
+}
+
+// FormatMarkdown applies FormatLine to every checklist line of a markdown
+// document outside fenced code blocks. Every other byte, including line
+// endings, is left as it was. When newID is set, tasks without an ID get the
+// one it returns.
+func FormatMarkdown(content string, newID func(Task) string) string {
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		body := strings.TrimSuffix(line, "\r")
+		task, ok := ParseTask(body)
+		if !ok {
+			continue
+		}
+		if task.ID == "" && newID != nil {
+			task.ID = newID(task)
+		}
+		if formatted, ok := formatParsed(body, task); ok {
+			lines[i] = formatted + line[len(body):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
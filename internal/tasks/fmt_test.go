@@ -0,0 +1,42 @@
+package tasks
+
+import "testing"
+
+func TestFormatLine(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"- [X] Ship #launch the beta", "- [x] Ship the beta #launch"},
+		{"  *   [ ] [tg-abc] ➕2024-01-02 call #home   mum", "  * [ ] ➕2024-01-02 [tg-abc] call mum #home"},
+		{"- [ ] 📅 2024-03-01 pay rent ⏫ #Bills", "- [ ] pay rent ⏫ 📅 2024-03-01 #bills"},
+		{"- [x] [tg-abc] done **✅2024-02-02 shipped**", "- [x] [tg-abc] done **✅2024-02-02 shipped**"},
+		{"- [ ] see [docs](#setup) for details", "- [ ] see [docs](#setup) for details"},
+		{"- [ ] #web see [docs](#setup)", "- [ ] see [docs](#setup) #web"},
+	}
+	for _, tc := range cases {
+		got, ok := FormatLine(tc.in)
+		if !ok || got != tc.want {
+			t.Fatalf("FormatLine(%q) = %q, %v; want %q", tc.in, got, ok, tc.want)
+		}
+	}
+	if got, ok := FormatLine("Just prose #label"); ok || got != "Just prose #label" {
+		t.Fatalf("expected prose to be left alone, got %q %v", got, ok)
+	}
+}
+
+func TestFormatMarkdownLeavesOtherLinesAlone(t *testing.T) {
+	in := "# Plan  \r\n\nSome *prose* #tag  \n- [X] #a task\r\n```\n- [X] #in code\n```\n- [ ] no id\n"
+	want := "# Plan  \r\n\nSome *prose* #tag  \n- [x] task #a\r\n```\n- [X] #in code\n```\n- [ ] [tg-new] no id\n"
+	got := FormatMarkdown(in, func(task Task) string {
+		if task.Done {
+			return ""
+		}
+		return "tg-new"
+	})
+	if got != want {
+		t.Fatalf("unexpected output:\n%q\nwant\n%q", got, want)
+	}
+	if FormatMarkdown(want, nil) != want {
+		t.Fatalf("expected formatting to be idempotent")
+	}
+}
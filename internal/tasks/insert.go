@@ -0,0 +1,276 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	markdownHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listItemPattern        = regexp.MustCompile(`^([ \t]*)([-*+])\s+`)
+	// leadingRefsPattern captures the run of [ref]s a list item opens with,
+	// after its checkbox and a ➕created date: the item's own ID and import
+	// refs such as [beads:pl-1]. Refs later in the text only mention a task.
+	leadingRefsPattern = regexp.MustCompile(`^[ \t]*[-*+]\s+(?:\[[ xX]\]\s*)?(?:➕\s?\d{4}-\d{2}-\d{2}\s*)?((?:\[[^\]\s]+\]\s*)+)`)
+)
+
+// Placement selects where InsertTask writes a new checklist line.
+//
+// With ParentID set, the task becomes an indented child of that task. With
+// Heading set, it is added at the end of that heading's section. With neither,
+// it is appended to the end of the file.
+type Placement struct {
+	Heading  string
+	ParentID string
+}
+
+// InsertTask writes one markdown checklist line into tasksFile at the given
+// placement and returns the generated task ID.
+func InsertTask(tasksFile, prefix, text string, labels []string, taskType string, at Placement) (string, error) {
+	return InsertTaskAvoiding(tasksFile, prefix, text, labels, taskType, at, nil)
+}
+
+// InsertTaskAvoiding is InsertTask with a generated ID that also collides
+// with none of taken, typically every ID elsewhere in the repo.
+func InsertTaskAvoiding(tasksFile, prefix, text string, labels []string, taskType string, at Placement, taken map[string]bool) (string, error) {
+	if strings.TrimSpace(tasksFile) == "" {
+		return "", errors.New("tasks file is required")
+	}
+	clean := strings.TrimSpace(text)
+	if clean == "" {
+		return "", errors.New("task text is required")
+	}
+	parentID := strings.TrimSpace(at.ParentID)
+	heading := strings.TrimSpace(at.Heading)
+
+	existing, err := os.ReadFile(tasksFile)
+	if err != nil {
+		if !os.IsNotExist(err) || parentID != "" || heading != "" {
+			return "", err
+		}
+	}
+
+	ids := collectExistingIDs(string(existing))
+	for id := range taken {
+		ids[id] = true
+	}
+	body, id, err := formatTaskLine(normalizePrefix(prefix), clean, labels, taskType, ids, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	content := string(existing)
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	switch {
+	case parentID != "":
+		lines, err = insertUnderParent(lines, parentID, body)
+	case heading != "":
+		lines, err = insertUnderHeading(lines, heading, body)
+	default:
+		lines = append(lines, "- "+body)
+		trailingNewline = true
+	}
+	if err != nil {
+		return "", err
+	}
+
+	out := strings.Join(lines, "\n")
+	if trailingNewline {
+		out += "\n"
+	}
+	if err := os.WriteFile(tasksFile, []byte(out), 0o644); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func insertUnderParent(lines []string, parentID, body string) ([]string, error) {
+	return InsertUnderParent(lines, parentID, []string{"- " + body})
+}
+
+// InsertUnderParent adds block as the last child of the list item that opens
+// with [parentRef]. The block's first line is an unindented list item;
+// following lines keep their indent relative to it. The indent follows the
+// parent's existing children, or the file's indent unit when it has none. The
+// first line always gets a - marker, the only one the indexer reads as a task.
+func InsertUnderParent(lines []string, parentRef string, block []string) ([]string, error) {
+	parentIdx := -1
+	for i, line := range lines {
+		if hasLeadingRef(line, parentRef) {
+			parentIdx = i
+			break
+		}
+	}
+	if parentIdx < 0 {
+		return nil, fmt.Errorf("parent task not found: %s", parentRef)
+	}
+
+	parent := listItemPattern.FindStringSubmatch(lines[parentIdx])
+	parentIndent := parent[1]
+	parentWidth := indentWidth(parentIndent)
+
+	// The parent's block runs until the first non-blank line indented at or
+	// above the parent. Blank lines inside it belong to the block only if more
+	// nested content follows them.
+	end := parentIdx
+	childIndent := ""
+	for i := parentIdx + 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lead := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if indentWidth(lead) <= parentWidth {
+			break
+		}
+		end = i
+		if childIndent == "" {
+			if m := listItemPattern.FindStringSubmatch(line); m != nil {
+				childIndent = m[1]
+			}
+		}
+	}
+	if childIndent == "" {
+		childIndent = parentIndent + detectIndentUnit(lines, parentIndent)
+	}
+
+	at := end + 1
+	for i, line := range block {
+		switch {
+		case i == 0:
+			line = childIndent + dashMarker(line)
+		case strings.TrimSpace(line) != "":
+			line = childIndent + line
+		default:
+			line = ""
+		}
+		lines = insertLine(lines, at+i, line)
+	}
+	return lines, nil
+}
+
+func insertUnderHeading(lines []string, heading string, body string) ([]string, error) {
+	return InsertUnderHeading(lines, heading, []string{"- " + body})
+}
+
+// InsertUnderHeading adds block at the end of the section under heading,
+// before any subsection. The block's first line is an unindented list item
+// and always gets a - marker, even in a section bulleted with * or +.
+func InsertUnderHeading(lines []string, heading string, block []string) ([]string, error) {
+	headingIdx := -1
+	for i, line := range lines {
+		m := markdownHeadingPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(m[2]), heading) {
+			headingIdx = i
+			break
+		}
+	}
+	if headingIdx < 0 {
+		return nil, fmt.Errorf("heading not found: %s", heading)
+	}
+
+	// Tasks go directly under the heading, so the section stops at the next
+	// heading of any level rather than running on into subsections.
+	sectionEnd := len(lines)
+	for i := headingIdx + 1; i < len(lines); i++ {
+		if markdownHeadingPattern.MatchString(lines[i]) {
+			sectionEnd = i
+			break
+		}
+	}
+
+	last := headingIdx
+	for i := headingIdx + 1; i < sectionEnd; i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			last = i
+		}
+	}
+
+	block = append([]string(nil), block...)
+	block[0] = dashMarker(block[0])
+	at := last + 1
+	if last == headingIdx {
+		lines = insertLine(lines, at, "")
+		at++
+	}
+	for i, line := range block {
+		lines = insertLine(lines, at+i, line)
+	}
+	return lines, nil
+}
+
+// dashMarker swaps the * or + marker of an unindented list item for -.
+func dashMarker(line string) string {
+	if m := listItemPattern.FindStringSubmatch(line); m != nil && m[2] != "-" {
+		return m[1] + "-" + line[len(m[1])+len(m[2]):]
+	}
+	return line
+}
+
+// detectIndentUnit returns the nesting step used by list items in lines,
+// falling back to a tab when the surrounding indent is tab-based and to two
+// spaces otherwise.
+func detectIndentUnit(lines []string, around string) string {
+	if strings.Contains(around, "\t") {
+		return "\t"
+	}
+	unit := ""
+	for _, line := range lines {
+		m := listItemPattern.FindStringSubmatch(line)
+		if m == nil || m[1] == "" {
+			continue
+		}
+		if strings.HasPrefix(m[1], "\t") {
+			if unit == "" {
+				unit = "\t"
+			}
+			continue
+		}
+		if unit == "" || unit == "\t" || len(m[1]) < len(unit) {
+			unit = m[1]
+		}
+	}
+	if unit == "" {
+		return "  "
+	}
+	return unit
+}
+
+func indentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		}
+	}
+	return width
+}
+
+func insertLine(lines []string, at int, line string) []string {
+	lines = append(lines, "")
+	copy(lines[at+1:], lines[at:])
+	lines[at] = line
+	return lines
+}
+
+// hasLeadingRef reports whether line is a list item that opens with [ref]
+// rather than only mentioning it in its text.
+func hasLeadingRef(line, ref string) bool {
+	m := leadingRefsPattern.FindStringSubmatch(line)
+	return m != nil && strings.Contains(m[1], "["+ref+"]")
+}
@@ -0,0 +1,171 @@
+package tasks
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestInsertTaskAddsIndentedChildUnderParent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, strings.Join([]string{
+		"# Roadmap",
+		"",
+		"- [ ] [tg-epic] Launch #t-epic",
+		"- [ ] [tg-other] Something else",
+	}, "\n")+"\n")
+
+	id, err := InsertTask(path, "tg", "write docs", nil, "", Placement{ParentID: "tg-epic"})
+	if err != nil {
+		t.Fatalf("InsertTask returned err: %v", err)
+	}
+
+	lines := strings.Split(readFile(t, path), "\n")
+	if !strings.HasPrefix(lines[3], "  - [ ] ➕"+todayISO()+" ["+id+"] write docs") {
+		t.Fatalf("expected indented child after parent, got %q", lines[3])
+	}
+	if lines[4] != "- [ ] [tg-other] Something else" {
+		t.Fatalf("expected following sibling preserved, got %q", lines[4])
+	}
+}
+
+func TestInsertTaskFollowsExistingChildIndentWithDashMarker(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, strings.Join([]string{
+		"* [ ] [tg-epic] Launch",
+		"    * [ ] first child",
+		"        * [ ] grandchild",
+		"",
+		"* [ ] [tg-other] Something else",
+	}, "\n")+"\n")
+
+	if _, err := InsertTask(path, "tg", "second child", nil, "", Placement{ParentID: "tg-epic"}); err != nil {
+		t.Fatalf("InsertTask returned err: %v", err)
+	}
+
+	lines := strings.Split(readFile(t, path), "\n")
+	if !regexp.MustCompile(`^    - \[ \] ➕\S+ \[tg-[0-9a-z]+\] second child$`).MatchString(lines[3]) {
+		t.Fatalf("expected child to match sibling indent with a - marker, got %q", lines[3])
+	}
+	if lines[4] != "" || lines[5] != "* [ ] [tg-other] Something else" {
+		t.Fatalf("expected trailing content preserved, got %q", lines[4:])
+	}
+}
+
+func TestInsertTaskUsesTabsWhenParentIsTabIndented(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, "- [ ] top\n\t- [ ] [tg-mid] middle\n")
+
+	if _, err := InsertTask(path, "tg", "leaf", nil, "", Placement{ParentID: "tg-mid"}); err != nil {
+		t.Fatalf("InsertTask returned err: %v", err)
+	}
+
+	lines := strings.Split(readFile(t, path), "\n")
+	if !strings.HasPrefix(lines[2], "\t\t- [ ] ") {
+		t.Fatalf("expected tab-indented child, got %q", lines[2])
+	}
+}
+
+func TestInsertTaskAppendsToHeadingSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, strings.Join([]string{
+		"# Roadmap",
+		"",
+		"## Q3",
+		"",
+		"- [ ] existing",
+		"",
+		"## Q4",
+		"- [ ] later",
+	}, "\n")+"\n")
+
+	if _, err := InsertTask(path, "tg", "new q3 item", []string{"launch"}, "", Placement{Heading: "q3"}); err != nil {
+		t.Fatalf("InsertTask returned err: %v", err)
+	}
+
+	lines := strings.Split(readFile(t, path), "\n")
+	if !strings.HasPrefix(lines[5], "- [ ] ➕") || !strings.HasSuffix(lines[5], "new q3 item #launch") {
+		t.Fatalf("expected new item at end of Q3 section, got %q", lines[5])
+	}
+	if lines[6] != "" || lines[7] != "## Q4" {
+		t.Fatalf("expected Q4 section untouched, got %q", lines[6:])
+	}
+}
+
+func TestInsertTaskWritesDashMarkerInStarBulletedSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "road.md")
+	mustWrite(t, path, "## Q4\n* [ ] other\n")
+
+	if _, err := InsertTask(path, "tg", "via add", nil, "", Placement{Heading: "Q4"}); err != nil {
+		t.Fatalf("InsertTask returned err: %v", err)
+	}
+
+	lines := strings.Split(readFile(t, path), "\n")
+	if lines[1] != "* [ ] other" || !strings.HasPrefix(lines[2], "- [ ] ➕") {
+		t.Fatalf("expected a - checkbox after the * item, got %q", lines)
+	}
+}
+
+func TestInsertTaskSeparatesFirstItemFromHeading(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, "# Roadmap\n## Q3\n## Q4\n")
+
+	if _, err := InsertTask(path, "tg", "first", nil, "", Placement{Heading: "Q3"}); err != nil {
+		t.Fatalf("InsertTask returned err: %v", err)
+	}
+
+	lines := strings.Split(readFile(t, path), "\n")
+	if lines[1] != "## Q3" || lines[2] != "" || !strings.HasSuffix(lines[3], "] first") || lines[4] != "## Q4" {
+		t.Fatalf("unexpected layout: %q", lines)
+	}
+}
+
+func TestInsertTaskReturnsErrorsForMissingTargets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, "# Roadmap\n- [ ] [tg-abc] one\n")
+
+	if _, err := InsertTask(path, "tg", "x", nil, "", Placement{ParentID: "tg-missing"}); err == nil || !strings.Contains(err.Error(), "parent task not found") {
+		t.Fatalf("expected parent not found error, got %v", err)
+	}
+	if _, err := InsertTask(path, "tg", "x", nil, "", Placement{Heading: "Nope"}); err == nil || !strings.Contains(err.Error(), "heading not found") {
+		t.Fatalf("expected heading not found error, got %v", err)
+	}
+}
+
+func TestInsertTaskCreatesMissingFileWithoutPlacement(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "new.md")
+
+	if _, err := InsertTask(path, "tg", "fresh", nil, "", Placement{}); err != nil {
+		t.Fatalf("InsertTask returned err: %v", err)
+	}
+	assertTaskLineFormat(t, readFile(t, path), "tg", "fresh")
+}
+
+func TestInsertUnderParentIndentsBlock(t *testing.T) {
+	lines := []string{"- [ ] follow up on [beads:pl-1]", "* [ ] [beads:pl-1] parent", "* [ ] sibling"}
+	got, err := InsertUnderParent(lines, "beads:pl-1", []string{"- [ ] child", "  notes", "", "  - [ ] grandchild"})
+	if err != nil {
+		t.Fatalf("InsertUnderParent returned err: %v", err)
+	}
+	want := []string{"- [ ] follow up on [beads:pl-1]", "* [ ] [beads:pl-1] parent", "  - [ ] child", "    notes", "", "    - [ ] grandchild", "* [ ] sibling"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected lines:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestInsertUnderParentFindsSyncedBeadsRef(t *testing.T) {
+	lines := []string{"- [ ] ➕2026-10-01 [tg-a1b2] [beads:tg-a1b2] parent"}
+	got, err := InsertUnderParent(lines, "beads:tg-a1b2", []string{"- [ ] child"})
+	if err != nil || len(got) != 2 || got[1] != "  - [ ] child" {
+		t.Fatalf("unexpected lines %q, err %v", got, err)
+	}
+}
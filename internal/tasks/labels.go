@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"strings"
+)
+
+// RenameLabel rewrites every #old label in a markdown document to #new,
+// outside fenced and indented code blocks. Labels nested below old move
+// with it, so renaming area to zone turns #area/frontend into #zone/frontend.
+// Only whole labels as labelPattern reads them are touched, so #old-stuff
+// and link targets such as [intro](#old) are left alone. Where a line
+// already carries the new label, a standalone #old is dropped instead. It
+// returns the new content and how many labels were rewritten.
+func RenameLabel(content, old, new string) (string, int) {
+	old, new = normalizeLabel(old), normalizeLabel(new)
+	if old == "" || new == "" || old == new {
+		return content, 0
+	}
+	renamed := func(label string) (string, bool) {
+		label = normalizeLabel(label)
+		if label != old && !strings.HasPrefix(label, old+"/") {
+			return "", false
+		}
+		return new + label[len(old):], true
+	}
+
+	lines := strings.Split(content, "\n")
+	code := codeLines(lines)
+	count := 0
+	for i, line := range lines {
+		if code[i] || !strings.Contains(line, "#") {
+			continue
+		}
+		hasOld := false
+		present := map[string]bool{}
+		replaceLabels(line, func(_, label string) (string, bool) {
+			if _, ok := renamed(label); ok {
+				hasOld = true
+			} else {
+				present[normalizeLabel(label)] = true
+			}
+			return "", false
+		})
+		if !hasOld {
+			continue
+		}
+		lines[i] = replaceLabels(line, func(boundary, label string) (string, bool) {
+			target, ok := renamed(label)
+			if !ok {
+				return "", false
+			}
+			count++
+			if present[target] && boundary != "(" {
+				return "", true
+			}
+			present[target] = true
+			return target, true
+		})
+	}
+	return strings.Join(lines, "\n"), count
+}
+
+// codeLines marks the lines of fenced code blocks, fences included, and of
+// indented code blocks: lines indented four or more columns after a blank
+// line, outside a list, where nested items are indented just as deep.
+func codeLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	inFence, inCode, inList, prevBlank := false, false, false, true
+	for i, line := range lines {
+		if fencePattern.MatchString(line) {
+			inFence = !inFence
+			code[i] = true
+			continue
+		}
+		if inFence {
+			code[i] = true
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			prevBlank = true
+			continue
+		}
+		lead := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		indented := indentWidth(lead) >= 4
+		switch {
+		case indented && (inCode || prevBlank && !inList):
+			inCode = true
+		case listItemPattern.MatchString(line):
+			inCode, inList = false, true
+		default:
+			inCode = false
+			if lead == "" {
+				inList = false
+			}
+		}
+		code[i] = inCode
+		prevBlank = false
+	}
+	return code
+}
+
+// replaceLabels calls fn with every label in line, as labelMatches finds
+// them, and the character before its #. When fn reports true the label is
+// replaced by the returned name, or removed together with the whitespace
+// before it when the name is empty.
+func replaceLabels(line string, fn func(boundary, label string) (string, bool)) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range labelMatches(line) {
+		boundary, name := line[m[2]:m[3]], line[m[4]:m[5]]
+		repl, ok := fn(boundary, name)
+		if !ok {
+			continue
+		}
+		hash := m[4] - 1
+		if repl != "" {
+			b.WriteString(line[last:m[4]])
+			b.WriteString(repl)
+		} else {
+			b.WriteString(strings.TrimRight(line[last:hash], " \t"))
+		}
+		last = m[5]
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package tasks
+
+import "testing"
+
+func TestRenameLabel(t *testing.T) {
+	content := "# Notes #home\n" +
+		"- [ ] [tg-aaa] Fix tap #Home #home-office (#home)\n" +
+		"- [ ] [tg-bbb] Paint #home #household\n" +
+		"```\n" +
+		"- [ ] example #home\n" +
+		"```\n" +
+		"- [ ] [tg-ccc] Mail#home stays #t-home\n" +
+		"- [ ] [tg-ddd] Parent #home\n" +
+		"    - [ ] [tg-eee] Nested child #home\n" +
+		"See [the intro](#home) and [notes](#household) #home\n" +
+		"\n" +
+		"    echo #home\n" +
+		"    # comment #home\n"
+
+	got, n := RenameLabel(content, "home", "household")
+	want := "# Notes #household\n" +
+		"- [ ] [tg-aaa] Fix tap #household #home-office (#household)\n" +
+		"- [ ] [tg-bbb] Paint #household\n" +
+		"```\n" +
+		"- [ ] example #home\n" +
+		"```\n" +
+		"- [ ] [tg-ccc] Mail#home stays #t-home\n" +
+		"- [ ] [tg-ddd] Parent #household\n" +
+		"    - [ ] [tg-eee] Nested child #household\n" +
+		"See [the intro](#home) and [notes](#household) #household\n" +
+		"\n" +
+		"    echo #home\n" +
+		"    # comment #home\n"
+	if got != want || n != 7 {
+		t.Fatalf("unexpected rename (%d):\n%s", n, got)
+	}
+
+	if got, n := RenameLabel(content, "home", "home"); got != content || n != 0 {
+		t.Fatalf("expected no change when renaming a label to itself")
+	}
+}
+
+func TestRenameLabelMovesNestedLabels(t *testing.T) {
+	content := "- [ ] [tg-aaa] Fix nav #area/frontend #areas\n" +
+		"- [ ] [tg-bbb] Fix API #Area/Backend/db #zone/backend/db #area\n"
+
+	got, n := RenameLabel(content, "area", "zone")
+	want := "- [ ] [tg-aaa] Fix nav #zone/frontend #areas\n" +
+		"- [ ] [tg-bbb] Fix API #zone/backend/db #zone\n"
+	if got != want || n != 3 {
+		t.Fatalf("unexpected rename (%d):\n%s", n, got)
+	}
+}
@@ -0,0 +1,50 @@
+package tasks
+
+import "strings"
+
+// Priority and dependency markers follow the Obsidian Tasks emoji format so
+// task lines stay readable in other markdown tools.
+const (
+	PriorityHighest = "🔺"
+	PriorityHigh    = "⏫"
+	PriorityMedium  = "🔼"
+	PriorityLow     = "🔽"
+	PriorityLowest  = "⏬"
+
+	DependsOnMarker = "⛔"
+)
+
+// priorityMarkers lists the markers from highest to lowest priority.
+var priorityMarkers = []string{PriorityHighest, PriorityHigh, PriorityMedium, PriorityLow, PriorityLowest}
+
+// PriorityMarker returns the marker for a 0 (highest) to 4 (lowest) priority
+// level, or an empty string when level is out of range.
+func PriorityMarker(level int) string {
+	if level < 0 || level >= len(priorityMarkers) {
+		return ""
+	}
+	return priorityMarkers[level]
+}
+
+// FormatDependsOn returns a "⛔ a,b" marker for the given task references, or
+// an empty string when there are none. The references are whatever appears in
+// the blocking task's brackets, such as tg-abc or beads:pl-1. tg resolves them
+// itself; Obsidian Tasks only links ⛔ to 🆔 IDs, so for it they are notes.
+func FormatDependsOn(refs []string) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	return DependsOnMarker + " " + strings.Join(refs, ",")
+}
+
+// ParsePriority returns the 0 (highest) to 4 (lowest) level of the first
+// priority marker in text.
+func ParsePriority(text string) (int, bool) {
+	best, bestAt := 0, -1
+	for level, marker := range priorityMarkers {
+		if i := strings.Index(text, marker); i >= 0 && (bestAt < 0 || i < bestAt) {
+			best, bestAt = level, i
+		}
+	}
+	return best, bestAt >= 0
+}
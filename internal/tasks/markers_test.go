@@ -0,0 +1,30 @@
+package tasks
+
+import "testing"
+
+func TestPriorityMarker(t *testing.T) {
+	if PriorityMarker(0) != PriorityHighest || PriorityMarker(4) != PriorityLowest {
+		t.Fatalf("unexpected priority markers: %q %q", PriorityMarker(0), PriorityMarker(4))
+	}
+	if PriorityMarker(-1) != "" || PriorityMarker(5) != "" {
+		t.Fatalf("expected empty marker out of range")
+	}
+}
+
+func TestFormatDependsOn(t *testing.T) {
+	if got := FormatDependsOn([]string{"tg-abc", "beads:pl-2"}); got != "⛔ tg-abc,beads:pl-2" {
+		t.Fatalf("unexpected marker: %q", got)
+	}
+	if got := FormatDependsOn(nil); got != "" {
+		t.Fatalf("expected empty marker, got %q", got)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	if level, ok := ParsePriority("ship it ⏬ later 🔺"); !ok || level != 4 {
+		t.Fatalf("expected first marker to win, got %d %v", level, ok)
+	}
+	if _, ok := ParsePriority("no marker"); ok {
+		t.Fatalf("expected no priority")
+	}
+}
@@ -0,0 +1,147 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MoveTask cuts the checklist item carrying id, together with its nested
+// children, out of srcFile and inserts it into dstFile at the given
+// placement, re-indented for its new position. The line itself, with its
+// ID, labels and dates, is kept as written. srcFile and dstFile may be the
+// same file.
+func MoveTask(srcFile, id, dstFile string, at Placement) error {
+	if strings.TrimSpace(srcFile) == "" || strings.TrimSpace(dstFile) == "" {
+		return errors.New("source and destination files are required")
+	}
+	parentID := strings.TrimSpace(at.ParentID)
+	heading := strings.TrimSpace(at.Heading)
+	if parentID == id {
+		return fmt.Errorf("cannot move %s under itself", id)
+	}
+
+	srcLines, srcTrailing, err := readLines(srcFile)
+	if err != nil {
+		return err
+	}
+	start, end, err := findTaskBlock(srcLines, id)
+	if err != nil {
+		return err
+	}
+	block := dedentBlock(srcLines[start:end])
+	if parentID != "" {
+		for _, line := range block[1:] {
+			if task, ok := ParseTask(line); ok && task.ID == parentID {
+				return fmt.Errorf("cannot move %s under its own subtask %s", id, parentID)
+			}
+		}
+	}
+	srcLines = append(srcLines[:start:start], srcLines[end:]...)
+
+	sameFile := samePath(srcFile, dstFile)
+	dstLines, dstTrailing := srcLines, srcTrailing
+	if !sameFile {
+		dstLines, dstTrailing, err = readLines(dstFile)
+		if err != nil {
+			if !os.IsNotExist(err) || parentID != "" || heading != "" {
+				return err
+			}
+			dstLines, dstTrailing, err = nil, true, nil
+		}
+	}
+
+	switch {
+	case parentID != "":
+		dstLines, err = InsertUnderParent(dstLines, parentID, block)
+	case heading != "":
+		dstLines, err = InsertUnderHeading(dstLines, heading, block)
+	default:
+		dstLines = append(dstLines, block...)
+		dstTrailing = true
+	}
+	if err != nil {
+		return err
+	}
+
+	if !sameFile {
+		if err := writeLines(srcFile, srcLines, srcTrailing); err != nil {
+			return err
+		}
+	}
+	return writeLines(dstFile, dstLines, dstTrailing)
+}
+
+// findTaskBlock returns the line range of the list item whose own ID is id and
+// every line nested below it. Blank lines belong to the block only when more
+// nested content follows them.
+func findTaskBlock(lines []string, id string) (int, int, error) {
+	start := -1
+	for i, line := range lines {
+		if task, ok := ParseTask(line); ok && task.ID == id {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return 0, 0, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	width := indentWidth(listItemPattern.FindStringSubmatch(lines[start])[1])
+	end := start + 1
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lead := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if indentWidth(lead) <= width {
+			break
+		}
+		end = i + 1
+	}
+	return start, end, nil
+}
+
+// dedentBlock removes the first line's indent from every line of block.
+func dedentBlock(block []string) []string {
+	indent := listItemPattern.FindStringSubmatch(block[0])[1]
+	out := make([]string, len(block))
+	for i, line := range block {
+		if strings.TrimSpace(line) == "" {
+			out[i] = ""
+			continue
+		}
+		out[i] = strings.TrimPrefix(line, indent)
+	}
+	return out
+}
+
+func readLines(path string) ([]string, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	content := string(b)
+	if content == "" {
+		return nil, true, nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), strings.HasSuffix(content, "\n"), nil
+}
+
+func writeLines(path string, lines []string, trailingNewline bool) error {
+	out := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		out += "\n"
+	}
+	return os.WriteFile(path, []byte(out), 0o644)
+}
+
+func samePath(a, b string) bool {
+	ai, errA := os.Stat(a)
+	bi, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return os.SameFile(ai, bi)
+}
@@ -0,0 +1,116 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveTaskToHeadingInAnotherFile(t *testing.T) {
+	dir := t.TempDir()
+	inbox := filepath.Join(dir, "issues.md")
+	roadmap := filepath.Join(dir, "roadmap.md")
+	writeTestFile(t, inbox, strings.Join([]string{
+		"- [ ] ➕2024-01-02 [tg-aaa] Plan launch #launch 📅 2024-03-01",
+		"  - [ ] [tg-bbb] Book venue",
+		"",
+		"    Venue notes",
+		"- [ ] [tg-ccc] Stay here",
+	}, "\n")+"\n")
+	writeTestFile(t, roadmap, "# Roadmap\n\n## Q3\n\n* [ ] [tg-ddd] Existing\n\n## Q4\n")
+
+	if err := MoveTask(inbox, "tg-aaa", roadmap, Placement{Heading: "Q3"}); err != nil {
+		t.Fatalf("MoveTask returned error: %v", err)
+	}
+
+	assertFile(t, inbox, "- [ ] [tg-ccc] Stay here\n")
+	assertFile(t, roadmap, strings.Join([]string{
+		"# Roadmap",
+		"",
+		"## Q3",
+		"",
+		"* [ ] [tg-ddd] Existing",
+		"- [ ] ➕2024-01-02 [tg-aaa] Plan launch #launch 📅 2024-03-01",
+		"  - [ ] [tg-bbb] Book venue",
+		"",
+		"    Venue notes",
+		"",
+		"## Q4",
+	}, "\n")+"\n")
+}
+
+func TestMoveTaskCreatesMissingDestinationFile(t *testing.T) {
+	dir := t.TempDir()
+	inbox := filepath.Join(dir, "issues.md")
+	later := filepath.Join(dir, "later.md")
+	writeTestFile(t, inbox, "- [ ] [tg-aaa] Plan launch\n- [ ] [tg-bbb] Stay here\n")
+
+	if err := MoveTask(inbox, "tg-aaa", later, Placement{}); err != nil {
+		t.Fatalf("MoveTask returned error: %v", err)
+	}
+
+	assertFile(t, inbox, "- [ ] [tg-bbb] Stay here\n")
+	assertFile(t, later, "- [ ] [tg-aaa] Plan launch\n")
+}
+
+func TestMoveTaskIgnoresLinesThatOnlyMentionTheID(t *testing.T) {
+	dir := t.TempDir()
+	inbox := filepath.Join(dir, "issues.md")
+	later := filepath.Join(dir, "later.md")
+	writeTestFile(t, inbox, "- [ ] Follow up on [tg-aaa]\n- [ ] [tg-aaa] Plan launch\n")
+
+	if err := MoveTask(inbox, "tg-aaa", later, Placement{}); err != nil {
+		t.Fatalf("MoveTask returned error: %v", err)
+	}
+
+	assertFile(t, inbox, "- [ ] Follow up on [tg-aaa]\n")
+	assertFile(t, later, "- [ ] [tg-aaa] Plan launch\n")
+}
+
+func TestMoveTaskUnderParentInSameFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plan.md")
+	writeTestFile(t, path, strings.Join([]string{
+		"- [ ] [tg-aaa] Epic",
+		"    - [ ] [tg-bbb] Child",
+		"- [ ] [tg-ccc] Stray",
+		"    - [ ] [tg-ddd] Stray child",
+	}, "\n")+"\n")
+
+	if err := MoveTask(path, "tg-ccc", path, Placement{ParentID: "tg-aaa"}); err != nil {
+		t.Fatalf("MoveTask returned error: %v", err)
+	}
+	assertFile(t, path, strings.Join([]string{
+		"- [ ] [tg-aaa] Epic",
+		"    - [ ] [tg-bbb] Child",
+		"    - [ ] [tg-ccc] Stray",
+		"        - [ ] [tg-ddd] Stray child",
+	}, "\n")+"\n")
+
+	if err := MoveTask(path, "tg-aaa", path, Placement{ParentID: "tg-ddd"}); err == nil {
+		t.Fatalf("expected moving a task under its own subtask to fail")
+	}
+	if err := MoveTask(path, "tg-zzz", path, Placement{}); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(b) != want {
+		t.Fatalf("unexpected %s:\n%s\nwant:\n%s", filepath.Base(path), b, want)
+	}
+}
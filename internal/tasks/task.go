@@ -0,0 +1,154 @@
+package tasks
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var datedMarkerPattern = regexp.MustCompile(`(➕|📅|⏳|🛫|✅)\s?(\d{4}-\d{2}-\d{2})`)
+var dependsOnPattern = regexp.MustCompile(`⛔\s?([A-Za-z0-9_:.-]+(?:,[A-Za-z0-9_:.-]+)*)`)
+var doneNoteFieldsPattern = regexp.MustCompile(`\s*\*\*✅(\d{4}-\d{2}-\d{2})([^*]*)\*\*\s*$`)
+
+// leadingIDPattern matches the [prefix-id] a task line owns: the first thing
+// after the checkbox, or after a leading ➕created date. IDs later in the text
+// are references to other tasks.
+var leadingIDPattern = regexp.MustCompile(`^\s*(?:➕\s?\d{4}-\d{2}-\d{2}\s*)?(\[[a-z0-9]+-[0-9a-z]{3,8}\])`)
+
+// Task is the structured form of one checklist line. Dates are YYYY-MM-DD
+// strings and are empty when the line has no such marker.
+type Task struct {
+	// Prefix is the indent and list marker before the checkbox, e.g. "  - ".
+	Prefix    string
+	Done      bool
+	ID        string
+	Text      string
+	Priority  string
+	Created   string
+	Start     string
+	Scheduled string
+	Due       string
+	Completed string
+	DoneNote  string
+	DependsOn []string
+	Labels    []string
+}
+
+// ExtractDependsOn returns the task references in the ⛔ markers of text.
+func ExtractDependsOn(text string) []string {
+	var refs []string
+	for _, m := range dependsOnPattern.FindAllStringSubmatch(text, -1) {
+		refs = append(refs, strings.Split(m[1], ",")...)
+	}
+	return refs
+}
+
+// ParseTask splits a checklist line into its markers, ID, labels and
+// remaining text. It reports false for lines that are not checklist items.
+func ParseTask(line string) (Task, bool) {
+	m := checkboxLinePattern.FindStringSubmatch(line)
+	if m == nil {
+		return Task{}, false
+	}
+	task := Task{Prefix: m[1], Done: m[2] != " "}
+	rest := m[3]
+	var ref string
+	if id := leadingIDPattern.FindStringSubmatch(rest); id != nil {
+		ref = id[1]
+	}
+
+	if note := doneNoteFieldsPattern.FindStringSubmatch(rest); note != nil {
+		task.Completed = note[1]
+		task.DoneNote = strings.TrimSpace(note[2])
+		rest = rest[:len(rest)-len(note[0])]
+	}
+	rest = datedMarkerPattern.ReplaceAllStringFunc(rest, func(match string) string {
+		sub := datedMarkerPattern.FindStringSubmatch(match)
+		switch sub[1] {
+		case "➕":
+			task.Created = sub[2]
+		case "📅":
+			task.Due = sub[2]
+		case "⏳":
+			task.Scheduled = sub[2]
+		case "🛫":
+			task.Start = sub[2]
+		case "✅":
+			task.Completed = sub[2]
+		}
+		return " "
+	})
+	rest = dependsOnPattern.ReplaceAllStringFunc(rest, func(match string) string {
+		sub := dependsOnPattern.FindStringSubmatch(match)
+		task.DependsOn = append(task.DependsOn, strings.Split(sub[1], ",")...)
+		return " "
+	})
+	if level, ok := ParsePriority(rest); ok {
+		task.Priority = PriorityMarker(level)
+	}
+	for _, marker := range priorityMarkers {
+		rest = strings.ReplaceAll(rest, marker, " ")
+	}
+	if ref != "" {
+		task.ID = strings.Trim(ref, "[]")
+		rest = strings.Replace(rest, ref, " ", 1)
+	}
+	task.Labels = ExtractLabels(rest)
+	task.Text = stripLabels(rest)
+	return task, true
+}
+
+// Format renders the task as a checklist line in tg's canonical order:
+// created date, ID, text, priority, dates, dependencies, labels, done note.
+func (t Task) Format() string {
+	prefix := t.Prefix
+	if prefix == "" {
+		prefix = "- "
+	}
+	box := "[ ]"
+	if t.Done {
+		box = "[x]"
+	}
+	parts := []string{box}
+	if t.Created != "" {
+		parts = append(parts, "➕"+t.Created)
+	}
+	if t.ID != "" {
+		parts = append(parts, "["+t.ID+"]")
+	}
+	if t.Text != "" {
+		parts = append(parts, t.Text)
+	}
+	if t.Priority != "" {
+		parts = append(parts, t.Priority)
+	}
+	if t.Start != "" {
+		parts = append(parts, "🛫 "+t.Start)
+	}
+	if t.Scheduled != "" {
+		parts = append(parts, "⏳ "+t.Scheduled)
+	}
+	if t.Due != "" {
+		parts = append(parts, "📅 "+t.Due)
+	}
+	if deps := FormatDependsOn(t.DependsOn); deps != "" {
+		parts = append(parts, deps)
+	}
+	if labels := formatLabels(t.Labels); labels != "" {
+		parts = append(parts, labels)
+	}
+	if t.Completed != "" {
+		note := "**✅" + t.Completed
+		if t.DoneNote != "" {
+			note += " " + t.DoneNote
+		}
+		parts = append(parts, note+"**")
+	}
+	return prefix + strings.Join(parts, " ")
+}
+
+// NewTaskID returns a fresh [prefix-hash] ID for text that does not collide
+// with existing.
+func NewTaskID(prefix, text string, created time.Time, existing map[string]bool) string {
+	return generateIssueID(normalizePrefix(prefix), text, created, existing)
+}
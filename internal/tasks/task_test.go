@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTaskReadsMarkers(t *testing.T) {
+	line := "  - [x] ➕2026-10-01 [tg-a1b2] Ship release ⏫ 🛫 2026-10-02 📅 2026-10-09 ⛔ tg-c3d4,tg-e5f6 #web #t-bug **✅2026-10-05 done early**"
+	task, ok := ParseTask(line)
+	if !ok {
+		t.Fatalf("expected checklist line to parse")
+	}
+	want := Task{
+		Prefix:    "  - ",
+		Done:      true,
+		ID:        "tg-a1b2",
+		Text:      "Ship release",
+		Priority:  PriorityHigh,
+		Created:   "2026-10-01",
+		Start:     "2026-10-02",
+		Due:       "2026-10-09",
+		Completed: "2026-10-05",
+		DoneNote:  "done early",
+		DependsOn: []string{"tg-c3d4", "tg-e5f6"},
+		Labels:    []string{"web", "t-bug"},
+	}
+	if !reflect.DeepEqual(task, want) {
+		t.Fatalf("unexpected task:\n got %+v\nwant %+v", task, want)
+	}
+	if got := task.Format(); got != line {
+		t.Fatalf("expected canonical line to round-trip:\n got %q\nwant %q", got, line)
+	}
+}
+
+func TestParseTaskKeepsLinkAnchorsInText(t *testing.T) {
+	line := "- [ ] [tg-a1b2] see [docs](#setup) for details #web"
+	task, ok := ParseTask(line)
+	if !ok {
+		t.Fatalf("expected checklist line to parse")
+	}
+	if task.Text != "see [docs](#setup) for details" || !reflect.DeepEqual(task.Labels, []string{"web"}) {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if got := task.Format(); got != line {
+		t.Fatalf("expected link anchor to round-trip:\n got %q\nwant %q", got, line)
+	}
+}
+
+func TestParseTaskReadsOnlyTheLeadingID(t *testing.T) {
+	for line, want := range map[string]string{
+		"- [ ] [tg-a1b2] Ship release":                "tg-a1b2",
+		"- [ ] ➕2026-10-01 [tg-a1b2] Ship release":    "tg-a1b2",
+		"- [ ] follow up on [tg-a1b2]":                "",
+		"- [ ] [tg-c3d4] follow up on [tg-a1b2] #web": "tg-c3d4",
+	} {
+		task, ok := ParseTask(line)
+		if !ok || task.ID != want {
+			t.Fatalf("ParseTask(%q) ID = %q, want %q", line, task.ID, want)
+		}
+		if got := task.Format(); got != line {
+			t.Fatalf("expected %q to round-trip, got %q", line, got)
+		}
+	}
+}
+
+func TestParseTaskRejectsPlainLines(t *testing.T) {
+	if _, ok := ParseTask("- plain bullet"); ok {
+		t.Fatalf("expected non-checklist line to be rejected")
+	}
+}
+
+func TestTaskFormatDefaultsToDashMarker(t *testing.T) {
+	got := Task{Text: "Call Sam", Scheduled: "2026-10-03"}.Format()
+	if got != "- [ ] Call Sam ⏳ 2026-10-03" {
+		t.Fatalf("unexpected line: %q", got)
+	}
+}
+
+func TestNewTaskIDAvoidsExisting(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	first := NewTaskID("TG!", "x", now, map[string]bool{})
+	second := NewTaskID("tg", "x", now, map[string]bool{first: true})
+	if first == second || ExtractTaskID("["+first+"]") != first {
+		t.Fatalf("unexpected IDs %q %q", first, second)
+	}
+}
@@ -14,56 +14,47 @@ import (
 )
 
 var idPattern = regexp.MustCompile(`\[[a-z0-9]+-[0-9a-z]{3,8}\]`)
-var labelPattern = regexp.MustCompile(`(^|[\s(])#([A-Za-z0-9][A-Za-z0-9-]*)`)
+var labelPattern = regexp.MustCompile(`(^|[\s(])#([A-Za-z0-9][A-Za-z0-9-]*(?:/[A-Za-z0-9][A-Za-z0-9-]*)*)`)
 var typeLabelPrefix = "t-"
 
+// typeNamespace is the hierarchical spelling of typeLabelPrefix: #type/epic
+// is read as #t-epic.
+var typeNamespace = "type"
+var checkboxLinePattern = regexp.MustCompile(`^([ \t]*[-*+]\s+)\[( |x|X)\](.*)$`)
+var doneNotePattern = regexp.MustCompile(`\s*\*\*✅\d{4}-\d{2}-\d{2}[^*]*\*\*\s*$`)
+
+// ErrTaskNotFound reports that no checklist line carries the requested ID.
+var ErrTaskNotFound = errors.New("task not found")
+
 // AppendTask appends one markdown checklist line to tasksFile.
 func AppendTask(tasksFile, prefix, text string, labels []string, taskType string) error {
-	if strings.TrimSpace(tasksFile) == "" {
-		return errors.New("tasks file is required")
-	}
-	cleanPrefix := normalizePrefix(prefix)
-	clean := strings.TrimSpace(text)
-	if clean == "" {
-		return errors.New("task text is required")
-	}
-	resolvedType, cleanInputLabels, err := ResolveTaskType(clean, labels, taskType)
-	if err != nil {
-		return err
-	}
+	_, err := InsertTask(tasksFile, prefix, text, labels, taskType, Placement{})
+	return err
+}
 
-	existing, err := os.ReadFile(tasksFile)
-	if err != nil && !os.IsNotExist(err) {
-		return err
+// formatTaskLine renders the canonical checklist body (everything after the list
+// marker) for a new task and returns it together with the generated ID.
+func formatTaskLine(prefix, text string, labels []string, taskType string, existingIDs map[string]bool, now time.Time) (string, string, error) {
+	resolvedType, cleanInputLabels, err := ResolveTaskType(text, labels, taskType)
+	if err != nil {
+		return "", "", err
 	}
 
-	now := time.Now()
-	existingIDs := collectExistingIDs(string(existing))
-	id := generateIssueID(cleanPrefix, clean, now, existingIDs)
-	mergedLabels := MergeLabels(ExtractLabels(clean), cleanInputLabels)
+	id := generateIssueID(prefix, text, now, existingIDs)
+	mergedLabels := MergeLabels(ExtractLabels(text), cleanInputLabels)
 	if resolvedType != "" {
 		mergedLabels = MergeLabels(mergedLabels, []string{TypeLabel(resolvedType)})
 	}
 	if len(mergedLabels) > 0 {
-		clean = stripLabels(clean)
-		clean = strings.TrimSpace(clean + " " + formatLabels(mergedLabels))
-	}
-
-	line := fmt.Sprintf("- [ ] ➕%s [%s] %s\n", now.Format("2006-01-02"), id, clean)
-	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
-		line = "\n" + line
-	}
-
-	f, err := os.OpenFile(tasksFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
-	if err != nil {
-		return err
+		text = stripLabels(text)
+		text = strings.TrimSpace(text + " " + formatLabels(mergedLabels))
 	}
-	defer f.Close()
 
-	_, err = f.WriteString(line)
-	return err
+	return fmt.Sprintf("[ ] ➕%s [%s] %s", now.Format("2006-01-02"), id, text), id, nil
 }
 
+// CloseTask marks the checklist line carrying id as done, appending a dated
+// completion note with the optional reason.
 func CloseTask(tasksFile, id, reason string) error {
 	if strings.TrimSpace(tasksFile) == "" {
 		return errors.New("tasks file is required")
@@ -72,7 +63,6 @@ func CloseTask(tasksFile, id, reason string) error {
 	if id == "" {
 		return errors.New("task id is required")
 	}
-	reason = strings.TrimSpace(reason)
 
 	content, err := os.ReadFile(tasksFile)
 	if err != nil {
@@ -81,31 +71,105 @@ func CloseTask(tasksFile, id, reason string) error {
 
 	lines := strings.Split(string(content), "\n")
 	needle := "[" + id + "]"
-	found := false
 	for i, line := range lines {
 		if !strings.Contains(line, needle) {
 			continue
 		}
-		found = true
-		if !strings.HasPrefix(line, "- [ ] ") {
-			return fmt.Errorf("task already closed: %s", id)
+		if err := closeLine(lines, i, reason); err != nil {
+			return fmt.Errorf("%w: %s", err, id)
 		}
-		note := " **✅" + time.Now().Format("2006-01-02")
-		if reason != "" {
-			note += " " + reason
+		return os.WriteFile(tasksFile, []byte(strings.Join(lines, "\n")), 0o644)
+	}
+	return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+}
+
+// CloseTaskLine closes the checklist item on the given 1-based line, for tasks
+// that have no ID to address them by.
+func CloseTaskLine(tasksFile string, lineNo int, reason string) error {
+	return rewriteChecklistLine(tasksFile, lineNo, func(lines []string, i int) error {
+		return closeLine(lines, i, reason)
+	})
+}
+
+// ReopenTaskLine marks the checklist item on the given 1-based line as open
+// again and drops its completion note.
+func ReopenTaskLine(tasksFile string, lineNo int) error {
+	return rewriteChecklistLine(tasksFile, lineNo, func(lines []string, i int) error {
+		m := checkboxLinePattern.FindStringSubmatch(lines[i])
+		if m == nil {
+			return errors.New("not a checklist item")
 		}
-		note += "**"
-		lines[i] = line + note
-		lines[i] = strings.Replace(lines[i], "- [ ] ", "- [x] ", 1)
-		break
+		if m[2] == " " {
+			return errors.New("task already open")
+		}
+		rest := doneNotePattern.ReplaceAllString(m[3], "")
+		lines[i] = m[1] + "[ ]" + rest
+		return nil
+	})
+}
+
+// SetCheckboxState returns line with its checkbox ticked or cleared. Clearing
+// also drops the completion note. Lines that are not checklist items are
+// returned unchanged.
+func SetCheckboxState(line string, closed bool) string {
+	m := checkboxLinePattern.FindStringSubmatch(line)
+	if m == nil {
+		return line
+	}
+	if closed {
+		if m[2] != " " {
+			return line
+		}
+		return m[1] + "[x]" + m[3]
 	}
-	if !found {
-		return fmt.Errorf("task not found: %s", id)
+	if m[2] == " " {
+		return line
 	}
+	return m[1] + "[ ]" + doneNotePattern.ReplaceAllString(m[3], "")
+}
 
+func rewriteChecklistLine(tasksFile string, lineNo int, edit func(lines []string, i int) error) error {
+	if strings.TrimSpace(tasksFile) == "" {
+		return errors.New("tasks file is required")
+	}
+	content, err := os.ReadFile(tasksFile)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+	if lineNo < 1 || lineNo > len(lines) {
+		return fmt.Errorf("line %d out of range in %s", lineNo, tasksFile)
+	}
+	if err := edit(lines, lineNo-1); err != nil {
+		return fmt.Errorf("%s:%d: %w", tasksFile, lineNo, err)
+	}
 	return os.WriteFile(tasksFile, []byte(strings.Join(lines, "\n")), 0o644)
 }
 
+func closeLine(lines []string, i int, reason string) error {
+	m := checkboxLinePattern.FindStringSubmatch(lines[i])
+	if m == nil {
+		return errors.New("not a checklist item")
+	}
+	if m[2] != " " {
+		return errors.New("task already closed")
+	}
+	note := " **✅" + time.Now().Format("2006-01-02")
+	if reason = strings.TrimSpace(reason); reason != "" {
+		note += " " + reason
+	}
+	note += "**"
+	lines[i] = m[1] + "[x]" + m[3] + note
+	return nil
+}
+
+// ExtractTaskID returns the first tg-style [prefix-id] reference in text, or
+// an empty string when there is none.
+func ExtractTaskID(text string) string {
+	match := idPattern.FindString(text)
+	return strings.TrimSuffix(strings.TrimPrefix(match, "["), "]")
+}
+
 func normalizePrefix(raw string) string {
 	var out []rune
 	for _, r := range strings.ToLower(raw) {
@@ -223,13 +287,10 @@ func NormalizeLabelsCSV(raw string) []string {
 }
 
 func ExtractLabels(text string) []string {
-	matches := labelPattern.FindAllStringSubmatch(text, -1)
+	matches := labelMatches(text)
 	out := make([]string, 0, len(matches))
-	for _, match := range matches {
-		if len(match) < 3 {
-			continue
-		}
-		label := normalizeLabel(match[2])
+	for _, m := range matches {
+		label := normalizeLabel(text[m[4]:m[5]])
 		if label != "" {
 			out = append(out, label)
 		}
@@ -237,6 +298,20 @@ func ExtractLabels(text string) []string {
 	return MergeLabels(out)
 }
 
+// labelMatches returns the submatch indices of the labels in text, leaving
+// out markdown link targets such as [text](#anchor). m[2]:m[3] is the
+// boundary before the #, m[4]:m[5] the label name.
+func labelMatches(text string) [][]int {
+	var out [][]int
+	for _, m := range labelPattern.FindAllStringSubmatchIndex(text, -1) {
+		if text[m[2]:m[3]] == "(" && strings.HasSuffix(text[:m[2]], "]") {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
 func MergeLabels(groups ...[]string) []string {
 	seen := map[string]bool{}
 	out := []string{}
@@ -253,6 +328,9 @@ func MergeLabels(groups ...[]string) []string {
 	return out
 }
 
+// normalizeLabel lowercases a label and drops characters other than letters,
+// digits, single hyphens and the slashes separating hierarchical segments,
+// as in area/frontend. type/<name> becomes the type label t-<name>.
 func normalizeLabel(raw string) string {
 	label := strings.TrimSpace(strings.TrimPrefix(raw, "#"))
 	label = strings.ToLower(label)
@@ -260,20 +338,38 @@ func normalizeLabel(raw string) string {
 		return ""
 	}
 
-	var out []rune
-	prevHyphen := false
-	for _, r := range label {
-		switch {
-		case unicode.IsLetter(r) || unicode.IsDigit(r):
-			out = append(out, r)
-			prevHyphen = false
-		case r == '-' && len(out) > 0 && !prevHyphen:
-			out = append(out, r)
-			prevHyphen = true
+	var segments []string
+	for _, segment := range strings.Split(label, "/") {
+		var out []rune
+		prevHyphen := false
+		for _, r := range segment {
+			switch {
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				out = append(out, r)
+				prevHyphen = false
+			case r == '-' && len(out) > 0 && !prevHyphen:
+				out = append(out, r)
+				prevHyphen = true
+			}
+		}
+		if segment = strings.Trim(string(out), "-"); segment != "" {
+			segments = append(segments, segment)
 		}
 	}
-	label = strings.Trim(string(out), "-")
-	return label
+	if len(segments) > 1 && segments[0] == typeNamespace {
+		return typeLabelPrefix + strings.Join(segments[1:], "/")
+	}
+	return strings.Join(segments, "/")
+}
+
+// LabelMatches reports whether label is filter or sits below it in the
+// label hierarchy, so a filter of area matches area/frontend. The filter
+// type matches every type label.
+func LabelMatches(label, filter string) bool {
+	if label == filter || strings.HasPrefix(label, filter+"/") {
+		return true
+	}
+	return filter == typeNamespace && strings.HasPrefix(label, typeLabelPrefix)
 }
 
 func NormalizeTaskType(raw string) string {
@@ -354,8 +450,14 @@ func ResolveTaskType(text string, labels []string, taskType string) (string, []s
 }
 
 func stripLabels(text string) string {
-	cleaned := labelPattern.ReplaceAllString(text, "$1")
-	return strings.Join(strings.Fields(cleaned), " ")
+	var b strings.Builder
+	last := 0
+	for _, m := range labelMatches(text) {
+		b.WriteString(text[last : m[4]-1])
+		last = m[5]
+	}
+	b.WriteString(text[last:])
+	return strings.Join(strings.Fields(b.String()), " ")
 }
 
 func formatLabels(labels []string) string {
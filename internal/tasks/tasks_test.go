@@ -85,13 +85,44 @@ func TestNormalizeLabelsCSV(t *testing.T) {
 }
 
 func TestExtractLabelsFromText(t *testing.T) {
-	got := ExtractLabels("prep venue notes #flowershow #ABC not-a-label#fragment")
+	got := ExtractLabels("prep venue notes #flowershow #ABC not-a-label#fragment [intro](#setup)")
 	want := []string{"flowershow", "abc"}
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("got %v want %v", got, want)
 	}
 }
 
+func TestExtractLabelsReadsHierarchicalLabels(t *testing.T) {
+	got := ExtractLabels("redo nav #Area/Frontend #area/ #type/Epic (#people/sam/) #a//b")
+	want := []string{"area/frontend", "area", "t-epic", "people/sam", "a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got := NormalizeTaskType("type/bug"); got != "bug" {
+		t.Fatalf("got type %q want bug", got)
+	}
+}
+
+func TestLabelMatches(t *testing.T) {
+	cases := []struct {
+		label, filter string
+		want          bool
+	}{
+		{"area", "area", true},
+		{"area/frontend", "area", true},
+		{"area/frontend/nav", "area/frontend", true},
+		{"areas", "area", false},
+		{"area", "area/frontend", false},
+		{"t-epic", "type", true},
+		{"t-epic", "t-epic", true},
+	}
+	for _, c := range cases {
+		if got := LabelMatches(c.label, c.filter); got != c.want {
+			t.Errorf("LabelMatches(%q, %q) = %v, want %v", c.label, c.filter, got, c.want)
+		}
+	}
+}
+
 func TestMergeLabelsDeduplicatesPreservingOrder(t *testing.T) {
 	got := MergeLabels(
 		ExtractLabels("prep venue notes #flowershow #abc"),
@@ -213,6 +244,58 @@ func TestCloseTaskReturnsErrorForAlreadyClosedTask(t *testing.T) {
 	}
 }
 
+func TestCloseTaskClosesIndentedChild(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, "- [ ] parent\n  * [ ] [tg-abc] child\n")
+
+	if err := CloseTask(path, "tg-abc", ""); err != nil {
+		t.Fatalf("CloseTask returned err: %v", err)
+	}
+
+	got := readFile(t, path)
+	want := "- [ ] parent\n  * [x] [tg-abc] child **✅" + todayISO() + "**\n"
+	if got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestCloseAndReopenTaskLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "roadmap.md")
+	mustWrite(t, path, "# Roadmap\n  - [ ] untracked item #home\n")
+
+	if err := CloseTaskLine(path, 2, "done"); err != nil {
+		t.Fatalf("CloseTaskLine returned err: %v", err)
+	}
+	if got := readFile(t, path); got != "# Roadmap\n  - [x] untracked item #home **✅"+todayISO()+" done**\n" {
+		t.Fatalf("unexpected closed content: %q", got)
+	}
+
+	if err := ReopenTaskLine(path, 2); err != nil {
+		t.Fatalf("ReopenTaskLine returned err: %v", err)
+	}
+	if got := readFile(t, path); got != "# Roadmap\n  - [ ] untracked item #home\n" {
+		t.Fatalf("unexpected reopened content: %q", got)
+	}
+
+	if err := ReopenTaskLine(path, 2); err == nil {
+		t.Fatalf("expected error reopening an open task")
+	}
+	if err := CloseTaskLine(path, 1, ""); err == nil {
+		t.Fatalf("expected error closing a heading line")
+	}
+}
+
+func TestExtractTaskID(t *testing.T) {
+	if got := ExtractTaskID("➕2026-03-03 [tg-abc] call Alice"); got != "tg-abc" {
+		t.Fatalf("got %q want %q", got, "tg-abc")
+	}
+	if got := ExtractTaskID("no id here"); got != "" {
+		t.Fatalf("expected empty ID, got %q", got)
+	}
+}
+
 func mustWrite(t *testing.T, path string, content string) {
 	t.Helper()
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
@@ -244,3 +327,15 @@ func assertTaskLineFormat(t *testing.T, line, prefix, text string) {
 		t.Fatalf("line did not match expected format.\nline: %q\npattern: %q", line, pattern)
 	}
 }
+
+func TestSetCheckboxState(t *testing.T) {
+	if got := SetCheckboxState("  - [ ] task", true); got != "  - [x] task" {
+		t.Fatalf("unexpected closed line: %q", got)
+	}
+	if got := SetCheckboxState("- [x] task **✅2026-01-02 done**", false); got != "- [ ] task" {
+		t.Fatalf("unexpected reopened line: %q", got)
+	}
+	if got := SetCheckboxState("plain text", true); got != "plain text" {
+		t.Fatalf("expected non-checklist line unchanged, got %q", got)
+	}
+}
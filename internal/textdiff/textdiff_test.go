@@ -0,0 +1,45 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedReturnsEmptyForEqualInput(t *testing.T) {
+	if got := Unified("a.md", "x\n", "x\n"); got != "" {
+		t.Fatalf("expected no diff, got %q", got)
+	}
+}
+
+func TestUnifiedShowsChangesWithContext(t *testing.T) {
+	before := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	after := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	want := strings.Join([]string{
+		"--- a/notes.md",
+		"+++ b/notes.md",
+		"@@ -1,6 +1,6 @@",
+		" one",
+		" two",
+		"-three",
+		"+THREE",
+		" four",
+		" five",
+		" six",
+		"@@ -8,3 +8,4 @@",
+		" eight",
+		" nine",
+		" ten",
+		"+eleven",
+	}, "\n") + "\n"
+	if got := Unified("notes.md", before, after); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedHandlesNewFile(t *testing.T) {
+	want := "--- a/new.md\n+++ b/new.md\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := Unified("new.md", "", "a\nb\n"); got != want {
+		t.Fatalf("unexpected diff: %q", got)
+	}
+}